@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint/ghquick/internal/ai"
+	"github.com/saint/ghquick/internal/commit"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	amendMessage string
+	amendAI      bool
+	amendFixup   string
+	amendPaths   []string
+	amendNoPush  bool
+	amendForce   bool
+	amendRemote  string
+	amendYes     bool
+	amendSecrets bool
+)
+
+func init() {
+	rootCmd.AddCommand(amendCmd)
+	amendCmd.Flags().StringVarP(&amendMessage, "message", "m", "", "New message for the amended commit (default: keep it)")
+	amendCmd.Flags().BoolVar(&amendAI, "ai", false, "Regenerate the message from the combined diff of the commit and the new changes")
+	amendCmd.Flags().StringVar(&amendFixup, "fixup", "", "Fold the changes into this earlier commit instead of the last one")
+	amendCmd.Flags().StringSliceVar(&amendPaths, "paths", nil, "Stage only these paths (default: all changes)")
+	amendCmd.Flags().BoolVar(&amendNoPush, "no-push", false, "Rewrite the commit locally without pushing")
+	amendCmd.Flags().BoolVar(&amendForce, "force", false, "Rewrite a commit that is already pushed and force-push it with --force-with-lease")
+	amendCmd.Flags().StringVar(&amendRemote, "remote", "origin", "Remote to push to")
+	amendCmd.Flags().DurationVar(&hookTimeout, "hook-timeout", 0, "Give up on a hook after this long (default hook_timeout config, or 10m)")
+	amendCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the pre_commit and pre_push hooks, and git's own commit and push hooks")
+	amendCmd.Flags().BoolVarP(&amendYes, "yes", "y", false, "Accept the regenerated --ai message without asking")
+	amendCmd.Flags().BoolVar(&amendSecrets, "allow-secrets", false, "Commit even when staged changes look like they contain API keys, tokens or private keys")
+	amendCmd.MarkFlagsMutuallyExclusive("message", "ai")
+	amendCmd.MarkFlagsMutuallyExclusive("fixup", "message")
+	amendCmd.MarkFlagsMutuallyExclusive("fixup", "ai")
+}
+
+var amendCmd = &cobra.Command{
+	Use:   "amend",
+	Short: "Fold new changes into the last commit (or an earlier one) and push",
+	Long: `Stage the current changes into the last commit, keeping its message, or with
+--ai regenerating it from the combined diff; --fixup <commit> folds them into an
+earlier commit of the branch instead (git commit --fixup plus an autosquash
+rebase). Then push.
+
+Rewriting a commit that is already pushed needs a force-push, which, as for
+push --amend, only happens with --force and then uses --force-with-lease. When
+the branch looks shared (it is the default branch, has commits you don't have,
+or has commits by other authors) ghquick says why before going on or stopping.
+Example:
+  ghquick amend
+  ghquick amend --ai
+  ghquick amend --paths README.md -m "docs: fix install steps"
+  ghquick amend --fixup HEAD~2 --no-push
+  ghquick amend --force    # the last commit is already pushed`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		gitOps := newGitOps(wd)
+		gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+		gitOps.SetExcludePatterns(fileCfg.Exclude)
+		if fileCfg.SignCommits || fileCfg.SigningKey != "" {
+			gitOps.SetSigning(git.SignOn, fileCfg.SigningKey)
+		}
+		if fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "" {
+			gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+		}
+		if err := gitOps.CheckSigning(ctx); err != nil {
+			return err
+		}
+		if amendMessage != "" && fileCfg.Conventional {
+			if err := commit.ValidateConventional(amendMessage); err != nil {
+				return err
+			}
+		}
+
+		// Decide whether the rewrite is safe before touching anything
+		target := strutil.FirstNonEmpty(amendFixup, "HEAD")
+		branch, err := gitOps.CurrentBranch(ctx)
+		if err != nil {
+			return err
+		}
+		pushed, err := checkRewrite(ctx, gitOps, fileCfg, amendRemote, branch, target, amendForce, amendNoPush)
+		if err != nil {
+			return err
+		}
+
+		if len(amendPaths) > 0 {
+			err = gitOps.StageFiles(ctx, amendPaths)
+		} else {
+			err = gitOps.StageAll(ctx)
+		}
+		noChanges := errors.Is(err, git.ErrNoChanges)
+		if err != nil && !noChanges {
+			return err
+		}
+		if noChanges && (amendFixup != "" || (amendMessage == "" && !amendAI)) {
+			logger.Warning("Nothing staged to fold into %s", target)
+			return nil
+		}
+		if !noChanges {
+			diff, err := gitOps.GetDiff(ctx, true)
+			if err != nil && !errors.Is(err, git.ErrNoChanges) {
+				return err
+			}
+			if err := checkSecrets(diff, amendSecrets); err != nil {
+				return err
+			}
+		}
+
+		if err := runHooks(ctx, wd, hookPreCommit, fileCfg.Hooks.PreCommit, hookLimit(fileCfg)); err != nil {
+			return fmt.Errorf("%w; nothing was rewritten", err)
+		}
+
+		previous, _ := gitOps.HeadCommit(ctx)
+		if amendFixup != "" {
+			if err := gitOps.Fixup(ctx, amendFixup); err != nil {
+				return err
+			}
+		} else {
+			message := amendMessage
+			if amendAI {
+				if message, err = regenerateAmendMessage(ctx, gitOps, fileCfg, wd); err != nil {
+					return err
+				}
+			} else if validator := fileCfg.CommitMsgValidator; validator != "" && message != "" {
+				if _, err := lintCommitMessage(ctx, wd, validator, message, nil); err != nil {
+					return err
+				}
+			}
+			if err := gitOps.Amend(ctx, message); err != nil {
+				return err
+			}
+		}
+
+		sha, _ := gitOps.HeadCommit(ctx)
+		op := git.Operation{SHA: sha, Parent: previous, Branch: branch, Amended: true, Time: time.Now()}
+		if amendNoPush {
+			if pushed && !dryRun {
+				logger.Warning("The rewritten commit isn't pushed; push it later with 'git push --force-with-lease'")
+			}
+			if amendFixup == "" {
+				recordOperation(ctx, gitOps, op)
+			}
+			logger.Result(map[string]interface{}{"ok": true, "sha": sha, "branch": branch, "pushed": false})
+			return nil
+		}
+
+		if err := runHooks(ctx, wd, hookPrePush, fileCfg.Hooks.PrePush, hookLimit(fileCfg)); err != nil {
+			return fmt.Errorf("%w; the rewritten commit is kept locally and nothing was pushed", err)
+		}
+		if err := gitOps.Push(ctx, amendRemote, branch); err != nil {
+			return err
+		}
+		if amendFixup == "" {
+			op.Remotes, op.RemoteBranch = []string{amendRemote}, branch
+			recordOperation(ctx, gitOps, op)
+		}
+		logger.Result(map[string]interface{}{"ok": true, "sha": sha, "branch": branch, "pushed": true, "forced": pushed})
+		return nil
+	},
+}
+
+// checkRewrite is the one policy for rewriting target, shared by amend and
+// push --amend, and reports whether target is already pushed. A pushed commit
+// is only rewritten with force, and is then force-pushed with
+// --force-with-lease; reasons the branch looks shared are shown either way.
+// With localOnly nothing will be pushed, so divergence is only warned about.
+func checkRewrite(ctx context.Context, gitOps *git.Operations, fileCfg *config.FileConfig, remote, branch, target string, force, localOnly bool) (bool, error) {
+	pushed, err := gitOps.IsPushed(ctx, target)
+	if err != nil || !pushed {
+		return false, err
+	}
+	if localOnly {
+		logger.Warning("The commit is already pushed; rewriting it locally will make %s diverge from %s", branch, remote)
+		return true, nil
+	}
+
+	// A stale view of the remote would hide commits pushed since the last fetch
+	if err := gitOps.FetchBranch(ctx, remote, branch); err != nil {
+		logger.Warning("Could not fetch %s; checking against what is known locally: %v", remote, err)
+	}
+	base := fileCfg.DefaultBranch
+	if base == "" {
+		base, _ = gitOps.DefaultBranch(ctx, remote)
+	}
+	reasons, err := gitOps.SharedBranch(ctx, remote, branch, base)
+	if err != nil {
+		return true, err
+	}
+	for _, r := range reasons {
+		logger.Warning("%s", r)
+	}
+	if !force {
+		logger.Error("The commit has already been pushed to %s/%s", remote, branch)
+		if len(reasons) > 0 {
+			return true, fmt.Errorf("refusing to rewrite a pushed commit on a shared branch (%s); commit the changes on top instead, or pass --force", strings.Join(reasons, "; "))
+		}
+		return true, fmt.Errorf("rewriting a pushed commit requires a force-push; pass --force to force-push %s/%s with --force-with-lease", remote, branch)
+	}
+	logger.Warning("--force given; %s/%s will be force-pushed with --force-with-lease", remote, branch)
+	gitOps.SetForcePush(true)
+	return true, nil
+}
+
+// regenerateAmendMessage writes a new message for the whole amended commit:
+// what it already changed plus what is staged now
+func regenerateAmendMessage(ctx context.Context, gitOps *git.Operations, fileCfg *config.FileConfig, wd string) (string, error) {
+	cfg, err := config.Load(tokenResolver())
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	provider, err := ai.NewProvider(aiProviderConfig(cfg, fileCfg))
+	if err != nil {
+		return "", err
+	}
+	diff, err := gitOps.AmendDiff(ctx)
+	if err != nil {
+		return "", err
+	}
+	language, err := ai.ParseLanguage(fileCfg.CommitLanguage)
+	if err != nil {
+		return "", err
+	}
+	files := git.ParseDiff(diff)
+	opts := ai.Options{
+		Language:        language,
+		StructuredBody:  fileCfg.StructuredBody,
+		SplitThreshold:  defaultMaxDiffBytes,
+		TokenBudget:     fileCfg.AITokenBudget,
+		MaxBodyLines:    fileCfg.MaxBodyLines,
+		MaxSummaryWords: fileCfg.MaxSummaryWords,
+		Conventional:    fileCfg.Conventional,
+	}
+	if opts.Conventional {
+		opts.InferredType = commit.InferType(files)
+	}
+	breakingOpts := commit.BreakingOptions{}
+	if fileCfg.DetectBreaking {
+		breakingOpts = commit.DefaultBreakingOptions()
+	}
+	flow := &messageFlow{
+		gen:     ai.NewCommitMessageGenerator(provider).WithOptions(opts),
+		diff:    diff,
+		opts:    opts,
+		summary: commit.Summarize(files, breakingOpts),
+		confirm: !amendYes && isInteractive(),
+	}
+	message, err := flow.generate(ctx)
+	if err != nil {
+		return "", err
+	}
+	if validator := fileCfg.CommitMsgValidator; validator != "" {
+		return lintCommitMessage(ctx, wd, validator, message, flow.regenerate)
+	}
+	return message, nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/saint/ghquick/internal/auth"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authUser      string
+	authWithToken bool
+)
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authStatusCmd)
+
+	authCmd.PersistentFlags().StringVar(&authUser, "user", "", "GitHub username (defaults to GITHUB_USERNAME)")
+	authLoginCmd.Flags().BoolVar(&authWithToken, "with-token", false, "Read the token from standard input")
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the GitHub token stored in the OS keychain",
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store a GitHub token in the OS keychain",
+	Long: `Store a GitHub token in the OS credential store (macOS Keychain, Windows
+Credential Manager, or libsecret on Linux). Other commands use it when there is
+no --token flag, GITHUB_TOKEN, or gh CLI login ('gh auth token').
+Example:
+  echo "$TOKEN" | ghquick auth login --with-token`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := resolveAuthUser()
+		if err != nil {
+			return err
+		}
+
+		var token string
+		if authWithToken {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read token: %w", err)
+			}
+			token = strings.TrimSpace(string(data))
+		} else {
+			if token, err = readSecret("GitHub token: "); err != nil {
+				return err
+			}
+		}
+		if token == "" {
+			return fmt.Errorf("no token provided")
+		}
+
+		if dryRun {
+			logger.Info("[dry-run] Would store a token in the keychain for %s", user)
+			return nil
+		}
+		if err := auth.NewKeychainStore().Set(user, token); err != nil {
+			logger.Error("Failed to store token")
+			return err
+		}
+		logger.Success("Token stored in keychain for %s", user)
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the GitHub token from the OS keychain",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := resolveAuthUser()
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			logger.Info("[dry-run] Would remove the keychain token for %s", user)
+			return nil
+		}
+		if err := auth.NewKeychainStore().Delete(user); err != nil {
+			if errors.Is(err, auth.ErrTokenNotFound) {
+				logger.Info("No token stored for %s", user)
+				return nil
+			}
+			return err
+		}
+		logger.Success("Token removed from keychain for %s", user)
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the GitHub token is read from",
+	Long: `Show which source the GitHub token is taken from. They are tried in order:
+the --token flag, GITHUB_TOKEN, the gh CLI's login, and the OS keychain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := resolveAuthUser()
+		if err != nil {
+			return err
+		}
+		_, source, err := tokenResolver().Resolve(user)
+		switch {
+		case err == nil:
+			logger.Success("Using token from %s for %s", source, user)
+		case errors.Is(err, auth.ErrTokenNotFound):
+			logger.Warning("No token found; run 'ghquick auth login', 'gh auth login', or set %s", config.EnvGitHubToken)
+		default:
+			logger.Warning("No token found, and the keychain can't be read: %v", err)
+		}
+		if err == nil {
+			logger.Result(map[string]interface{}{"ok": true, "user": user, "source": string(source)})
+		}
+		return nil
+	},
+}
+
+// resolveAuthUser returns the --user flag or GITHUB_USERNAME
+func resolveAuthUser() (string, error) {
+	if authUser != "" {
+		return authUser, nil
+	}
+	if user := os.Getenv(config.EnvGitHubUsername); user != "" {
+		return user, nil
+	}
+	return "", fmt.Errorf("GitHub username is required (use --user or set %s)", config.EnvGitHubUsername)
+}
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	batchRoot      string
+	batchWorkspace string
+	batchJobs      int
+	batchDepth     int
+)
+
+const (
+	defaultBatchJobs  = 4
+	defaultBatchDepth = 3
+)
+
+// batchCommands are the commands batch can run in each repository
+var batchCommands = map[string]bool{"push": true, "sync": true, "status": true}
+
+// skipDirs are never searched for repositories
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringVar(&batchRoot, "root", "", "Directory to look for repositories under (default: --dir or the current directory)")
+	batchCmd.Flags().StringVar(&batchWorkspace, "workspace", "", "YAML file listing the repositories under repos: (paths relative to the file)")
+	batchCmd.Flags().IntVarP(&batchJobs, "jobs", "j", defaultBatchJobs, "Repositories to work on at once")
+	batchCmd.Flags().IntVar(&batchDepth, "depth", defaultBatchDepth, "How many directories deep to look for repositories under --root")
+	batchCmd.MarkFlagsMutuallyExclusive("root", "workspace")
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <push|sync|status> [args...] [-- flags]",
+	Short: "Run push, sync or status in every repository under a directory",
+	Long: `Find the git repositories under --root (or listed in a --workspace file) and
+run a ghquick command in each of them, a few at a time, then print a table with
+every repository's branch, state and outcome. Arguments after the command, and
+flags after --, are passed on to it. status only reads each repository.
+
+A workspace file lists the repositories relative to itself:
+  repos:
+    - api
+    - ../libs/shared
+Example:
+  ghquick batch status --root ~/src/github.com/my-org
+  ghquick batch sync --jobs 8
+  ghquick batch push start --workspace tools.yaml -- --no-hints
+  ghquick batch push -- --commitmsg "chore: bump dependencies"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || !batchCommands[args[0]] || cmd.ArgsLenAtDash() == 0 {
+			return fmt.Errorf("batch needs a command to run: push, sync or status")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if batchJobs < 1 {
+			return fmt.Errorf("--jobs must be at least 1")
+		}
+		root, repos, err := batchRepos()
+		if err != nil {
+			return err
+		}
+		if len(repos) == 0 {
+			logger.Warning("No git repositories found under %s", root)
+			logger.Result(map[string]interface{}{"ok": true, "repos": []batchResult{}})
+			return nil
+		}
+		logger.Info("Running %s in %d repositories (%d at a time)", args[0], len(repos), batchJobs)
+
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the ghquick executable: %w", err)
+		}
+		results := make([]batchResult, len(repos))
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < batchJobs && w < len(repos); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					results[i] = runBatchRepo(self, root, repos[i], args)
+					reportBatchResult(results[i])
+				}
+			}()
+		}
+		for i := range repos {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+
+		if !log.JSON() {
+			printBatchTable(results)
+		}
+		failed := 0
+		for _, r := range results {
+			if !r.OK {
+				failed++
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": failed == 0, "repos": results, "failed": failed})
+		if failed > 0 {
+			return fmt.Errorf("%s failed in %d of %d repositories", args[0], failed, len(repos))
+		}
+		return nil
+	},
+}
+
+// batchResult is how one repository fared
+type batchResult struct {
+	Repo     string        `json:"repo"`
+	Path     string        `json:"path"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Branch   string        `json:"branch,omitempty"`
+	Changes  int           `json:"changes"`
+	Upstream string        `json:"upstream,omitempty"`
+	Ahead    int           `json:"ahead"`
+	Behind   int           `json:"behind"`
+	Duration time.Duration `json:"duration_ms"`
+}
+
+// MarshalJSON reports the duration in milliseconds
+func (r batchResult) MarshalJSON() ([]byte, error) {
+	type plain batchResult
+	p := plain(r)
+	p.Duration = r.Duration / time.Millisecond
+	return json.Marshal(p)
+}
+
+// batchRepos returns the directory repository names are shown relative to and
+// the repositories to work on, from --workspace or by searching --root
+func batchRepos() (string, []string, error) {
+	if batchWorkspace != "" {
+		return readWorkspace(batchWorkspace)
+	}
+	root := batchRoot
+	if root == "" {
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return "", nil, err
+		}
+		root = wd
+	}
+	root, err := expandCodeRoot(root)
+	if err != nil {
+		return "", nil, err
+	}
+	repos, err := discoverRepos(root, batchDepth)
+	return root, repos, err
+}
+
+// discoverRepos returns the worktrees of the git repositories under root, at
+// most depth directories down. Hidden directories, node_modules and vendor are
+// skipped, and a repository's own subdirectories aren't searched.
+func discoverRepos(root string, depth int) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// An unreadable directory only hides what is inside it
+			if p != root && d != nil && d.IsDir() {
+				logger.Debug("Skipping %s: %v", p, err)
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		if p != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		// .git is a directory in a clone and a file in a worktree or submodule
+		if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+			repos = append(repos, p)
+			return filepath.SkipDir
+		}
+		if rel != "." && strings.Count(rel, string(filepath.Separator))+1 >= depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for repositories: %w", root, err)
+	}
+	return repos, nil
+}
+
+// readWorkspace reads the repositories listed in a workspace file
+func readWorkspace(path string) (string, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+	var ws struct {
+		Repos []string `yaml:"repos"`
+	}
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return "", nil, fmt.Errorf("failed to parse workspace file %s: %w", path, err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return "", nil, err
+	}
+
+	seen := map[string]bool{}
+	var repos []string
+	for _, r := range ws.Repos {
+		if r == "" {
+			continue
+		}
+		if r != "~" && !strings.HasPrefix(r, "~/") && !filepath.IsAbs(r) {
+			r = filepath.Join(dir, r)
+		}
+		repo, err := expandCodeRoot(r)
+		if err != nil {
+			return "", nil, err
+		}
+		if info, err := os.Stat(repo); err != nil || !info.IsDir() {
+			return "", nil, fmt.Errorf("workspace file %s lists %s, which is not a directory", path, r)
+		}
+		if !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+	sort.Strings(repos)
+	return dir, repos, nil
+}
+
+// runBatchRepo runs the command in repo with a child ghquick process, so each
+// repository gets its own flags and config, and then reads the repository's state
+func runBatchRepo(self, root, repo string, args []string) batchResult {
+	result := batchResult{Repo: repo, Path: repo}
+	if rel, err := filepath.Rel(root, repo); err == nil && !strings.HasPrefix(rel, "..") {
+		result.Repo = rel
+	}
+	if result.Repo == "." {
+		result.Repo = filepath.Base(repo)
+	}
+	start := time.Now()
+
+	if interruptCtx.Err() != nil {
+		result.Error = "skipped: interrupted"
+		return result
+	}
+	if args[0] != "status" {
+		result.OK, result.Error = runBatchChild(self, repo, args)
+	} else {
+		result.OK = true
+	}
+
+	ctx, cancel := commandContext(timeout)
+	defer cancel()
+	gitOps := newGitOps(repo)
+	branches, err := gitOps.ListBranches(ctx)
+	if err != nil && result.OK {
+		result.OK, result.Error = false, err.Error()
+	}
+	for _, b := range branches {
+		if b.Current {
+			result.Branch, result.Upstream, result.Ahead, result.Behind = b.Name, b.Upstream, b.Ahead, b.Behind
+		}
+	}
+	if result.Branch == "" {
+		result.Branch, _ = gitOps.CurrentBranch(ctx)
+	}
+	if changes, err := gitOps.GetStatus(ctx); err == nil {
+		result.Changes = len(changes)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// runBatchChild runs ghquick with args in repo and returns whether it
+// succeeded and, if not, the error from its JSON result
+func runBatchChild(self, repo string, args []string) (bool, string) {
+	childArgs := []string{"--dir", repo, "--output", "json", "--no-hints"}
+	if configPath != "" {
+		childArgs = append(childArgs, "--config", configPath)
+	}
+	if dryRun {
+		childArgs = append(childArgs, "--dry-run")
+	}
+	if quiet {
+		childArgs = append(childArgs, "--quiet")
+	}
+	if debug {
+		childArgs = append(childArgs, "--debug")
+	}
+	if logLevel != "" {
+		childArgs = append(childArgs, "--log-level", logLevel)
+	}
+	if maxPar > 0 {
+		childArgs = append(childArgs, "--max-parallel", strconv.Itoa(maxPar))
+	}
+	if networkTimeout > 0 {
+		childArgs = append(childArgs, "--network-timeout", networkTimeout.String())
+	}
+	if localTimeout > 0 {
+		childArgs = append(childArgs, "--local-timeout", localTimeout.String())
+	}
+	childArgs = append(childArgs, args...)
+
+	var stdout, stderr bytes.Buffer
+	// Not bound to interruptCtx: the child shares our process group, so Ctrl-C
+	// already reaches it and it rolls back what it started on its own. Sending
+	// it a second SIGINT could cut that rollback short.
+	child := exec.Command(self, childArgs...)
+	child.Dir = repo
+	if tokenFlag != "" {
+		// Through the environment, not argv, where other users can read it
+		child.Env = append(os.Environ(), config.EnvGitHubToken+"="+tokenFlag)
+	}
+	child.Stdout, child.Stderr = &stdout, &stderr
+	logger.Debug("Running %s in %s", strings.Join(args, " "), repo)
+	runErr := child.Run()
+
+	// The last line of JSON output is the result; errors logged before it
+	// explain a failure the result doesn't
+	var result map[string]interface{}
+	var lastError string
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			continue
+		}
+		switch event["level"] {
+		case "result":
+			result = event
+		case "error":
+			lastError, _ = event["message"].(string)
+		}
+	}
+
+	if result != nil {
+		if ok, _ := result["ok"].(bool); ok && runErr == nil {
+			return true, ""
+		}
+		if msg, _ := result["error"].(string); msg != "" {
+			return false, msg
+		}
+	}
+	switch {
+	case lastError != "":
+		return false, lastError
+	case strings.TrimSpace(stderr.String()) != "":
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		return false, lines[len(lines)-1]
+	case runErr != nil:
+		return false, runErr.Error()
+	}
+	return false, "no result reported"
+}
+
+// reportBatchResult logs a repository as soon as its command finishes
+func reportBatchResult(r batchResult) {
+	if log.JSON() {
+		logger.Event(map[string]interface{}{"repo": r.Repo, "ok": r.OK, "error": r.Error})
+		return
+	}
+	if r.OK {
+		logger.Success("%s (%s)", r.Repo, r.Duration.Round(time.Millisecond))
+	} else {
+		logger.Error("%s: %s", r.Repo, r.Error)
+	}
+}
+
+// printBatchTable prints one row per repository
+func printBatchTable(results []batchResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tBRANCH\tCHANGES\tUPSTREAM\tTIME\tRESULT")
+	for _, r := range results {
+		upstream := "none"
+		if r.Upstream != "" {
+			upstream = fmt.Sprintf("%s +%d -%d", r.Upstream, r.Ahead, r.Behind)
+		}
+		outcome := "ok"
+		if !r.OK {
+			// Only the first line of a git error fits in a table cell
+			first, _, _ := strings.Cut(r.Error, "\n")
+			outcome = "failed: " + first
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", r.Repo, strutil.FirstNonEmpty(r.Branch, "-"), r.Changes, upstream, r.Duration.Round(time.Millisecond), outcome)
+	}
+	w.Flush()
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	branchBase   string
+	branchDirty  string
+	branchMerged bool
+	branchRemote bool
+	branchForce  bool
+	branchYes    bool
+)
+
+func init() {
+	rootCmd.AddCommand(branchCmd)
+	branchCmd.AddCommand(branchCreateCmd, branchSwitchCmd, branchListCmd, branchDeleteCmd)
+
+	branchCreateCmd.Flags().StringVar(&branchBase, "base", "", "Branch or commit to start from (defaults to the remote's default branch)")
+	for _, c := range []*cobra.Command{branchCreateCmd, branchSwitchCmd} {
+		c.Flags().StringVar(&branchDirty, "dirty", "", "What to do with local changes: stash (default), abort, or carry")
+		_ = c.RegisterFlagCompletionFunc("dirty", completeValues(dirtyPolicies...))
+	}
+	branchDeleteCmd.Flags().BoolVar(&branchMerged, "merged", false, "Delete every branch already merged into the default branch")
+	branchDeleteCmd.Flags().BoolVar(&branchRemote, "remote", false, "Delete the branches on origin too")
+	branchDeleteCmd.Flags().BoolVar(&branchForce, "force", false, "Delete branches even if they have unmerged commits")
+	branchDeleteCmd.Flags().BoolVarP(&branchYes, "yes", "y", false, "Don't ask before deleting merged branches")
+}
+
+var branchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "Create, switch, list and delete branches",
+	Long: `Manage local branches.
+Example:
+  ghquick branch create feature/login          # From the up-to-date default branch
+  ghquick branch switch main                   # Stashes and restores local changes
+  ghquick branch list                          # With ahead/behind counts
+  ghquick branch delete --merged --remote      # Clean up merged branches here and on origin`,
+}
+
+var branchCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a branch from a base and switch to it",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, fileCfg, err := branchSetup()
+		if err != nil {
+			return err
+		}
+		policy, err := branchDirtyPolicy(fileCfg)
+		if err != nil {
+			return err
+		}
+
+		base := branchBase
+		if base == "" {
+			if base, err = defaultBranchRef(ctx, gitOps, fileCfg); err != nil {
+				return err
+			}
+		}
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		} else if !isInteractive() {
+			return fmt.Errorf("name the branch to create")
+		} else if name, err = promptValue("Branch name", ""); err != nil {
+			return err
+		}
+		return gitOps.CreateBranch(ctx, name, base, policy)
+	},
+}
+
+var branchSwitchCmd = &cobra.Command{
+	Use:               "switch [name]",
+	Short:             "Switch branches, stashing and restoring local changes",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: singleArg(completeBranches),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, fileCfg, err := branchSetup()
+		if err != nil {
+			return err
+		}
+		policy, err := branchDirtyPolicy(fileCfg)
+		if err != nil {
+			return err
+		}
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		} else if name, err = promptBranch(ctx, gitOps, "name the branch to switch to"); err != nil {
+			return err
+		}
+		return gitOps.Checkout(ctx, name, policy)
+	},
+}
+
+var branchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List local branches with how far they are ahead of or behind their upstream",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, _, err := branchSetup()
+		if err != nil {
+			return err
+		}
+		branches, err := gitOps.ListBranches(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !log.JSON() {
+			for _, b := range branches {
+				marker := " "
+				if b.Current {
+					marker = "*"
+				}
+				var status string
+				switch {
+				case b.Upstream == "":
+					status = "no upstream"
+				case b.Gone:
+					status = b.Upstream + ", gone"
+				case b.Ahead == 0 && b.Behind == 0:
+					status = b.Upstream + ", up to date"
+				default:
+					status = fmt.Sprintf("%s, ahead %d, behind %d", b.Upstream, b.Ahead, b.Behind)
+				}
+				fmt.Printf("%s %s (%s)\n", marker, b.Name, status)
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "branches": branches})
+		return nil
+	},
+}
+
+var branchDeleteCmd = &cobra.Command{
+	Use:               "delete [name...]",
+	Short:             "Delete branches locally, and with --remote on origin",
+	ValidArgsFunction: completeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, fileCfg, err := branchSetup()
+		if err != nil {
+			return err
+		}
+
+		names := args
+		if branchMerged {
+			base, err := defaultBranchRef(ctx, gitOps, fileCfg)
+			if err != nil {
+				return err
+			}
+			merged, err := gitOps.MergedBranches(ctx, base)
+			if err != nil {
+				return err
+			}
+			if len(merged) == 0 {
+				logger.Info("No branches are merged into %s", base)
+				return nil
+			}
+			logger.Info("Merged into %s: %s", base, strings.Join(merged, ", "))
+			if !branchYes {
+				if !isInteractive() {
+					return fmt.Errorf("refusing to delete merged branches without --yes")
+				}
+				answer, err := readLine(fmt.Sprintf("Delete %d branch(es)? [y/N]: ", len(merged)))
+				if err != nil {
+					return err
+				}
+				if a := strings.ToLower(answer); a != "y" && a != "yes" {
+					return fmt.Errorf("nothing was deleted")
+				}
+			}
+			names = append(names, merged...)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("name the branches to delete, or pass --merged")
+		}
+
+		remote := ""
+		if branchRemote {
+			remote = "origin"
+		}
+		for _, name := range names {
+			if err := gitOps.DeleteBranch(ctx, name, remote, branchForce); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// branchSetup resolves the working directory and loads its config
+func branchSetup() (*git.Operations, *config.FileConfig, error) {
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	fileCfg, err := loadFileConfig(wd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	gitOps := newGitOps(wd)
+	gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+	return gitOps, fileCfg, nil
+}
+
+// branchDirtyPolicy picks the dirty-tree policy for branch commands: --dirty,
+// then checkout_dirty_policy, then stash
+func branchDirtyPolicy(fileCfg *config.FileConfig) (git.DirtyPolicy, error) {
+	return git.ParseDirtyPolicy(strutil.FirstNonEmpty(branchDirty, fileCfg.CheckoutDirtyPolicy, string(git.DirtyStash)))
+}
+
+// defaultBranchRef returns the default branch to start from or compare with,
+// preferring its remote-tracking branch so new work starts from what was pushed
+func defaultBranchRef(ctx context.Context, gitOps *git.Operations, fileCfg *config.FileConfig) (string, error) {
+	name := fileCfg.DefaultBranch
+	if name == "" {
+		detected, err := gitOps.DefaultBranch(ctx, "origin")
+		if err != nil {
+			return "", fmt.Errorf("could not detect the default branch; pass --base or set default_branch: %w", err)
+		}
+		name = detected
+	}
+	if gitOps.RemoteBranchExists(ctx, "origin", name) {
+		return "origin/" + name, nil
+	}
+	return name, nil
+}
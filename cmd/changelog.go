@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/saint/ghquick/internal/ai"
+	"github.com/saint/ghquick/internal/changelog"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogVersion string
+	changelogDate    string
+	changelogFile    string
+	changelogAll     bool
+	changelogAI      bool
+	changelogStdout  bool
+	changelogStage   bool
+)
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", "", "Release the changes as this version instead of listing them under Unreleased")
+	changelogCmd.Flags().StringVar(&changelogDate, "date", "", "Release date for --version, YYYY-MM-DD (defaults to the tag's date, else today)")
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "CHANGELOG.md", "Changelog to write, relative to the repository")
+	changelogCmd.Flags().BoolVar(&changelogAll, "all", false, "Also list docs, test, build, CI, style and chore commits")
+	changelogCmd.Flags().BoolVar(&changelogAI, "ai", false, "Rewrite the entries from commit subjects into prose for users")
+	changelogCmd.Flags().BoolVar(&changelogStdout, "stdout", false, "Print the section instead of writing the changelog")
+	changelogCmd.Flags().BoolVar(&changelogStage, "stage", false, "Stage the changelog so the next commit includes it")
+}
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [from] [to]",
+	Short: "Write the commits since the last tag to CHANGELOG.md",
+	Long: `Update CHANGELOG.md in Keep a Changelog format (https://keepachangelog.com)
+with the commits in from..to, grouped by their Conventional Commit type: feat
+under Added, fix under Fixed, and so on. from defaults to the latest tag and to
+to HEAD. The changes replace the Unreleased section, or with --version, become
+that version's section below an emptied Unreleased one; a section that's already
+there is replaced, so running it again is safe. Compare links at the bottom are
+kept up to date from origin.
+Example:
+  ghquick changelog                        # Refresh the Unreleased section
+  ghquick changelog --version v1.4.0 --ai --stage
+  ghquick changelog v1.2.0 v1.3.0 --version v1.3.0 --stdout`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		log.SetPhase("setup")
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		gitOps := newGitOps(wd)
+		if changelogStdout && changelogStage {
+			return fmt.Errorf("--stage needs the changelog written; drop --stdout")
+		}
+
+		version := strutil.FirstNonEmpty(changelogVersion, changelog.Unreleased)
+		released := changelogVersion != ""
+		from, to := "", "HEAD"
+		if len(args) > 1 {
+			to = args[1]
+		} else if released && gitOps.TagExists(ctx, version) {
+			// Already tagged: the release's changes end at its tag
+			to = version
+		}
+		if len(args) > 0 {
+			from = args[0]
+		} else {
+			from = gitOps.TagBefore(ctx, to)
+			if from == to {
+				from = gitOps.TagBefore(ctx, to+"^")
+			}
+		}
+		date := changelogDate
+		if released && date == "" {
+			date = time.Now().Format("2006-01-02")
+			if tags, err := gitOps.ListTags(ctx, version); err == nil && len(tags) == 1 {
+				date = tags[0].Date.Local().Format("2006-01-02")
+			}
+		}
+
+		log.SetPhase("notes")
+		data, err := gitOps.ClassifyCommits(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		body, count := changelog.Body(data, changelogAll)
+		if count == 0 {
+			if released {
+				return fmt.Errorf("no changes to list for %s since %s", version, strutil.FirstNonEmpty(from, "the first commit"))
+			}
+			logger.Info("No unreleased changes since %s", strutil.FirstNonEmpty(from, "the first commit"))
+		}
+		if changelogAI && count > 0 {
+			cfg, err := config.Load(tokenResolver())
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			provider, err := ai.NewProvider(aiProviderConfig(cfg, fileCfg))
+			if err != nil {
+				return err
+			}
+			logger.Step("Polishing the changelog entries...")
+			if polished, err := ai.NewCommitMessageGenerator(provider).PolishChangelog(ctx, body); err != nil {
+				logger.Warning("Keeping the entries as written in the commits: %v", err)
+			} else {
+				body = polished
+			}
+		}
+		section := changelog.Section{Version: version, Date: date, Body: body}
+
+		if changelogStdout {
+			// In JSON mode the section goes in the result, keeping stdout to JSON lines
+			if !log.JSON() {
+				fmt.Print(section.String())
+			}
+			logger.Result(map[string]interface{}{"ok": true, "version": version, "from": from, "to": to, "entries": count, "markdown": section.String()})
+			return nil
+		}
+
+		path := filepath.Join(wd, changelogFile)
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", changelogFile, err)
+		}
+		repoURL := ""
+		if owner, name, err := gitOps.RemoteRepo(ctx, strutil.FirstNonEmpty(fileCfg.Remote, "origin")); err == nil {
+			repoURL = fmt.Sprintf("https://%s/%s/%s", strutil.FirstNonEmpty(fileCfg.GitHubHost, "github.com"), owner, name)
+		}
+		updated := changelog.Update(string(existing), section, repoURL, from)
+
+		if dryRun {
+			logger.Info("[dry-run] Would write %s with:\n%s", changelogFile, section.String())
+		} else {
+			if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", changelogFile, err)
+			}
+			logger.Success("Updated %s: %d change(s) under %s", changelogFile, count, version)
+		}
+		if changelogStage {
+			// An unchanged changelog leaves nothing to stage
+			if err := gitOps.StageFiles(ctx, []string{changelogFile}); err != nil && !errors.Is(err, git.ErrNoChanges) {
+				return err
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "file": changelogFile, "version": version, "from": from, "to": to, "entries": count, "staged": changelogStage})
+		return nil
+	},
+}
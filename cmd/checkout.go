@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var dirtyPolicy string
+
+func init() {
+	rootCmd.AddCommand(checkoutCmd)
+	checkoutCmd.Flags().StringVar(&dirtyPolicy, "dirty", "", "What to do with local changes: abort, stash, or carry (default abort)")
+	_ = checkoutCmd.RegisterFlagCompletionFunc("dirty", completeValues(dirtyPolicies...))
+}
+
+var checkoutCmd = &cobra.Command{
+	Use:   "checkout [branch]",
+	Short: "Switch branches with a predictable dirty-tree policy",
+	Long: `Switch to another branch. When the working tree has local changes, the
+policy decides what happens: abort (refuse), stash (auto-stash and restore),
+or carry (git's default behavior). Set checkout_dirty_policy in .ghquick.yaml
+to change the default. Without a branch, it asks which one to switch to.
+Example:
+  ghquick checkout feature/login --dirty stash`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: singleArg(completeBranches),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name := dirtyPolicy
+		if name == "" {
+			name = fileCfg.CheckoutDirtyPolicy
+		}
+		policy, err := git.ParseDirtyPolicy(name)
+		if err != nil {
+			return err
+		}
+
+		gitOps := newGitOps(wd)
+		branch := ""
+		if len(args) > 0 {
+			branch = args[0]
+		} else if branch, err = promptBranch(ctx, gitOps, "name the branch to switch to"); err != nil {
+			return err
+		}
+		return gitOps.Checkout(ctx, branch, policy)
+	},
+}
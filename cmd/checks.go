@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checksInterval time.Duration
+	checksTimeout  time.Duration
+	checksOnce     bool
+	checksRemote   string
+)
+
+const (
+	defaultChecksInterval = 10 * time.Second
+	defaultChecksTimeout  = 30 * time.Minute
+	// checksAppearWait is how long a pushed commit may go without any check
+	// runs before it is taken to have no checks configured
+	checksAppearWait = time.Minute
+)
+
+func init() {
+	rootCmd.AddCommand(checksCmd)
+	checksCmd.Flags().DurationVar(&checksInterval, "interval", defaultChecksInterval, "How often to poll the checks")
+	checksCmd.Flags().DurationVar(&checksTimeout, "timeout", defaultChecksTimeout, "Give up waiting for the checks after this long")
+	checksCmd.Flags().BoolVar(&checksOnce, "once", false, "Show the checks' current state instead of waiting for them to finish")
+	checksCmd.Flags().StringVar(&checksRemote, "remote", "origin", "Remote whose GitHub repository runs the checks")
+}
+
+var checksCmd = &cobra.Command{
+	Use:   "checks [commit]",
+	Short: "Follow the GitHub Actions and other check runs for a pushed commit",
+	Long: `Poll the Checks API for a pushed commit (HEAD by default) and report each check
+run as it is queued, starts and finishes, until all of them have completed. It
+exits non-zero when any check fails, is cancelled or times out, so it can gate
+a script on CI. 'ghquick push --watch' does the same right after pushing.
+Example:
+  ghquick checks
+  ghquick checks v1.4.0 --once
+  ghquick checks --interval 30s --timeout 1h`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(checksTimeout)
+		defer cancel()
+
+		cfg, err := config.LoadGitHub(tokenResolver())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		gitOps := newGitOps(wd)
+		ghClient := newGitHubClient(cfg.GitHubToken)
+		ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+		rev := "HEAD"
+		if len(args) > 0 {
+			rev = args[0]
+		}
+		sha, err := gitOps.ResolveCommit(ctx, rev)
+		if err != nil {
+			return err
+		}
+		if pushed, err := gitOps.IsPushed(ctx, sha); err == nil && !pushed {
+			logger.Warning("%s is not on any remote-tracking branch; GitHub may not know it", shortSHA(sha))
+		}
+		owner, repo, err := gitOps.RemoteRepo(ctx, checksRemote)
+		if err != nil {
+			return err
+		}
+
+		var runs []github.CheckRun
+		if checksOnce {
+			runs, err = ghClient.ListCheckRuns(ctx, owner, repo, sha)
+			if err == nil {
+				for _, r := range runs {
+					reportCheckRun(r)
+				}
+				err = checkRunsOutcome(runs, sha)
+			}
+		} else {
+			runs, err = watchChecks(ctx, ghClient, owner, repo, sha)
+		}
+		result := map[string]interface{}{"ok": err == nil, "sha": sha, "checks": runs}
+		if err != nil {
+			result["error"] = err.Error()
+		}
+		logger.Result(result)
+		return err
+	},
+}
+
+// watchChecks polls the check runs for sha, reporting each one as its state
+// changes, until they have all completed. It fails when any of them failed.
+// A commit with no check runs after checksAppearWait has none configured.
+func watchChecks(ctx context.Context, ghClient *github.Client, owner, repo, sha string) ([]github.CheckRun, error) {
+	logger.Step("Waiting for the checks on %s...", shortSHA(sha))
+	start := time.Now()
+	seen := map[string]string{}
+	for {
+		runs, err := ghClient.ListCheckRuns(ctx, owner, repo, sha)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("gave up waiting for the checks on %s after %v", shortSHA(sha), time.Since(start).Round(time.Second))
+			}
+			return nil, err
+		}
+
+		done := len(runs) > 0
+		for _, r := range runs {
+			if state := r.Status + "/" + r.Conclusion; seen[r.Name] != state {
+				seen[r.Name] = state
+				reportCheckRun(r)
+			}
+			done = done && r.Completed()
+		}
+		if done {
+			return runs, checkRunsOutcome(runs, sha)
+		}
+		if len(runs) == 0 && time.Since(start) >= checksAppearWait {
+			logger.Warning("No checks ran for %s; the repository may not have any workflows for this branch", shortSHA(sha))
+			return runs, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return runs, fmt.Errorf("gave up waiting for the checks on %s after %v", shortSHA(sha), time.Since(start).Round(time.Second))
+		case <-time.After(checksInterval):
+		}
+	}
+}
+
+// reportCheckRun reports a check run's current state
+func reportCheckRun(r github.CheckRun) {
+	if log.JSON() {
+		logger.Event(map[string]interface{}{"check": r.Name, "status": r.Status, "conclusion": r.Conclusion, "url": r.URL})
+		return
+	}
+	switch {
+	case !r.Completed() && r.Status == "queued":
+		logger.Info("⏳ %s queued", r.Name)
+	case !r.Completed():
+		logger.Step("%s running...", r.Name)
+	case r.Failed():
+		logger.Error("%s: %s (%s)", r.Name, strings.ReplaceAll(r.Conclusion, "_", " "), r.URL)
+	case r.Conclusion == "success":
+		took := ""
+		if !r.StartedAt.IsZero() && !r.CompletedAt.IsZero() {
+			took = fmt.Sprintf(" (%s)", r.CompletedAt.Sub(r.StartedAt).Round(time.Second))
+		}
+		logger.Success("%s passed%s", r.Name, took)
+	default:
+		logger.Info("%s %s", r.Name, r.Conclusion)
+	}
+}
+
+// checkRunsOutcome summarizes finished check runs, failing when any of them failed
+func checkRunsOutcome(runs []github.CheckRun, sha string) error {
+	var failed, pending []string
+	for _, r := range runs {
+		switch {
+		case r.Failed():
+			failed = append(failed, r.Name)
+		case !r.Completed():
+			pending = append(pending, r.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d checks failed for %s: %s", len(failed), len(runs), shortSHA(sha), strings.Join(failed, ", "))
+	}
+	switch {
+	case len(runs) == 0:
+		logger.Info("No checks for %s", shortSHA(sha))
+	case len(pending) > 0:
+		logger.Info("%d of %d checks still running: %s", len(pending), len(runs), strings.Join(pending, ", "))
+	default:
+		logger.Success("All %d checks passed for %s", len(runs), shortSHA(sha))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(classifyCmd)
+}
+
+var classifyCmd = &cobra.Command{
+	Use:   "classify <from> [to]",
+	Short: "Group commits in a range by Conventional Commit type as JSON",
+	Long: `Classify each commit in from..to (to defaults to HEAD) by its Conventional
+Commit type and print the grouping as JSON, for feeding changelog tools.
+Commits that don't follow the convention are grouped under "other".
+Example:
+  ghquick classify v1.2.0
+  ghquick classify v1.2.0 v1.3.0 > changes.json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+
+		to := ""
+		if len(args) > 1 {
+			to = args[1]
+		}
+		data, err := newGitOps(wd).ClassifyCommits(ctx, args[0], to)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	},
+}
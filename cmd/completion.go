@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the git commands run while completing, so a slow
+// repository doesn't hang the shell
+const completionTimeout = 2 * time.Second
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate the shell completion script",
+	Long: `Print a completion script for your shell. Besides commands and flags it
+completes branch, tag and stash names from the repository, and the values of
+flags such as --dirty and --commit-type.
+
+  bash:        source <(ghquick completion bash)
+               # or, for every new shell (needs bash-completion):
+               ghquick completion bash > /etc/bash_completion.d/ghquick
+  zsh:         ghquick completion zsh > "${fpath[1]}/_ghquick"
+               # with "autoload -U compinit; compinit" in ~/.zshrc
+  fish:        ghquick completion fish > ~/.config/fish/completions/ghquick.fish
+  powershell:  ghquick completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.ExactArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		return fmt.Errorf("unknown shell %q (expected bash, zsh, fish or powershell)", args[0])
+	},
+}
+
+// completeValues completes a flag that takes one of a fixed set of values
+func completeValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFromRepo completes with names listed from the repository --dir or
+// --repo points at, skipping ones already given as arguments
+func completeFromRepo(list func(context.Context, *git.Operations) ([]string, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		ctx, cancel := commandContext(completionTimeout)
+		defer cancel()
+		names, err := list(ctx, git.NewOperations(wd, false))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		given := map[string]bool{}
+		for _, a := range args {
+			given[a] = true
+		}
+		var out []string
+		for _, n := range names {
+			if !given[n] && strings.HasPrefix(n, toComplete) {
+				out = append(out, n)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeBranches completes local branch names
+var completeBranches = completeFromRepo(func(ctx context.Context, o *git.Operations) ([]string, error) {
+	branches, err := o.ListBranches(ctx)
+	var names []string
+	for _, b := range branches {
+		names = append(names, b.Name)
+	}
+	return names, err
+})
+
+// completeTags completes tag names
+var completeTags = completeFromRepo(func(ctx context.Context, o *git.Operations) ([]string, error) {
+	tags, err := o.ListTags(ctx)
+	var names []string
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names, err
+})
+
+// completeStashes completes the names stash entries were saved with
+var completeStashes = completeFromRepo(func(ctx context.Context, o *git.Operations) ([]string, error) {
+	entries, err := o.ListStashes(ctx)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	return names, err
+})
+
+// singleArg limits a positional completion to the first argument
+func singleArg(complete func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// dirtyPolicies are the values of the --dirty flags
+var dirtyPolicies = []string{string(git.DirtyStash), string(git.DirtyAbort), string(git.DirtyCarry)}
+
+// commonLicenses are offered for --license; GitHub knows a few more
+var commonLicenses = []string{"mit", "apache-2.0", "gpl-3.0", "agpl-3.0", "lgpl-3.0", "bsd-2-clause", "bsd-3-clause", "mpl-2.0", "unlicense"}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var configLocal bool
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd)
+	configSetCmd.Flags().BoolVar(&configLocal, "local", false, "Write to the repository's .ghquick.yaml (or .ghquick.toml) instead of the global config")
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change ghquick settings",
+	Long: `Read and change the settings in the global config ($HOME/.ghquick.yaml,
+$HOME/.ghquick/config.toml, or --config) and the repository's .ghquick.yaml or
+.ghquick.toml, which overrides it. A TOML file is used instead of the YAML one
+when it exists; writing to it drops its comments.
+Example:
+  ghquick config set ai_model gpt-4o
+  ghquick config set --local default_branch develop
+  ghquick config set exclude "[.env, dist]"
+  ghquick config get default_branch    # The effective value in this repository`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fileCfg, err := loadConfigForCommand()
+		if err != nil {
+			return err
+		}
+		value, err := fileCfg.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if log.JSON() {
+			logger.Result(map[string]interface{}{"ok": true, "key": args[0], "value": value})
+			return nil
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the global or repository config",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configPath
+		if configLocal {
+			if config.GlobalOnly(args[0]) {
+				return fmt.Errorf("%s can only be set in the global config", args[0])
+			}
+			wd, err := resolveWorkingDir()
+			if err != nil {
+				return err
+			}
+			path = config.RepoConfigPath(wd)
+		} else if path == "" {
+			if path = config.DefaultConfigPath(); path == "" {
+				return fmt.Errorf("could not locate the home directory; pass --config")
+			}
+		}
+
+		if dryRun {
+			logger.Info("[dry-run] Would set %s: %s in %s", args[0], args[1], path)
+			return nil
+		}
+		if err := config.SetValue(path, args[0], args[1]); err != nil {
+			return err
+		}
+		logger.Success("Set %s in %s", args[0], path)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every setting with its effective value",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fileCfg, err := loadConfigForCommand()
+		if err != nil {
+			return err
+		}
+		settings := make(map[string]string)
+		for _, key := range config.Keys() {
+			value, err := fileCfg.Lookup(key)
+			if err != nil {
+				return err
+			}
+			settings[key] = value
+			if !log.JSON() {
+				fmt.Printf("%s: %s\n", key, value)
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "settings": settings})
+		return nil
+	},
+}
+
+// loadConfigForCommand loads the merged config for the working directory
+func loadConfigForCommand() (*config.FileConfig, error) {
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadFileConfig(wd)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint/ghquick/internal/checks"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// noVerify skips ghquick's hooks and git's own commit and push hooks
+	noVerify bool
+	// hookTimeout limits each hook, in place of the command's own --timeout
+	hookTimeout time.Duration
+)
+
+// defaultHookTimeout is how long a hook may run when neither --hook-timeout nor
+// hook_timeout is set; test suites and linters often outlast the commands' two minutes
+const defaultHookTimeout = 10 * time.Minute
+
+const (
+	hookPreCommit = "pre-commit"
+	hookPrePush   = "pre-push"
+)
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksListCmd, hooksRunCmd)
+	hooksRunCmd.Flags().DurationVar(&hookTimeout, "hook-timeout", 0, "Give up on a hook after this long (default hook_timeout config, or 10m)")
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "List and run the pre-commit and pre-push hooks from .ghquick.yaml",
+	Long: `Hooks are commands push and amend run before committing (pre_commit) and
+before pushing (pre_push), such as tests and linters. Their output is shown as
+they run, and the first one that fails stops the command: nothing is committed,
+or the new commit is kept locally without being pushed. --no-verify skips them,
+along with git's own hooks. Each hook may run for hook_timeout (default 10m,
+or --hook-timeout), which doesn't count against the command's --timeout.
+pre_commit_checks, a plain list of commands, is the older spelling of
+hooks.pre_commit and still works; push's --check adds one more.
+
+  hook_timeout: 15m
+  hooks:
+    pre_commit:
+      - gofmt -l . | (! grep .)
+      - name: lint
+        run: golangci-lint run
+    pre_push:
+      - go test ./...
+Example:
+  ghquick hooks list
+  ghquick hooks run pre-push`,
+}
+
+var hooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the configured hooks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if !log.JSON() {
+			for _, stage := range []string{hookPreCommit, hookPrePush} {
+				hooks := stageHooks(fileCfg, stage)
+				if len(hooks) == 0 {
+					fmt.Printf("%s: none\n", stage)
+					continue
+				}
+				fmt.Printf("%s:\n", stage)
+				for _, h := range hooks {
+					if h.Name != "" {
+						fmt.Printf("  %s: %s\n", h.Name, h.Run)
+					} else {
+						fmt.Printf("  %s\n", h.Run)
+					}
+				}
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "pre_commit": fileCfg.Hooks.PreCommit, "pre_push": fileCfg.Hooks.PrePush})
+		return nil
+	},
+}
+
+var hooksRunCmd = &cobra.Command{
+	Use:       "run <pre-commit|pre-push>",
+	Short:     "Run one stage's hooks now",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{hookPreCommit, hookPrePush},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stage := args[0]
+		if stage != hookPreCommit && stage != hookPrePush {
+			return fmt.Errorf("unknown hook stage %q (expected %s or %s)", stage, hookPreCommit, hookPrePush)
+		}
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		hooks := stageHooks(fileCfg, stage)
+		if len(hooks) == 0 {
+			logger.Info("No %s hooks configured", stage)
+		}
+		return runHooks(ctx, wd, stage, hooks, hookLimit(fileCfg))
+	},
+}
+
+// stageHooks returns the hooks configured for stage
+func stageHooks(fileCfg *config.FileConfig, stage string) []config.Hook {
+	if stage == hookPrePush {
+		return fileCfg.Hooks.PrePush
+	}
+	return fileCfg.Hooks.PreCommit
+}
+
+// hookLimit is how long each hook may run
+func hookLimit(fileCfg *config.FileConfig) time.Duration {
+	return firstPositiveDuration(hookTimeout, fileCfg.HookTimeout, defaultHookTimeout)
+}
+
+// runHooks runs a stage's hooks in order in the repository, showing their
+// output as it arrives, and stops at the first one that fails. Each may run
+// for limit; the command's own timeout is paused meanwhile.
+func runHooks(ctx context.Context, wd, stage string, hooks []config.Hook, limit time.Duration) error {
+	if noVerify {
+		if len(hooks) > 0 {
+			logger.Warning("Skipping %d %s hook(s) (--no-verify)", len(hooks), stage)
+		}
+		return nil
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	defer pauseTimeout()()
+	for _, h := range hooks {
+		logger.Step("Running %s hook: %s", stage, h.Label())
+		start := time.Now()
+		hookCtx, cancel := context.WithTimeout(ctx, limit)
+		output, err := checks.RunStreaming(hookCtx, wd, h.Run, logger.Output)
+		timedOut := errors.Is(hookCtx.Err(), context.DeadlineExceeded)
+		cancel()
+		if err != nil {
+			logger.Error("%s hook failed: %s", stage, h.Label())
+			// Quiet mode didn't show the output as it ran
+			if output = strings.TrimSpace(output); log.Quiet() && output != "" {
+				logger.Error("%s", output)
+			}
+			if timedOut {
+				return fmt.Errorf("%s hook %q timed out after %v", stage, h.Label(), limit)
+			}
+			return fmt.Errorf("%s hook %q failed: %w", stage, h.Label(), err)
+		}
+		logger.Success("%s hook passed: %s (%s)", stage, h.Label(), time.Since(start).Round(100*time.Millisecond))
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initGitignore   []string
+	initLicense     string
+	initReadme      bool
+	initDescription string
+	initPrivate     bool
+	initNoRemote    bool
+	initNoPush      bool
+	initMessage     string
+	initRefresh     bool
+	initForce       bool
+	initExisting    bool
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringSliceVar(&initGitignore, "gitignore", nil, ".gitignore templates to combine, e.g. Go,Node (from github/gitignore)")
+	initCmd.Flags().StringVar(&initLicense, "license", "", "Write a LICENSE, e.g. mit or apache-2.0")
+	_ = initCmd.RegisterFlagCompletionFunc("license", completeValues(commonLicenses...))
+	initCmd.Flags().BoolVar(&initReadme, "readme", false, "Write a README.md stub")
+	initCmd.Flags().StringVar(&initDescription, "description", "", "Repository description, also used in the README")
+	initCmd.Flags().BoolVar(&initPrivate, "private", false, "Create the repository as private")
+	initCmd.Flags().BoolVar(&initNoRemote, "no-remote", false, "Only scaffold and make the initial commit locally; don't create a GitHub repository")
+	initCmd.Flags().BoolVar(&initNoPush, "no-push", false, "Create the repository and configure origin without pushing")
+	initCmd.Flags().StringVarP(&initMessage, "message", "m", "Initial commit", "Message for the initial commit")
+	initCmd.Flags().BoolVar(&initRefresh, "refresh", false, "Download templates again instead of using cached copies")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite .gitignore, LICENSE and README.md if they exist")
+	initCmd.Flags().BoolVar(&initExisting, "existing", false, "If the repository already exists on GitHub, set up origin and push to it instead of failing")
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init [name]",
+	Short: "Scaffold a new project and push it to a new GitHub repository",
+	Long: `Start a project in this directory: write a .gitignore combined from
+github/gitignore templates, optionally a LICENSE and a README.md stub, then
+initialize git, create the GitHub repository and push the first commit, like
+'repo create'. The name defaults to the directory name, which a terminal
+session asks to confirm. Templates are cached under ~/.cache/ghquick/templates,
+so later runs work offline. Existing files are kept unless --force is given.
+Example:
+  ghquick init --gitignore Go
+  ghquick init my-tool --gitignore Go,Node --license mit --readme --private
+  ghquick init --gitignore Python --no-remote`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		log.SetPhase("setup")
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		cfg, err := config.LoadGitHub(tokenResolver())
+		if err != nil {
+			if !initNoRemote {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Templates can be downloaded without signing in
+			cfg = &config.Config{GitHubUsername: os.Getenv(config.EnvGitHubUsername)}
+		}
+		ghClient := newGitHubClient(cfg.GitHubToken)
+		ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+		name := filepath.Base(wd)
+		if len(args) > 0 {
+			name = args[0]
+		} else if isInteractive() {
+			if name, err = promptValue("Repository name", name); err != nil {
+				return err
+			}
+		}
+
+		log.SetPhase("scaffold")
+		files, err := scaffoldFiles(ctx, ghClient, name, strutil.FirstNonEmpty(fileCfg.AuthorName, cfg.GitHubUsername))
+		if err != nil {
+			return err
+		}
+		written, err := writeScaffold(wd, files)
+		if err != nil {
+			return err
+		}
+
+		if initNoRemote {
+			return initLocal(ctx, fileCfg, wd, written)
+		}
+		result, err := publishRepository(ctx, cfg, fileCfg, wd, name, github.RepoOptions{
+			Private:     initPrivate,
+			Description: initDescription,
+			Existing:    initExisting,
+		}, initMessage, initNoPush)
+		if err != nil || result == nil {
+			return err
+		}
+		result["files"] = written
+		logger.Result(result)
+		return nil
+	},
+}
+
+// scaffoldFile is a file init writes
+type scaffoldFile struct {
+	name    string
+	content string
+}
+
+// scaffoldFiles builds the files the flags ask for. holder is the LICENSE's
+// copyright holder.
+func scaffoldFiles(ctx context.Context, ghClient *github.Client, name, holder string) ([]scaffoldFile, error) {
+	var files []scaffoldFile
+	if len(initGitignore) > 0 {
+		var parts []string
+		for _, t := range initGitignore {
+			source, err := ghClient.GitignoreTemplate(ctx, t, initRefresh)
+			if err != nil {
+				return nil, err
+			}
+			source = strings.TrimSpace(source) + "\n"
+			if len(initGitignore) > 1 {
+				source = "### " + t + " ###\n" + source
+			}
+			parts = append(parts, source)
+		}
+		files = append(files, scaffoldFile{".gitignore", strings.Join(parts, "\n")})
+	}
+	if initLicense != "" {
+		body, err := ghClient.LicenseTemplate(ctx, initLicense, initRefresh)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, scaffoldFile{"LICENSE", fillLicense(body, holder)})
+	}
+	if initReadme {
+		readme := "# " + name + "\n"
+		if initDescription != "" {
+			readme += "\n" + initDescription + "\n"
+		}
+		files = append(files, scaffoldFile{"README.md", readme})
+	}
+	return files, nil
+}
+
+// fillLicense fills in the year and copyright holder placeholders GitHub's
+// license texts use. Without a holder its placeholders are left for the user.
+func fillLicense(body, holder string) string {
+	year := strconv.Itoa(time.Now().Year())
+	pairs := []string{"[year]", year, "[yyyy]", year}
+	if holder != "" {
+		pairs = append(pairs, "[fullname]", holder, "[name of copyright owner]", holder)
+	}
+	return strings.NewReplacer(pairs...).Replace(body)
+}
+
+// writeScaffold writes files into wd, keeping ones that already exist unless
+// --force was given, and returns the names written
+func writeScaffold(wd string, files []scaffoldFile) ([]string, error) {
+	written := []string{}
+	for _, f := range files {
+		path := filepath.Join(wd, f.name)
+		if _, err := os.Stat(path); err == nil && !initForce {
+			logger.Info("Keeping the existing %s (--force overwrites it)", f.name)
+			continue
+		}
+		if dryRun {
+			logger.Info("[dry-run] Would write %s", f.name)
+			written = append(written, f.name)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(f.content), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		logger.Success("Wrote %s", f.name)
+		written = append(written, f.name)
+	}
+	return written, nil
+}
+
+// initLocal initializes git in wd and makes the initial commit, for --no-remote
+func initLocal(ctx context.Context, fileCfg *config.FileConfig, wd string, written []string) error {
+	if dryRun {
+		logger.Info("[dry-run] Would initialize git and commit the working tree")
+		return nil
+	}
+	gitOps := newGitOps(wd)
+	if fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "" {
+		gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+	}
+	log.SetPhase("commit")
+	if err := gitOps.InitRepository(ctx); err != nil {
+		return fmt.Errorf("failed to setup git: %w", err)
+	}
+	branch, err := gitOps.Bootstrap(ctx, "", initMessage)
+	if err != nil {
+		return err
+	}
+	logger.Result(map[string]interface{}{"ok": true, "branch": branch, "files": written})
+	return nil
+}
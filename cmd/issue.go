@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/saint/ghquick/internal/ai"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	issueTarget     string
+	issueState      string
+	issueLabels     []string
+	issueAssignees  []string
+	issueLimit      int
+	issueTitle      string
+	issueBody       string
+	issueAI         bool
+	issueFromFile   string
+	issueComment    string
+	issueNotPlanned bool
+)
+
+func init() {
+	rootCmd.AddCommand(issueCmd)
+	issueCmd.AddCommand(issueListCmd, issueCreateCmd, issueCloseCmd, issueCommentCmd)
+	issueCmd.PersistentFlags().StringVar(&issueTarget, "target", "", "Repository as owner/name (defaults to upstream, then origin)")
+
+	issueListCmd.Flags().StringVar(&issueState, "state", "open", "Which issues to list: open, closed, or all")
+	issueListCmd.Flags().StringSliceVar(&issueLabels, "label", nil, "Only issues with all of these labels (comma-separated or repeated)")
+	issueListCmd.Flags().StringSliceVar(&issueAssignees, "assignee", nil, "Only issues assigned to this user (\"none\" for unassigned)")
+	issueListCmd.Flags().IntVar(&issueLimit, "limit", 30, "Maximum number of issues to list")
+
+	issueCreateCmd.Flags().StringVar(&issueTitle, "title", "", "Issue title")
+	issueCreateCmd.Flags().StringVar(&issueBody, "body", "", "Issue body")
+	issueCreateCmd.Flags().StringSliceVar(&issueLabels, "label", nil, "Add these labels (comma-separated or repeated)")
+	issueCreateCmd.Flags().StringSliceVar(&issueAssignees, "assignee", nil, "Assign these users (comma-separated or repeated)")
+	issueCreateCmd.Flags().BoolVar(&issueAI, "ai", false, "Draft the title and body from an error, log or diff (--from-file or stdin)")
+	issueCreateCmd.Flags().StringVar(&issueFromFile, "from-file", "", "Read the error, log or diff for --ai from this file (- for stdin)")
+
+	issueCloseCmd.Flags().StringVar(&issueComment, "comment", "", "Comment to add before closing")
+	issueCloseCmd.Flags().BoolVar(&issueNotPlanned, "not-planned", false, "Close as not planned instead of completed")
+
+	issueCommentCmd.Flags().StringVar(&issueBody, "body", "", "Comment text")
+	_ = issueCommentCmd.MarkFlagRequired("body")
+}
+
+var issueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "List, open, close and comment on GitHub issues",
+}
+
+var issueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the repository's issues",
+	Long: `List issues, newest first. Pull requests are left out.
+Example:
+  ghquick issue list
+  ghquick issue list --label bug --assignee alice
+  ghquick issue list --state closed --limit 10`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		switch issueState {
+		case "open", "closed", "all":
+		default:
+			return fmt.Errorf("invalid --state %q (expected open, closed, or all)", issueState)
+		}
+		if len(issueAssignees) > 1 {
+			return fmt.Errorf("--assignee takes a single user when listing")
+		}
+		_, _, ghClient, owner, repo, err := issueSetup(ctx)
+		if err != nil {
+			return err
+		}
+
+		issues, err := ghClient.ListIssues(ctx, owner, repo, github.IssueFilter{
+			State:    issueState,
+			Labels:   issueLabels,
+			Assignee: strings.Join(issueAssignees, ""),
+			Limit:    issueLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !log.JSON() {
+			if len(issues) == 0 {
+				logger.Info("No %s issues in %s/%s", issueState, owner, repo)
+			}
+			for _, i := range issues {
+				line := fmt.Sprintf("#%-5d %s", i.Number, i.Title)
+				if len(i.Labels) > 0 {
+					line += " [" + strings.Join(i.Labels, ", ") + "]"
+				}
+				if len(i.Assignees) > 0 {
+					line += " @" + strings.Join(i.Assignees, " @")
+				}
+				if issueState != "open" {
+					line += " (" + i.State + ")"
+				}
+				fmt.Println(line)
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "repo": owner + "/" + repo, "issues": issues})
+		return nil
+	},
+}
+
+var issueCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Open an issue",
+	Long: `Open an issue with a title, body, labels and assignees. With --ai the title
+and body are drafted from an error message, log or diff read from --from-file or
+piped in; --title overrides the drafted one, and --body is passed to the model
+as your description of the problem and kept at the top of the body.
+Example:
+  ghquick issue create --title "Crash on empty config" --label bug
+  go test ./... 2>&1 | ghquick issue create --ai --label bug --assignee alice
+  ghquick issue create --ai --from-file panic.log`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		cfg, fileCfg, ghClient, owner, repo, err := issueSetup(ctx)
+		if err != nil {
+			return err
+		}
+
+		in := github.IssueInput{
+			Owner:     owner,
+			Repo:      repo,
+			Title:     issueTitle,
+			Body:      issueBody,
+			Labels:    issueLabels,
+			Assignees: issueAssignees,
+		}
+		if issueAI {
+			if err := draftIssue(ctx, cfg, fileCfg, &in); err != nil {
+				return err
+			}
+		}
+		if in.Title == "" {
+			if !isInteractive() {
+				return fmt.Errorf("--title is required (or --ai to draft one)")
+			}
+			if in.Title, err = readLine("Title: "); err != nil {
+				return err
+			}
+			if in.Title == "" {
+				return fmt.Errorf("an issue needs a title")
+			}
+		}
+
+		issue, err := ghClient.CreateIssue(ctx, in)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			logger.Success("🔗 %s", issue.URL)
+		}
+		logger.Result(map[string]interface{}{"ok": true, "number": issue.Number, "url": issue.URL, "title": in.Title})
+		return nil
+	},
+}
+
+// draftIssue fills in the title and body of in from the error, log or diff
+// given with --from-file or on stdin, keeping an explicit --title or --body
+func draftIssue(ctx context.Context, cfg *config.Config, fileCfg *config.FileConfig, in *github.IssueInput) error {
+	var (
+		input []byte
+		err   error
+	)
+	switch issueFromFile {
+	case "", "-":
+		if issueFromFile == "" {
+			if info, statErr := os.Stdin.Stat(); statErr == nil && info.Mode()&os.ModeCharDevice != 0 {
+				return fmt.Errorf("--ai needs an error, log or diff; pipe it in or pass --from-file")
+			}
+		}
+		input, err = io.ReadAll(os.Stdin)
+	default:
+		input, err = os.ReadFile(issueFromFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read input for --ai: %w", err)
+	}
+	if strings.TrimSpace(string(input)) == "" {
+		return fmt.Errorf("--ai input is empty")
+	}
+
+	provider, err := ai.NewProvider(aiProviderConfig(cfg, fileCfg))
+	if err != nil {
+		return err
+	}
+	logger.Step("Drafting issue...")
+	title, body, err := ai.NewCommitMessageGenerator(provider).GenerateIssue(ctx, string(input), issueBody)
+	if err != nil {
+		return err
+	}
+	in.Title = strutil.FirstNonEmpty(issueTitle, title)
+	in.Body = body
+	if issueBody != "" {
+		in.Body = issueBody + "\n\n" + body
+	}
+	return nil
+}
+
+var issueCloseCmd = &cobra.Command{
+	Use:   "close <number>",
+	Short: "Close an issue, optionally with a comment",
+	Long: `Close an issue as completed, or with --not-planned as not planned.
+Example:
+  ghquick issue close 42 --comment "Fixed in #57"
+  ghquick issue close 43 --not-planned`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		number, err := parseIssueNumber(args[0])
+		if err != nil {
+			return err
+		}
+		_, _, ghClient, owner, repo, err := issueSetup(ctx)
+		if err != nil {
+			return err
+		}
+		reason := "completed"
+		if issueNotPlanned {
+			reason = "not_planned"
+		}
+		if err := ghClient.CloseIssue(ctx, owner, repo, number, issueComment, reason); err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "number": number, "reason": reason})
+		return nil
+	},
+}
+
+var issueCommentCmd = &cobra.Command{
+	Use:   "comment <number>",
+	Short: "Comment on an issue",
+	Long: `Add a comment to an issue (or pull request).
+Example:
+  ghquick issue comment 42 --body "Can reproduce on main"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		number, err := parseIssueNumber(args[0])
+		if err != nil {
+			return err
+		}
+		_, _, ghClient, owner, repo, err := issueSetup(ctx)
+		if err != nil {
+			return err
+		}
+		if err := ghClient.CommentOnIssue(ctx, owner, repo, number, issueBody); err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "number": number})
+		return nil
+	},
+}
+
+// issueSetup loads the config and resolves the repository issue commands act
+// on: --target, else the upstream remote, else origin
+func issueSetup(ctx context.Context) (*config.Config, *config.FileConfig, *github.Client, string, string, error) {
+	cfg, err := config.Load(tokenResolver())
+	if err != nil {
+		return nil, nil, nil, "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return nil, nil, nil, "", "", err
+	}
+	fileCfg, err := loadFileConfig(wd)
+	if err != nil {
+		return nil, nil, nil, "", "", fmt.Errorf("failed to load config file: %w", err)
+	}
+	ghClient := newGitHubClient(cfg.GitHubToken)
+	ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+	var owner, repo string
+	switch gitOps := newGitOps(wd); {
+	case issueTarget != "":
+		var ok bool
+		if owner, repo, ok = strings.Cut(issueTarget, "/"); !ok || owner == "" || repo == "" {
+			return nil, nil, nil, "", "", fmt.Errorf("invalid --target %q (expected owner/name)", issueTarget)
+		}
+	case gitOps.HasRemote(ctx, "upstream"):
+		owner, repo, err = gitOps.RemoteRepo(ctx, "upstream")
+	default:
+		owner, repo, err = gitOps.RemoteRepo(ctx, "origin")
+	}
+	if err != nil {
+		return nil, nil, nil, "", "", err
+	}
+	return cfg, fileCfg, ghClient, owner, repo, nil
+}
+
+// parseIssueNumber accepts "42" or "#42"
+func parseIssueNumber(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid issue number %q", s)
+	}
+	return n, nil
+}
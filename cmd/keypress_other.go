@@ -0,0 +1,13 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"errors"
+	"time"
+)
+
+// waitForKey is only implemented for Unix terminals
+func waitForKey(fd int, d time.Duration) (bool, error) {
+	return false, errors.New("waiting for a keypress isn't supported on this platform; drop --confirm-window")
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitForKey waits up to d for input on the terminal fd, returning early with
+// true once there is some. Nothing is read, so a keypress that comes later is
+// left for whoever reads next.
+func waitForKey(fd int, d time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, int(d.Milliseconds()))
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+}
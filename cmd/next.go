@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/strutil"
+)
+
+// outcome describes how a command finished, for suggesting what to do next
+type outcome struct {
+	Committed bool
+	Pushed    bool
+	// Rejected is set when the remote refused the push (e.g. non-fast-forward)
+	Rejected bool
+	Branch   string
+	// DefaultBranch is the remote's default branch; pushes elsewhere are feature branches
+	DefaultBranch string
+}
+
+// suggestNext returns a hint for the natural next command, or "" when there is none
+func suggestNext(state outcome) string {
+	switch {
+	case state.Rejected:
+		return "the remote has commits you don't; run 'ghquick sync' and then 'ghquick push'"
+	case state.Committed && !state.Pushed:
+		return "your commit is local only; run 'ghquick push' when you're ready"
+	case state.Pushed && state.Branch != "" && state.Branch != state.DefaultBranch:
+		return "open a pull request for " + state.Branch + " with 'ghquick pr'"
+	}
+	return ""
+}
+
+// printNextStep logs the suggested next step unless hints are turned off
+func printNextStep(ctx context.Context, gitOps *git.Operations, fileCfg *config.FileConfig, state outcome) {
+	if noHints || fileCfg.NoHints {
+		return
+	}
+	if state.Pushed && state.DefaultBranch == "" {
+		defaultBranch, _ := gitOps.DefaultBranch(ctx, "origin")
+		state.DefaultBranch = strutil.FirstNonEmpty(fileCfg.DefaultBranch, defaultBranch, "main")
+	}
+	if hint := suggestNext(state); hint != "" {
+		logger.Info("Next: %s", hint)
+	}
+}
+
+// isRejectedPush reports whether a push error means the remote refused the update
+func isRejectedPush(err error) bool {
+	var conflict *git.RebaseConflictError
+	if errors.Is(err, git.ErrNonFastForward) || errors.As(err, &conflict) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "[rejected]") || strings.Contains(msg, "non-fast-forward") || strings.Contains(msg, "fetch first")
+}
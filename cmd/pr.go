@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/ai"
+	"github.com/saint/ghquick/internal/commit"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prBase     string
+	prBaseRepo string
+	prTitle    string
+	prBody     string
+	prDraft    bool
+	prReady    bool
+	prAI       bool
+	prCreateAI bool
+	prReviewer []string
+	prLabels   []string
+)
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	prCmd.AddCommand(prCreateCmd)
+
+	// pr and pr create share their flags; only the --ai default differs
+	addPRFlags(prCmd, &prAI, false)
+	addPRFlags(prCreateCmd, &prCreateAI, true)
+}
+
+func addPRFlags(cmd *cobra.Command, useAI *bool, aiDefault bool) {
+	cmd.Flags().StringVar(&prBase, "base", "", "Base branch (defaults to the remote's default branch)")
+	cmd.Flags().StringVar(&prBaseRepo, "base-repo", "", "Repository to open the PR against as owner/name (defaults to upstream, then origin)")
+	cmd.Flags().StringVar(&prTitle, "title", "", "PR title (defaults to the last commit subject)")
+	cmd.Flags().StringVar(&prBody, "body", "", "PR body (defaults to the last commit body plus the PR template)")
+	cmd.Flags().BoolVar(&prDraft, "draft", false, "Open the PR as a draft (or convert the open PR to one)")
+	cmd.Flags().BoolVar(&prReady, "ready", false, "Open the PR ready for review (or mark the open draft PR ready)")
+	cmd.MarkFlagsMutuallyExclusive("draft", "ready")
+	cmd.Flags().BoolVar(useAI, "ai", aiDefault, "Generate the title and body from the branch's diff against the base")
+	cmd.Flags().StringSliceVar(&prReviewer, "reviewer", nil, "Request review from these users or org/team teams (comma-separated or repeated)")
+	cmd.Flags().StringSliceVar(&prLabels, "label", nil, "Add these labels to the PR (comma-separated or repeated)")
+}
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Push the current branch and open a pull request",
+	Long: `Push the current branch to origin and open a pull request for it.
+Example:
+  ghquick pr --base main --draft
+  ghquick pr --ready                   # Mark this branch's draft PR ready for review
+  ghquick pr --dry-run                 # Show the branch, base, title, body and API call
+
+Set pr_draft_default: true in .ghquick.yaml to open drafts unless --ready is given.`,
+	Args: cobra.NoArgs,
+	RunE: runPullRequest,
+}
+
+var prCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Push the current branch and open a pull request with an AI-written title and body",
+	Long: `Push the current branch to origin and open a pull request for it, with the
+title and body generated from the branch's diff against the base. --title and
+--body override the generated ones, and --ai=false uses the last commit instead.
+Example:
+  ghquick pr create --base develop --draft
+  ghquick pr create --reviewer alice,my-org/backend --label enhancement`,
+	Args: cobra.NoArgs,
+	RunE: runPullRequest,
+}
+
+// runPullRequest pushes the current branch and opens (or updates) its pull request
+func runPullRequest(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(timeout)
+	defer cancel()
+
+	useAI, _ := cmd.Flags().GetBool("ai")
+	tokens := tokenResolver()
+	cfg, err := config.LoadGitHub(tokens)
+	if useAI && err == nil {
+		cfg, err = config.Load(tokens)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return err
+	}
+
+	fileCfg, err := loadFileConfig(wd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	gitOps := newGitOps(wd)
+	gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+	ghClient := newGitHubClient(cfg.GitHubToken)
+	ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+	in, err := buildPullRequest(ctx, gitOps, ghClient, wd, strutil.FirstNonEmpty(prBase, fileCfg.DefaultBranch))
+	if err != nil {
+		return err
+	}
+	in.Draft = (fileCfg.PRDraftDefault || prDraft) && !prReady
+	branch := in.Head[strings.Index(in.Head, ":")+1:]
+	if in.Base == branch && in.Head == branch {
+		return fmt.Errorf("current branch %s is the base branch; create a feature branch first", branch)
+	}
+	if useAI && (prTitle == "" || prBody == "") {
+		if err := generatePullRequestText(ctx, gitOps, cfg, fileCfg, &in); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		logger.Info("[dry-run] Branch: %s (pushed to origin)", branch)
+		logger.Info("[dry-run] Target: %s/%s, base %s, head %s", in.Owner, in.Repo, in.Base, in.Head)
+		logger.Info("[dry-run] Title: %s", in.Title)
+		logger.Info("[dry-run] Body:\n%s", in.Body)
+	}
+
+	if err := gitOps.Push(ctx, "origin", branch); err != nil {
+		return fmt.Errorf("failed to push branch: %w", err)
+	}
+
+	pr, err := openPullRequest(ctx, ghClient, in, prDraft || prReady)
+	if err != nil {
+		return err
+	}
+	if len(prReviewer) > 0 {
+		if err := ghClient.RequestReviewers(ctx, in.Owner, in.Repo, pr, prReviewer); err != nil {
+			return err
+		}
+	}
+	if len(prLabels) > 0 {
+		if err := ghClient.AddLabels(ctx, in.Owner, in.Repo, pr, prLabels); err != nil {
+			return err
+		}
+	}
+	if !dryRun {
+		logger.Success("🔗 %s", pr.URL)
+	}
+	return nil
+}
+
+// generatePullRequestText replaces the commit-based title and body in in with
+// ones generated from the branch's diff, keeping an explicit --title or --body
+func generatePullRequestText(ctx context.Context, gitOps *git.Operations, cfg *config.Config, fileCfg *config.FileConfig, in *github.PullRequestInput) error {
+	remote := "origin"
+	if gitOps.HasRemote(ctx, "upstream") {
+		remote = "upstream"
+	}
+	diff, err := gitOps.BranchDiff(ctx, remote, in.Base)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		return fmt.Errorf("HEAD has no changes against %s", in.Base)
+	}
+
+	provider, err := ai.NewProvider(aiProviderConfig(cfg, fileCfg))
+	if err != nil {
+		return err
+	}
+	gen := ai.NewCommitMessageGenerator(provider).WithOptions(ai.Options{
+		SplitThreshold: defaultMaxDiffBytes,
+		TokenBudget:    fileCfg.AITokenBudget,
+	})
+	logger.Step("Generating pull request description...")
+	title, body, err := gen.GeneratePullRequest(ctx, diff)
+	if err != nil {
+		return err
+	}
+	in.Title = strutil.FirstNonEmpty(prTitle, title)
+	if prBody == "" {
+		in.Body = body
+	}
+	return nil
+}
+
+// openPullRequest creates the pull request described by in, or returns the one
+// already open for its head branch. With syncDraft the open PR's draft state is
+// changed to match in.Draft.
+func openPullRequest(ctx context.Context, ghClient *github.Client, in github.PullRequestInput, syncDraft bool) (*github.PullRequest, error) {
+	// An open PR for the branch already has everything but maybe its draft state
+	head := in.Head
+	if !strings.Contains(head, ":") {
+		head = in.Owner + ":" + head
+	}
+	pr, err := ghClient.FindOpenPullRequest(ctx, in.Owner, in.Repo, head)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return ghClient.CreatePullRequest(ctx, in)
+	}
+
+	logger.Info("Pull request #%d is already open for %s", pr.Number, in.Head)
+	if syncDraft && pr.Draft != in.Draft {
+		if err := ghClient.SetPullRequestDraft(ctx, pr, in.Draft); err != nil {
+			return nil, err
+		}
+	}
+	return pr, nil
+}
+
+// buildPullRequest computes the target repository, head, base, title and body for the PR.
+// An empty base means the target repository's default branch.
+func buildPullRequest(ctx context.Context, gitOps *git.Operations, ghClient *github.Client, wd, base string) (github.PullRequestInput, error) {
+	var in github.PullRequestInput
+
+	branch, err := gitOps.CurrentBranch(ctx)
+	if err != nil {
+		return in, err
+	}
+
+	headOwner, _, err := gitOps.RemoteRepo(ctx, "origin")
+	if err != nil {
+		return in, err
+	}
+
+	// The remote the target repository is cloned as, for offline default-branch lookups
+	baseRemote := "origin"
+	switch {
+	case prBaseRepo != "":
+		baseRemote = ""
+		owner, name, ok := strings.Cut(prBaseRepo, "/")
+		if !ok || owner == "" || name == "" {
+			return in, fmt.Errorf("invalid --base-repo %q (expected owner/name)", prBaseRepo)
+		}
+		in.Owner, in.Repo = owner, name
+	case gitOps.HasRemote(ctx, "upstream"):
+		baseRemote = "upstream"
+		if in.Owner, in.Repo, err = gitOps.RemoteRepo(ctx, "upstream"); err != nil {
+			return in, err
+		}
+	default:
+		if in.Owner, in.Repo, err = gitOps.RemoteRepo(ctx, "origin"); err != nil {
+			return in, err
+		}
+	}
+
+	// Cross-repository PRs name the head as owner:branch
+	in.Head = branch
+	if !strings.EqualFold(headOwner, in.Owner) {
+		in.Head = headOwner + ":" + branch
+	}
+
+	in.Base = base
+	if in.Base == "" {
+		in.Base = resolveBaseBranch(ctx, gitOps, ghClient, baseRemote, in.Owner, in.Repo)
+	}
+
+	subject, body, err := gitOps.LastCommitMessage(ctx)
+	if err != nil {
+		return in, err
+	}
+	in.Title = strutil.FirstNonEmpty(prTitle, subject)
+	in.Body = prBody
+	if in.Body == "" {
+		// A structured commit body already has the PR template's sections
+		if commit.HasSectionHeadings(body) {
+			in.Body = commit.ParseCommitMessage(subject + "\n\n" + body).RenderPRBody()
+		} else {
+			template, err := github.FindPRTemplate(wd)
+			if err != nil {
+				return in, err
+			}
+			in.Body = strings.TrimSpace(body + "\n\n" + template)
+		}
+	}
+	return in, nil
+}
+
+// resolveBaseBranch finds the repository's default branch from what git knows
+// about remote (origin/HEAD, or asking the remote), then the API. As a last
+// resort it picks a local main or master, in that order.
+func resolveBaseBranch(ctx context.Context, gitOps *git.Operations, ghClient *github.Client, remote, owner, repo string) string {
+	if remote != "" {
+		branch, err := gitOps.DefaultBranch(ctx, remote)
+		if err == nil {
+			return branch
+		}
+		logger.Debug("Could not get default branch from %s: %v", remote, err)
+	}
+
+	branch, err := ghClient.GetDefaultBranch(ctx, owner, repo)
+	if err == nil {
+		return branch
+	}
+	logger.Debug("Could not get default branch from the API: %v", err)
+
+	branch = "main"
+	if !gitOps.BranchExists(ctx, "main") && gitOps.BranchExists(ctx, "master") {
+		branch = "master"
+	}
+	logger.Warning("Could not detect the default branch; assuming %s", branch)
+	return branch
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prListState      string
+	prListBase       string
+	prListLimit      int
+	prMergeMethod    string
+	prMergeTitle     string
+	prMergeBody      string
+	prDeleteBranch   bool
+	prCheckoutBranch string
+	prCheckoutDirty  string
+)
+
+func init() {
+	prCmd.AddCommand(prListCmd, prMergeCmd, prCheckoutCmd)
+	for _, c := range []*cobra.Command{prListCmd, prMergeCmd, prCheckoutCmd} {
+		c.Flags().StringVar(&prBaseRepo, "base-repo", "", "Repository the pull requests are in as owner/name (defaults to upstream, then origin)")
+	}
+
+	prListCmd.Flags().StringVar(&prListState, "state", "open", "Which pull requests to list: open, closed, or all")
+	_ = prListCmd.RegisterFlagCompletionFunc("state", completeValues("open", "closed", "all"))
+	prListCmd.Flags().StringVar(&prListBase, "base", "", "Only pull requests into this branch")
+	prListCmd.Flags().IntVar(&prListLimit, "limit", 30, "Maximum number of pull requests to list")
+
+	prMergeCmd.Flags().StringVar(&prMergeMethod, "method", "", "How to merge: merge, squash, or rebase (default pr_merge_method config, or merge)")
+	_ = prMergeCmd.RegisterFlagCompletionFunc("method", completeValues("merge", "squash", "rebase"))
+	prMergeCmd.Flags().StringVar(&prMergeTitle, "title", "", "Title of the merge or squash commit (defaults to GitHub's)")
+	prMergeCmd.Flags().StringVar(&prMergeBody, "body", "", "Message of the merge or squash commit (defaults to GitHub's)")
+	prMergeCmd.Flags().BoolVar(&prDeleteBranch, "delete-branch", false, "Delete the head branch on GitHub and locally after merging (default pr_delete_branch config)")
+
+	prCheckoutCmd.Flags().StringVar(&prCheckoutBranch, "branch", "", "Local branch to check the pull request out as (defaults to its head branch)")
+	prCheckoutCmd.Flags().StringVar(&prCheckoutDirty, "dirty", "", "What to do with local changes: stash (default), abort, or carry")
+	_ = prCheckoutCmd.RegisterFlagCompletionFunc("dirty", completeValues(dirtyPolicies...))
+}
+
+var prListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the repository's pull requests",
+	Long: `List pull requests, newest first.
+Example:
+  ghquick pr list
+  ghquick pr list --base develop
+  ghquick pr list --state closed --limit 10`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		switch prListState {
+		case "open", "closed", "all":
+		default:
+			return fmt.Errorf("invalid --state %q (expected open, closed, or all)", prListState)
+		}
+		t, err := prRepoSetup(ctx)
+		if err != nil {
+			return err
+		}
+		prs, err := t.ghClient.ListPullRequests(ctx, t.owner, t.repo, github.PullRequestFilter{
+			State: prListState,
+			Base:  prListBase,
+			Limit: prListLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !log.JSON() {
+			if len(prs) == 0 {
+				logger.Info("No %s pull requests in %s/%s", prListState, t.owner, t.repo)
+			}
+			for _, pr := range prs {
+				line := fmt.Sprintf("#%-5d %s (%s → %s) @%s", pr.Number, pr.Title, pr.Head, pr.Base, pr.Author)
+				if pr.Draft {
+					line += " [draft]"
+				}
+				if prListState != "open" {
+					line += " (" + pr.State + ")"
+				}
+				fmt.Println(line)
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "repo": t.owner + "/" + t.repo, "pull_requests": prs})
+		return nil
+	},
+}
+
+var prMergeCmd = &cobra.Command{
+	Use:   "merge [number]",
+	Short: "Merge a pull request (the current branch's by default)",
+	Long: `Merge a pull request on GitHub with a merge commit, squashed, or rebased.
+Only the head commit seen when the pull request is read is merged, so commits
+pushed in the meantime never go in unseen. --delete-branch then deletes the head
+branch on GitHub and the local branch, switching to the base first if it is
+checked out; a local branch with commits that aren't in the pull request is kept.
+Example:
+  ghquick pr merge                             # This branch's pull request
+  ghquick pr merge 42 --method squash --delete-branch
+  ghquick pr merge 42 --method rebase --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		t, err := prRepoSetup(ctx)
+		if err != nil {
+			return err
+		}
+		method, err := github.ParseMergeMethod(strutil.FirstNonEmpty(prMergeMethod, t.fileCfg.PRMergeMethod))
+		if err != nil {
+			return err
+		}
+		deleteBranch := t.fileCfg.PRDeleteBranch
+		if cmd.Flags().Changed("delete-branch") {
+			deleteBranch = prDeleteBranch
+		}
+
+		pr, err := prFromArgs(ctx, t, args)
+		if err != nil {
+			return err
+		}
+		switch {
+		case pr.State != "open":
+			return fmt.Errorf("pull request #%d is %s", pr.Number, pr.State)
+		case pr.Draft:
+			return fmt.Errorf("pull request #%d is a draft; mark it ready with 'ghquick pr --ready' first", pr.Number)
+		}
+		remote, track := prCheckoutRemote(ctx, t, pr)
+		local := t.gitOps.PullRequestBranch(ctx, pr.Number, remote, pr.Head, track)
+		if local != "" {
+			if tip, err := t.gitOps.ResolveCommit(ctx, local); err == nil && tip != pr.HeadSHA {
+				logger.Warning("Local %s is at %s but #%d is at %s; only what was pushed is merged", local, shortSHA(tip), pr.Number, shortSHA(pr.HeadSHA))
+			}
+		}
+
+		if err := t.ghClient.MergePullRequest(ctx, t.owner, t.repo, pr, github.MergeInput{
+			Method:  method,
+			Title:   prMergeTitle,
+			Message: prMergeBody,
+		}); err != nil {
+			return err
+		}
+		result := map[string]interface{}{"ok": true, "number": pr.Number, "method": method, "url": pr.URL}
+		if deleteBranch {
+			result["deleted"] = deleteMergedBranch(ctx, t, pr, local)
+		}
+		if !dryRun {
+			logger.Success("🔗 %s", pr.URL)
+		}
+		logger.Result(result)
+		return nil
+	},
+}
+
+var prCheckoutCmd = &cobra.Command{
+	Use:   "checkout <number>",
+	Short: "Fetch a pull request's branch and switch to it",
+	Long: `Fetch a pull request and switch to it as a local branch, to review, test or
+build on it. A pull request from a branch of one of this clone's remotes is
+checked out tracking that branch, so commits can be pushed back to it; one from
+another fork is fetched from the pull request itself, and 'git pull' keeps it
+up to date. Checking out the same pull request again fast-forwards the branch.
+Example:
+  ghquick pr checkout 42
+  ghquick pr checkout 42 --branch review/login`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		t, err := prRepoSetup(ctx)
+		if err != nil {
+			return err
+		}
+		policy, err := git.ParseDirtyPolicy(strutil.FirstNonEmpty(prCheckoutDirty, t.fileCfg.CheckoutDirtyPolicy))
+		if err != nil {
+			return err
+		}
+		pr, err := prFromArgs(ctx, t, args)
+		if err != nil {
+			return err
+		}
+
+		remote, track := prCheckoutRemote(ctx, t, pr)
+		if remote == "" {
+			return fmt.Errorf("%s/%s is not a remote of this repository; add it with 'git remote add upstream <url>'", t.owner, t.repo)
+		}
+
+		branch, err := t.gitOps.CheckoutPullRequest(ctx, remote, pr.Number, prCheckoutBranch, pr.Head, track, policy)
+		if err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "number": pr.Number, "branch": branch, "pull_request": pr})
+		return nil
+	},
+}
+
+// prTarget is what the pr subcommands working on existing pull requests share
+type prTarget struct {
+	gitOps   *git.Operations
+	fileCfg  *config.FileConfig
+	ghClient *github.Client
+	// owner/repo holds the pull requests: --base-repo, else upstream, else origin
+	owner, repo string
+	// remote is the remote owner/repo is cloned as, or "" when it isn't one
+	remote string
+}
+
+// prRepoSetup resolves the repository the pull requests are in
+func prRepoSetup(ctx context.Context) (*prTarget, error) {
+	cfg, err := config.LoadGitHub(tokenResolver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	fileCfg, err := loadFileConfig(wd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	t := &prTarget{gitOps: newGitOps(wd), fileCfg: fileCfg, ghClient: newGitHubClient(cfg.GitHubToken)}
+	t.gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+	t.ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+	switch {
+	case prBaseRepo != "":
+		var ok bool
+		if t.owner, t.repo, ok = strings.Cut(prBaseRepo, "/"); !ok || t.owner == "" || t.repo == "" {
+			return nil, fmt.Errorf("invalid --base-repo %q (expected owner/name)", prBaseRepo)
+		}
+		for _, r := range []string{"upstream", "origin"} {
+			if owner, repo, err := t.gitOps.RemoteRepo(ctx, r); err == nil && strings.EqualFold(owner+"/"+repo, prBaseRepo) {
+				t.remote = r
+				break
+			}
+		}
+	case t.gitOps.HasRemote(ctx, "upstream"):
+		t.remote = "upstream"
+		t.owner, t.repo, err = t.gitOps.RemoteRepo(ctx, "upstream")
+	default:
+		t.remote = "origin"
+		t.owner, t.repo, err = t.gitOps.RemoteRepo(ctx, "origin")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// prFromArgs reads the pull request numbered in args, or without one the open
+// pull request for the current branch
+func prFromArgs(ctx context.Context, t *prTarget, args []string) (*github.PullRequest, error) {
+	if len(args) > 0 {
+		n, err := parseIssueNumber(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pull request number %q", args[0])
+		}
+		return t.ghClient.GetPullRequest(ctx, t.owner, t.repo, n)
+	}
+	branch, err := t.gitOps.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headOwner, _, err := t.gitOps.RemoteRepo(ctx, "origin")
+	if err != nil {
+		return nil, err
+	}
+	found, err := t.ghClient.FindOpenPullRequest(ctx, t.owner, t.repo, headOwner+":"+branch)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no open pull request for %s in %s/%s; name one by number", branch, t.owner, t.repo)
+	}
+	return found, nil
+}
+
+// deleteMergedBranch deletes a merged pull request's head branch on GitHub and
+// the local branch following it, and reports where it was deleted. Failures
+// only warn: the merge is done.
+func deleteMergedBranch(ctx context.Context, t *prTarget, pr *github.PullRequest, local string) []string {
+	var deleted []string
+	if owner, repo, ok := strings.Cut(pr.HeadRepo, "/"); ok {
+		if err := t.ghClient.DeleteBranch(ctx, owner, repo, pr.Head); err != nil {
+			logger.Warning("Could not delete %s on GitHub: %v", pr.Head, err)
+		} else {
+			deleted = append(deleted, pr.HeadRepo)
+		}
+	}
+
+	if local == "" {
+		return deleted
+	}
+	// Squash and rebase merges leave the branch looking unmerged to git, so
+	// it's only deleted when it has nothing the pull request doesn't
+	if tip, err := t.gitOps.ResolveCommit(ctx, local); err != nil || tip != pr.HeadSHA {
+		logger.Warning("Kept local branch %s: it has commits that aren't in #%d", local, pr.Number)
+		return deleted
+	}
+	if current, _ := t.gitOps.CurrentBranch(ctx); current == local {
+		if err := t.gitOps.Checkout(ctx, pr.Base, git.DirtyStash); err != nil {
+			logger.Warning("Kept local branch %s: could not switch to %s: %v", local, pr.Base, err)
+			return deleted
+		}
+		logger.Info("Run 'ghquick sync' to bring %s up to date with the merge", pr.Base)
+	}
+	if err := t.gitOps.DeleteBranch(ctx, local, "", true); err != nil {
+		logger.Warning("Could not delete local branch %s: %v", local, err)
+		return deleted
+	}
+	return append(deleted, "local")
+}
+
+// prCheckoutRemote picks the remote a pull request is fetched from: the one
+// its head branch is on, so the local branch can track it (track), else the
+// remote of the repository it was opened against; "" when neither is a remote
+func prCheckoutRemote(ctx context.Context, t *prTarget, pr *github.PullRequest) (string, bool) {
+	for _, r := range []string{"origin", "upstream"} {
+		if !t.gitOps.HasRemote(ctx, r) {
+			continue
+		}
+		if owner, repo, err := t.gitOps.RemoteRepo(ctx, r); err == nil && strings.EqualFold(owner+"/"+repo, pr.HeadRepo) {
+			return r, true
+		}
+	}
+	return t.remote, false
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"golang.org/x/term"
+)
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// isInteractive reports whether both stdin and stdout are attached to a terminal.
+// JSON output is for scripts, so it never prompts.
+func isInteractive() bool {
+	if log.JSON() {
+		return false
+	}
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// readLine prints a prompt and returns the trimmed line typed by the user
+func readLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readSecret is readLine for passwords and tokens: on a terminal what is typed
+// isn't echoed
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return readLine(prompt)
+	}
+	fmt.Print(prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// promptValue asks for a value until one is given; pressing Enter picks def
+// when there is one
+func promptValue(label, def string) (string, error) {
+	prompt := label + ": "
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", label, def)
+	}
+	for {
+		value, err := readLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		if value = strutil.FirstNonEmpty(value, def); value != "" {
+			return value, nil
+		}
+	}
+}
+
+// promptChoice lists options and returns the one picked by number or name;
+// pressing Enter picks def when there is one
+func promptChoice(label string, options []string, def string) (string, error) {
+	fmt.Println(label)
+	for i, o := range options {
+		fmt.Printf("  %d) %s\n", i+1, o)
+	}
+	for {
+		choice, err := promptValue("Choose", def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		for _, o := range options {
+			if strings.EqualFold(o, choice) {
+				return o, nil
+			}
+		}
+		logger.Warning("Unknown choice: %s", choice)
+	}
+}
+
+// promptBranch asks which local branch to switch to, for commands whose
+// branch argument was left out; without a terminal it fails with missing
+func promptBranch(ctx context.Context, gitOps *git.Operations, missing string) (string, error) {
+	if !isInteractive() {
+		return "", errors.New(missing)
+	}
+	branches, err := gitOps.ListBranches(ctx)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, b := range branches {
+		if !b.Current {
+			names = append(names, b.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("there is no other branch to switch to")
+	}
+	return promptChoice("Switch to which branch?", names, "")
+}
+
+// refineCommitMessage shows the proposed message and lets the user accept, edit,
+// or regenerate it until they are happy with it. regenerate must produce the
+// new message the same way the first one was, options and checks included.
+func refineCommitMessage(ctx context.Context, message string, regenerate func(ctx context.Context) (string, error)) (string, error) {
+	for {
+		fmt.Printf("\nProposed commit message:\n\n%s\n\n", message)
+		choice, err := readLine("[a]ccept / [e]dit / [r]egenerate / [q]uit: ")
+		if err != nil {
+			return "", err
+		}
+
+		switch strings.ToLower(choice) {
+		case "", "a", "accept":
+			return message, nil
+		case "e", "edit":
+			edited, err := editMessage(message)
+			if err != nil {
+				logger.Warning("Failed to edit message: %v", err)
+				continue
+			}
+			if edited != "" {
+				message = edited
+			}
+		case "r", "regenerate":
+			regenerated, err := regenerate(ctx)
+			if err != nil {
+				return "", err
+			}
+			message = regenerated
+		case "q", "quit":
+			return "", fmt.Errorf("aborted by user")
+		default:
+			logger.Warning("Unknown choice: %s", choice)
+		}
+	}
+}
+
+// editMessage opens the message in $EDITOR, or asks for a replacement line when
+// no editor is set. A blank variable counts as unset.
+func editMessage(message string) (string, error) {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		return readLine("New commit message: ")
+	}
+
+	f, err := os.CreateTemp("", "ghquick-commit-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(message + "\n"); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	f.Close()
+
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor exited with error: %w", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited message: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// graceWindow shows a countdown and reports whether the user pressed a key to cancel.
+// The terminal is put into raw mode so a single keypress is enough, and the
+// key is read through stdinReader so nothing typed is lost to a later prompt.
+func graceWindow(ctx context.Context, window time.Duration, action string) (bool, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return false, fmt.Errorf("failed to read keypresses: %w", err)
+	}
+	defer term.Restore(fd, state)
+	defer fmt.Print("\r\033[K")
+
+	deadline := time.Now().Add(window)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		fmt.Printf("\r\033[K⏳ %s in %.0fs — press any key to cancel", action, remaining.Seconds()+0.49)
+
+		// Typed ahead, or ready on the terminal within the next tick
+		pressed := stdinReader.Buffered() > 0
+		if !pressed {
+			if pressed, err = waitForKey(fd, min(remaining, 100*time.Millisecond)); err != nil {
+				return false, fmt.Errorf("failed to read keypresses: %w", err)
+			}
+		}
+		if pressed {
+			// Raw mode hands over bytes as they are typed, so this doesn't block
+			if _, err := stdinReader.ReadByte(); err != nil {
+				return false, fmt.Errorf("failed to read keypresses: %w", err)
+			}
+			return true, nil
+		}
+	}
+}
+
+// selectFiles lists the changed files and asks which to stage, returning their
+// paths (both sides of a rename). Answers are numbers and ranges such as
+// "1 3-5", or "a" for everything.
+func selectFiles(entries []git.FileStatus) ([]string, error) {
+	if len(entries) == 0 {
+		return nil, git.ErrNoChanges
+	}
+	fmt.Println("\nChanged files:")
+	for i, e := range entries {
+		name := e.Path
+		if e.OrigPath != "" {
+			name = e.OrigPath + " -> " + e.Path
+		}
+		fmt.Printf("  %2d) %c%c %s\n", i+1, e.Index, e.Worktree, name)
+	}
+
+	for {
+		answer, err := readLine("\nFiles to stage (e.g. 1 3-5, a for all, empty to cancel): ")
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			return nil, fmt.Errorf("staging cancelled")
+		}
+		picked, err := parseSelection(answer, len(entries))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		var paths []string
+		for _, i := range picked {
+			if e := entries[i]; e.OrigPath != "" {
+				paths = append(paths, e.OrigPath, e.Path)
+			} else {
+				paths = append(paths, e.Path)
+			}
+		}
+		return paths, nil
+	}
+}
+
+// parseSelection turns an answer such as "1 3-5" or "a" into zero-based
+// indexes below n, in order and without duplicates
+func parseSelection(answer string, n int) ([]int, error) {
+	if a := strings.ToLower(strings.TrimSpace(answer)); a == "a" || a == "all" {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+
+	seen := make(map[int]bool)
+	var picked []int
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' }) {
+		from, to, isRange := strings.Cut(field, "-")
+		first, err := strconv.Atoi(from)
+		last := first
+		if err == nil && isRange {
+			last, err = strconv.Atoi(to)
+		}
+		if err != nil || first < 1 || last > n || first > last {
+			return nil, fmt.Errorf("%q is not a file number or range between 1 and %d", field, n)
+		}
+		for i := first - 1; i < last; i++ {
+			if !seen[i] {
+				seen[i] = true
+				picked = append(picked, i)
+			}
+		}
+	}
+	return picked, nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune [remote...]",
+	Short: "Remove remote-tracking branches that were deleted on the remote",
+	Long: `Fetch the given remotes (origin by default) with --prune so branches
+deleted on the remote no longer linger locally. Set auto_prune: true in
+.ghquick.yaml to prune on every fetch ghquick makes.
+Example:
+  ghquick prune
+  ghquick prune origin upstream`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			args = []string{"origin"}
+		}
+		gitOps := newGitOps(wd)
+		for _, remote := range args {
+			if err := gitOps.Prune(ctx, remote); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
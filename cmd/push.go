@@ -2,17 +2,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/saint/ghquick/internal/ai"
 	"github.com/saint/ghquick/internal/cache"
+	"github.com/saint/ghquick/internal/checks"
+	"github.com/saint/ghquick/internal/commit"
 	"github.com/saint/ghquick/internal/config"
 	"github.com/saint/ghquick/internal/git"
 	"github.com/saint/ghquick/internal/github"
 	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/scan"
+	"github.com/saint/ghquick/internal/strutil"
 	"github.com/spf13/cobra"
 )
 
@@ -21,10 +28,88 @@ var (
 	commitMsg  string
 	autoCommit bool
 	repoCache  *cache.RepoCache
-	debug      bool
-	logger     *log.Logger
 	private    bool
 	timeout    time.Duration = 120 * time.Second
+	autoScope  bool
+	multiScope bool
+	remotes    []string
+	pushBranch string
+	assumeYes  bool
+
+	pushAttempts       int
+	createRepo         bool
+	signCommit         bool
+	amendCommit        bool
+	noCache            bool
+	preCommitChecks    []string
+	openPR             bool
+	autoPR             bool
+	skipPreflight      bool
+	remoteScheme       string
+	aiProvider         string
+	aiModel            string
+	pushPRBase         string
+	pushPRDraft        bool
+	watchPushChecks    bool
+	signingKey         string
+	noGPGSign          bool
+	repoDescription    string
+	syncOnReject       bool
+	detectBreaking     bool
+	breakingInternal   bool
+	noFallbackUnstaged bool
+	splitLargeDiff     bool
+	maxDiffBytes       int
+	runTests           bool
+	testCommand        string
+	force              bool
+	normalizeSubject   bool
+	subjectCase        string
+	allowedExts        []string
+	allowlistStrict    bool
+	commitLanguage     string
+	structuredBody     bool
+	conventional       bool
+	commitScope        string
+	commitType         string
+	maxMessageBytes    int
+	messagePrefix      string
+	messageSuffix      string
+	noTodos            bool
+	allowSecrets       bool
+	eolTarget          string
+	previewRemote      bool
+	asBot              bool
+	botTrailer         bool
+	confirmWindow      time.Duration
+	waitForLock        time.Duration
+	deletionsOnly      bool
+	verifyCommit       bool
+	includeUntracked   []string
+	stagePaths         []string
+	interactiveStage   bool
+	excludePaths       []string
+	maxBodyLines       int
+	maxSummaryWords    int
+	newDirThreshold    int
+	runFormat          bool
+	skipSymlinks       bool
+	detachedSubmodules string
+	warnBlobSize       string
+	forceStage         bool
+	commitMsgValidator string
+	stagedOnly         bool
+	globalGitUser      bool
+	followTags         bool
+)
+
+const (
+	defaultTestCommand   = "go test ./..."
+	defaultFormatCommand = "gofmt -w ."
+	defaultBotName       = "ghquick-bot"
+	defaultBotEmail      = "ghquick-bot@users.noreply.github.com"
+	// defaultNewDirThreshold is how many files a new directory may hold before push asks about it
+	defaultNewDirThreshold = 20
 )
 
 func init() {
@@ -33,9 +118,89 @@ func init() {
 
 	pushCmd.Flags().StringVar(&repoName, "name", "", "Repository name (defaults to current directory name)")
 	pushCmd.Flags().StringVar(&commitMsg, "commitmsg", "", "Commit message")
-	pushCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
 	pushCmd.Flags().BoolVar(&private, "private", false, "Create repository as private")
+	pushCmd.Flags().StringVar(&aiProvider, "ai-provider", "", "AI provider for 'start': openai (default), anthropic, or ollama")
+	pushCmd.Flags().StringVar(&aiModel, "ai-model", "", "Model to generate commit messages with (defaults to the provider's)")
+	pushCmd.Flags().StringVar(&remoteScheme, "remote-scheme", "", "URL scheme for origin: auto (default), https, or ssh")
+	pushCmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Don't check credentials and remote access before staging (e.g. to commit offline)")
+	pushCmd.Flags().BoolVar(&openPR, "pr", false, "After pushing a feature branch, open a pull request for it")
+	pushCmd.Flags().StringVar(&pushPRBase, "pr-base", "", "Base branch for --pr (defaults to the default branch)")
+	pushCmd.Flags().BoolVar(&pushPRDraft, "pr-draft", false, "Open the --pr pull request as a draft")
+	pushCmd.Flags().BoolVar(&watchPushChecks, "watch", false, "After pushing, follow the commit's GitHub Actions checks and fail if any of them fail")
+	pushCmd.Flags().DurationVar(&checksTimeout, "watch-timeout", defaultChecksTimeout, "Give up waiting for the --watch checks after this long")
+	pushCmd.Flags().BoolVar(&autoPR, "auto-pr", false, "When the branch is protected, push to a new branch and open a pull request without asking")
+	pushCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the pre_commit and pre_push hooks, --check, and git's own commit and push hooks")
+	pushCmd.Flags().DurationVar(&hookTimeout, "hook-timeout", 0, "Give up on a hook or --check after this long (default hook_timeout config, or 10m)")
+	pushCmd.Flags().StringArrayVar(&preCommitChecks, "check", nil, "Command that must succeed before committing, e.g. 'go vet ./...', run after the pre_commit hooks (repeatable)")
+	pushCmd.Flags().BoolVar(&noCache, "no-cache", false, "Regenerate the commit message even if one was cached for the same diff")
+	pushCmd.Flags().BoolVar(&amendCommit, "amend", false, "Fold the changes into the last commit (with 'start', regenerate its message from the combined diff)")
+	pushCmd.Flags().BoolVar(&signCommit, "sign", false, "Sign the commit (GPG or SSH, per gpg.format)")
+	pushCmd.Flags().StringVar(&signingKey, "signing-key", "", "Key to sign with (implies --sign; defaults to user.signingkey)")
+	pushCmd.Flags().BoolVar(&noGPGSign, "no-gpg-sign", false, "Don't sign the commit, even if commit.gpgsign is set")
+	pushCmd.MarkFlagsMutuallyExclusive("sign", "no-gpg-sign")
+	pushCmd.MarkFlagsMutuallyExclusive("signing-key", "no-gpg-sign")
+	pushCmd.Flags().BoolVar(&createRepo, "create-repo", false, "Create the GitHub repository if it doesn't exist yet")
+	pushCmd.Flags().StringVar(&repoDescription, "description", "", "Description for a repository created with --create-repo")
 	pushCmd.Flags().DurationVar(&timeout, "timeout", timeout, "Timeout for operations (default 2m)")
+	pushCmd.Flags().BoolVar(&autoScope, "auto-scope", false, "Derive the commit scope from the changed paths (or auto_scope in the config)")
+	pushCmd.Flags().StringVar(&pushBranch, "branch", "", "Branch to push (defaults to the current branch)")
+	pushCmd.Flags().IntVar(&pushAttempts, "push-attempts", 0, "Times to try push and fetch when they fail with a transient network error (default push_attempts or retry_attempts config, or 3)")
+	pushCmd.Flags().BoolVar(&followTags, "follow-tags", false, "Also push annotated tags that point at the pushed commits")
+	pushCmd.Flags().BoolVar(&syncOnReject, "sync", false, "When the push is rejected as non-fast-forward, rebase onto the remote branch and push again")
+	pushCmd.Flags().StringSliceVar(&remotes, "remotes", []string{"origin"}, "Comma-separated remotes to push to (e.g. origin,mirror)")
+	pushCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Skip confirmation prompts and accept the generated commit message")
+	pushCmd.Flags().BoolVar(&detectBreaking, "detect-breaking", false, "Flag removed or changed exported Go symbols as breaking changes (or detect_breaking in the config)")
+	pushCmd.Flags().BoolVar(&breakingInternal, "breaking-internal", false, "Also treat changes to internal/ packages as breaking")
+	pushCmd.Flags().BoolVar(&noFallbackUnstaged, "no-fallback-unstaged", false, "Only diff staged changes; never fall back to the working tree")
+	pushCmd.Flags().BoolVar(&splitLargeDiff, "split-large-diff", true, "Summarize large diffs per file before generating the commit message")
+	pushCmd.Flags().IntVar(&maxDiffBytes, "max-diff-bytes", defaultMaxDiffBytes, "Diff size above which large-diff splitting kicks in")
+	pushCmd.Flags().BoolVar(&runTests, "test", false, "Run the project's tests before committing and record the result")
+	pushCmd.Flags().StringVar(&testCommand, "test-command", "", "Test command to run (default from test_command config, or 'go test ./...')")
+	pushCmd.Flags().BoolVar(&force, "force", false, "Proceed even when pre-commit checks fail, and force-push an amended commit that is already pushed")
+	pushCmd.Flags().BoolVar(&normalizeSubject, "normalize-subject", false, "Rewrite the subject to the imperative mood and apply --subject-case")
+	pushCmd.Flags().StringVar(&subjectCase, "subject-case", "", "Subject capitalization when normalizing: sentence or lower")
+	pushCmd.Flags().StringSliceVar(&allowedExts, "allow-ext", nil, "Only stage files with these extensions (e.g. .go,.md)")
+	pushCmd.Flags().BoolVar(&allowlistStrict, "allow-ext-strict", false, "Fail instead of skipping files outside --allow-ext")
+	pushCmd.Flags().StringVar(&commitLanguage, "language", "", "Language for AI-generated commit messages (default English)")
+	pushCmd.Flags().BoolVar(&conventional, "conventional", false, "Force generated messages into Conventional Commits format, inferring the type from the diff, and reject --commitmsg messages that don't follow it")
+	pushCmd.Flags().StringVar(&commitScope, "scope", "", "Conventional Commit scope for the generated message (implies --conventional)")
+	pushCmd.Flags().StringVar(&commitType, "commit-type", "", "Conventional Commit type, overriding the inferred one (implies --conventional)")
+	pushCmd.Flags().BoolVar(&structuredBody, "structured-body", false, "Generate a commit body with Summary/Changes/Testing sections for PR bodies")
+	pushCmd.Flags().IntVar(&maxMessageBytes, "max-message-bytes", 0, "Truncate the commit body so the message fits in this many bytes (0 = no limit)")
+	pushCmd.Flags().StringVar(&messagePrefix, "message-prefix", "", `Template prepended to the subject, e.g. '[CI #{{env "BUILD_NUMBER"}}]'`)
+	pushCmd.Flags().StringVar(&messageSuffix, "message-suffix", "", "Template appended to the subject")
+	pushCmd.Flags().BoolVar(&noTodos, "no-todos", false, "Fail when staged changes add TODO/FIXME/XXX markers")
+	pushCmd.Flags().BoolVar(&allowSecrets, "allow-secrets", false, "Commit even when staged changes look like they contain API keys, tokens or private keys")
+	pushCmd.Flags().StringVar(&eolTarget, "eol", "", "Normalize line endings of staged text files: lf, crlf, or auto")
+	pushCmd.Flags().BoolVar(&previewRemote, "preview-remote", false, "Show the diff against the remote branch before pushing")
+	pushCmd.Flags().BoolVar(&asBot, "as-bot", false, "Commit as the configured bot identity (bot_name/bot_email)")
+	pushCmd.Flags().BoolVar(&botTrailer, "bot-trailer", false, "With --as-bot, add a Triggered-by trailer naming $GITHUB_ACTOR")
+	pushCmd.Flags().BoolVar(&globalGitUser, "global-git-user", false, "Write user.name/user.email to the global git config instead of the repository's")
+	pushCmd.Flags().BoolVar(&stagedOnly, "staged-only", false, "Commit only what is already staged instead of staging everything")
+	pushCmd.Flags().StringVar(&commitMsgValidator, "commit-msg-validator", "", "Command that checks the commit message on stdin, e.g. \"npx commitlint\"")
+	pushCmd.Flags().BoolVar(&forceStage, "force-stage", false, "Auto-stage even when require_clean_index is set and changes are already staged")
+	pushCmd.Flags().StringVar(&warnBlobSize, "warn-blob-size", "", "Report the bytes the commit adds to history and warn above this size (e.g. 5MB)")
+	pushCmd.Flags().StringVar(&detachedSubmodules, "detached-submodules", "", "What to do when a submodule is detached with unpushed commits: warn, block, or ignore (default warn)")
+	pushCmd.Flags().BoolVar(&skipSymlinks, "skip-symlinks", false, "Leave staged symbolic links out of the commit")
+	pushCmd.Flags().BoolVar(&runFormat, "format", false, fmt.Sprintf("Run format_command (default %q) on staged changes and re-stage the result", defaultFormatCommand))
+	pushCmd.Flags().IntVar(&newDirThreshold, "new-dir-threshold", 0, fmt.Sprintf("Ask before staging a new directory with more than this many files (default %d)", defaultNewDirThreshold))
+	pushCmd.Flags().IntVar(&maxBodyLines, "max-body-lines", 0, "Limit AI-generated commit bodies to this many lines")
+	pushCmd.Flags().IntVar(&maxSummaryWords, "max-summary-words", 0, "Limit the summary of AI-generated commit bodies to this many words")
+	pushCmd.Flags().StringArrayVar(&excludePaths, "exclude", nil, "Never stage paths matching this glob, e.g. .env or node_modules (repeatable)")
+	pushCmd.Flags().StringSliceVar(&stagePaths, "paths", nil, "Stage only these files or directories instead of everything (comma-separated or repeated)")
+	pushCmd.Flags().BoolVarP(&interactiveStage, "interactive", "i", false, "Pick the files to stage from a list of changes")
+	pushCmd.Flags().StringArrayVar(&includeUntracked, "include-untracked", nil, "Stage tracked changes everywhere but untracked files only under this path (repeatable)")
+	pushCmd.Flags().BoolVar(&verifyCommit, "verify-commit", false, "Warn if the new commit's files differ from what was staged (e.g. a hook changed them)")
+	pushCmd.Flags().BoolVar(&deletionsOnly, "deletions-only", false, "Stage and commit only deleted files, with a \"Remove N files\" message")
+	pushCmd.Flags().DurationVar(&waitForLock, "wait-for-lock", 0, "If a git lock file exists, wait up to this long for it to be released instead of removing it")
+	pushCmd.Flags().DurationVar(&confirmWindow, "confirm-window", 0, "Wait this long before pushing, letting a keypress cancel (e.g. 5s)")
+	pushCmd.Flags().BoolVar(&multiScope, "multi-scope", false, "With --auto-scope, list every changed area in the scope instead of omitting it")
+	_ = pushCmd.RegisterFlagCompletionFunc("commit-type", completeValues(commit.ConventionalTypes...))
+	_ = pushCmd.RegisterFlagCompletionFunc("ai-provider", completeValues("openai", "anthropic", "ollama"))
+	_ = pushCmd.RegisterFlagCompletionFunc("remote-scheme", completeValues("auto", "https", "ssh"))
+	_ = pushCmd.RegisterFlagCompletionFunc("subject-case", completeValues("sentence", "lower"))
+	_ = pushCmd.RegisterFlagCompletionFunc("eol", completeValues("lf", "crlf", "auto"))
+	_ = pushCmd.RegisterFlagCompletionFunc("branch", completeBranches)
 }
 
 var pushCmd = &cobra.Command{
@@ -44,19 +209,22 @@ var pushCmd = &cobra.Command{
 	Long: `Push changes to GitHub with optional AI-powered commit messages.
 Example: 
   ghquick push start        # AI-powered push with automatic commit message
-  ghquick push --name my-repo --commitmsg "feature: new stuff"`,
+  ghquick push --name my-repo --commitmsg "feature: new stuff"
+  ghquick push start --remotes origin,mirror  # Push to several remotes
+  ghquick push start --scope api --commit-type fix  # Pin the Conventional Commit header
+  ghquick push start --amend                        # Fold changes into the last commit, new message`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		logger = log.New(debug)
 		if len(args) > 0 && args[0] == "start" {
 			autoCommit = true
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		ctx, cancel := commandContext(timeout)
 		defer cancel()
 
 		// Load configuration
+		log.SetPhase("setup")
 		logger.Step("Loading configuration...")
-		cfg, err := config.LoadFromEnv()
+		cfg, err := config.Load(tokenResolver())
 		if err != nil {
 			logger.Error("Failed to load configuration")
 			return fmt.Errorf("failed to load config: %w", err)
@@ -64,101 +232,1295 @@ Example:
 		logger.Success("Configuration loaded")
 
 		// Get current working directory
-		wd, err := os.Getwd()
+		wd, err := resolveWorkingDir()
 		if err != nil {
 			logger.Error("Failed to get working directory")
-			return fmt.Errorf("failed to get working directory: %w", err)
+			return err
+		}
+
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			logger.Error("Failed to load config file")
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+
+		if fileCfg.Remote != "" && !cmd.Flags().Changed("remotes") {
+			remotes = []string{fileCfg.Remote}
+		}
+		// --remotes= falls back to origin too, before anything reads remotes[0]
+		if len(remotes) == 0 {
+			remotes = []string{"origin"}
 		}
 
-		// If repo name is not provided, use current directory name
+		// Initialize services
+		gitOps := newGitOps(wd)
+		gitOps.SetCredentials(cfg.GitHubUsername, cfg.GitHubToken)
+
+		// If repo name is not provided, use current directory name. It only
+		// matters for a repository without origin yet, so that's when to ask.
 		if repoName == "" {
 			repoName = filepath.Base(wd)
-			logger.Info("Using current directory name as repository name: %s", repoName)
+			if !assumeYes && !dryRun && isInteractive() && !gitOps.HasRemote(ctx, "origin") {
+				if repoName, err = promptValue("Repository name", repoName); err != nil {
+					return err
+				}
+			} else {
+				logger.Info("Using current directory name as repository name: %s", repoName)
+			}
 		}
 
-		// Initialize services
-		gitOps := git.NewOperations(wd, debug)
-		ghClient := github.NewClient(cfg.GitHubToken, debug)
-		commitGen := ai.NewCommitMessageGenerator(cfg.OpenAIKey)
+		exts := allowedExts
+		if len(exts) == 0 {
+			exts = fileCfg.AllowedExtensions
+		}
+		gitOps.SetAllowedExtensions(exts, allowlistStrict || fileCfg.AllowlistStrict)
+		gitOps.SetExcludePatterns(append(fileCfg.Exclude, excludePaths...))
+		switch key := strutil.FirstNonEmpty(signingKey, fileCfg.SigningKey); {
+		case noGPGSign:
+			gitOps.SetSigning(git.SignOff, "")
+		case signCommit || signingKey != "" || fileCfg.SignCommits:
+			gitOps.SetSigning(git.SignOn, key)
+		}
+		lockStrategy, err := git.ParseLockStrategy(fileCfg.LockStrategy)
+		if err != nil {
+			return err
+		}
+		if waitForLock > 0 {
+			lockStrategy = git.LockWait
+		}
+		gitOps.SetLockStrategy(lockStrategy, firstPositiveDuration(waitForLock, fileCfg.LockWait))
+		gitOps.SetConfigLockRetries(fileCfg.ConfigLockRetries)
+		gitOps.SetAutoPrune(fileCfg.AutoPrune)
+		gitOps.SetGlobalGitUser(globalGitUser)
+		gitOps.SetFollowTags(followTags || fileCfg.FollowTags)
+		scheme, err := git.ParseRemoteScheme(strutil.FirstNonEmpty(remoteScheme, fileCfg.RemoteScheme))
+		if err != nil {
+			return err
+		}
+		gitOps.SetRemoteScheme(scheme, fileCfg.GitHubHost)
+		if err := gitOps.SetRetryPatterns(fileCfg.RetryPatterns); err != nil {
+			return err
+		}
+		policy := retryPolicy(fileCfg)
+		policy.Attempts = firstPositive(pushAttempts, fileCfg.PushAttempts, fileCfg.RetryAttempts)
+		gitOps.SetRetryPolicy(policy)
+		ghClient := newGitHubClient(cfg.GitHubToken)
+		ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+		// Only 'start' needs the AI provider, so a missing key matters only then
+		provider, providerErr := ai.NewProvider(aiProviderConfig(cfg, fileCfg))
+		if providerErr != nil && autoCommit {
+			return providerErr
+		}
+
+		if dryRun {
+			// Stage into a throwaway copy of the index so the real one is left alone
+			restore, err := gitOps.IsolateIndex(ctx)
+			if err != nil {
+				return err
+			}
+			defer restore()
+			logger.Info("[dry-run] Skipping repository creation and git setup")
+		} else {
+			// Ensure GitHub repository exists
+			if err := ghClient.EnsureRepositoryExists(ctx, repoName, github.RepoOptions{
+				Private:     private,
+				Description: repoDescription,
+				Create:      createRepo || fileCfg.CreateRepo,
+			}); err != nil {
+				return fmt.Errorf("failed to ensure repository exists: %w", err)
+			}
+
+			// Ensure git is set up
+			applyIdentity(ctx, gitOps, fileCfg, wd, cfg.GitHubUsername+"/"+repoName)
+			if err := gitOps.EnsureGitSetup(ctx, repoName); err != nil {
+				return fmt.Errorf("failed to setup git: %w", err)
+			}
+
+			// Fail before staging or spending an AI call when the push can't succeed
+			if skipPreflight {
+				logger.Debug("Skipping preflight checks")
+			} else if err := preflight(ctx, gitOps, cfg.GitHubUsername, remotes); err != nil {
+				return err
+			}
+		}
+		if err := gitOps.CheckSigning(ctx); err != nil {
+			return err
+		}
+
+		// Don't pile auto-staged files onto an index curated by hand (git add -p)
+		if fileCfg.RequireCleanIndex && !forceStage && !stagedOnly {
+			staged, err := gitOps.StagedChanges(ctx)
+			if err != nil {
+				return err
+			}
+			if len(staged) > 0 {
+				logger.Error("The index already has %d staged file(s): %s", len(staged), strings.Join(staged, ", "))
+				return fmt.Errorf("refusing to auto-stage on top of staged changes; commit them first or pass --force-stage")
+			}
+		}
 
-		// Ensure GitHub repository exists
-		if err := ghClient.EnsureRepositoryExists(ctx, repoName, private); err != nil {
-			return fmt.Errorf("failed to ensure repository exists: %w", err)
+		if deletionsOnly && len(includeUntracked) > 0 {
+			return fmt.Errorf("--deletions-only and --include-untracked cannot be combined")
+		}
+		if stagedOnly && (deletionsOnly || len(includeUntracked) > 0) {
+			return fmt.Errorf("--staged-only commits the index as-is and cannot be combined with --deletions-only or --include-untracked")
+		}
+		selective := len(stagePaths) > 0 || interactiveStage
+		if selective && (stagedOnly || deletionsOnly || len(includeUntracked) > 0) {
+			return fmt.Errorf("--paths and --interactive choose what to stage and cannot be combined with --staged-only, --deletions-only or --include-untracked")
+		}
+		if interactiveStage && !isInteractive() {
+			return fmt.Errorf("--interactive needs a terminal; name the files with --paths instead")
+		}
+
+		// Amending a pushed commit rewrites published history
+		if amendCommit {
+			branch := pushBranch
+			if branch == "" {
+				if branch, err = gitOps.CurrentBranch(ctx); err != nil {
+					return err
+				}
+			}
+			if _, err := checkRewrite(ctx, gitOps, fileCfg, remotes[0], branch, "HEAD", force, false); err != nil {
+				return err
+			}
+		}
+
+		// Don't record submodule pointers to commits that exist only locally
+		submodulePolicy, err := git.ParseSubmodulePolicy(strutil.FirstNonEmpty(detachedSubmodules, fileCfg.DetachedSubmodules))
+		if err != nil {
+			return err
+		}
+		if err := gitOps.CheckSubmodules(ctx, submodulePolicy); err != nil {
+			return err
 		}
 
-		// Ensure git is set up
-		if err := gitOps.EnsureGitSetup(ctx, repoName); err != nil {
-			return fmt.Errorf("failed to setup git: %w", err)
+		// Catch generated directories (dist/, node_modules/...) before git add -A sweeps them in
+		if !stagedOnly && !deletionsOnly && !selective && len(includeUntracked) == 0 {
+			if err := checkNewDirectories(ctx, gitOps, firstPositive(newDirThreshold, fileCfg.NewDirThreshold, defaultNewDirThreshold)); err != nil {
+				return err
+			}
 		}
 
-		// Stage all files first
-		if err := gitOps.StageAll(ctx); err != nil {
-			if err.Error() == "no changes to commit" {
+		log.SetPhase("stage")
+		// Stage all files first, or only the deletions with --deletions-only, or
+		// the chosen files with --paths/--interactive; --staged-only commits
+		// exactly what is already in the index
+		if stagedOnly {
+			if err := reportStagedFiles(ctx, gitOps); err != nil {
+				return err
+			}
+		} else if deletionsOnly {
+			deleted, err := gitOps.StageDeletions(ctx)
+			if err != nil {
+				if errors.Is(err, git.ErrNoChanges) {
+					logger.Warning("No deleted files to commit")
+					return nil
+				}
+				return fmt.Errorf("failed to stage deletions: %w", err)
+			}
+			if commitMsg == "" {
+				commitMsg = deletionMessage(deleted)
+				autoCommit = false
+			}
+		} else if selective {
+			paths := stagePaths
+			if interactiveStage {
+				entries, err := gitOps.GetStatus(ctx)
+				if err != nil {
+					return err
+				}
+				if paths, err = selectFiles(entries); err != nil {
+					if errors.Is(err, git.ErrNoChanges) {
+						logger.Warning("No changes to commit")
+						return nil
+					}
+					return err
+				}
+			}
+			if err := gitOps.StageFiles(ctx, paths); err != nil && !(amendCommit && errors.Is(err, git.ErrNoChanges)) {
+				if errors.Is(err, git.ErrNoChanges) {
+					logger.Warning("No changes to commit in %s", strings.Join(paths, ", "))
+					return nil
+				}
+				return fmt.Errorf("failed to stage files: %w", err)
+			}
+		} else if len(includeUntracked) > 0 {
+			if err := gitOps.StageWithUntracked(ctx, includeUntracked); err != nil && !(amendCommit && errors.Is(err, git.ErrNoChanges)) {
+				if errors.Is(err, git.ErrNoChanges) {
+					logger.Warning("No changes to commit")
+					return nil
+				}
+				return fmt.Errorf("failed to stage files: %w", err)
+			}
+		} else if err := gitOps.StageAll(ctx); err != nil && !(amendCommit && errors.Is(err, git.ErrNoChanges)) {
+			if errors.Is(err, git.ErrNoChanges) {
 				logger.Warning("No changes to commit")
 				return nil
 			}
 			return fmt.Errorf("failed to stage files: %w", err)
 		}
 
-		// Get diff for commit message generation
-		diff, err := gitOps.GetDiff(ctx)
+		// Symlinks are committed as-is unless configured otherwise
+		symlinkPolicy, err := git.ParseSymlinkPolicy(fileCfg.Symlinks)
 		if err != nil {
+			return err
+		}
+		if skipSymlinks {
+			symlinkPolicy = git.SymlinkSkip
+		}
+		if skipped, err := gitOps.ApplySymlinkPolicy(ctx, symlinkPolicy); err != nil {
+			return fmt.Errorf("failed to check staged symlinks: %w", err)
+		} else if len(skipped) > 0 && symlinkPolicy == git.SymlinkSkip {
+			if staged, err := gitOps.StagedFileNames(ctx); err != nil {
+				return err
+			} else if len(staged) == 0 {
+				logger.Warning("No changes to commit besides skipped symlinks")
+				return nil
+			}
+		}
+
+		// Normalize line endings of staged text files, if requested. This and --format
+		// re-stage whole files, which would fold unstaged hunks into a curated index.
+		if stagedOnly && (runFormat || strutil.FirstNonEmpty(eolTarget, fileCfg.EOL) != "") {
+			logger.Warning("Skipping formatting and line-ending normalization with --staged-only")
+		} else if dryRun && (runFormat || strutil.FirstNonEmpty(eolTarget, fileCfg.EOL) != "") {
+			logger.Info("[dry-run] Skipping formatting and line-ending normalization, which rewrite files")
+		} else if name := strutil.FirstNonEmpty(eolTarget, fileCfg.EOL); name != "" {
+			target, err := git.ParseEOL(name)
+			if err != nil {
+				return err
+			}
+			if _, err := gitOps.NormalizeLineEndings(ctx, target); err != nil {
+				return fmt.Errorf("failed to normalize line endings: %w", err)
+			}
+		}
+
+		// Format the code and re-stage whatever the formatter touched, if requested
+		if runFormat && !stagedOnly && !dryRun {
+			if err := formatAndRestage(ctx, gitOps, wd, fileCfg); err != nil {
+				return err
+			}
+		}
+
+		// Run the project's tests before committing, if requested
+		var testReport *checks.TestReport
+		if runTests || testCommand != "" {
+			log.SetPhase("test")
+			report, err := runProjectTests(ctx, wd, fileCfg)
+			if err != nil {
+				return err
+			}
+			testReport = report
+		}
+
+		// Gate the commit on the pre_commit hooks, --check ones last; a failure leaves the index as staged
+		hooks := fileCfg.Hooks.PreCommit
+		for _, c := range preCommitChecks {
+			hooks = append(hooks, config.Hook{Run: c})
+		}
+		if len(hooks) > 0 {
+			log.SetPhase("check")
+			if err := runHooks(ctx, wd, hookPreCommit, hooks, hookLimit(fileCfg)); err != nil {
+				return fmt.Errorf("%w; nothing was committed and your changes are still staged", err)
+			}
+		}
+
+		// Get diff for commit message generation; an amend covers the whole amended commit
+		var diff string
+		if amendCommit {
+			diff, err = gitOps.AmendDiff(ctx)
+		} else {
+			diff, err = gitOps.GetDiff(ctx, noFallbackUnstaged || stagedOnly)
+		}
+		if err != nil {
+			if errors.Is(err, git.ErrNoChanges) {
+				logger.Warning("Nothing staged to commit")
+				return nil
+			}
 			return fmt.Errorf("failed to get diff: %w", err)
 		}
 
+		// Warn about new TODO/FIXME markers; --no-todos turns this into a hard failure
+		if findings := scan.ScanDiffForMarkers(diff, fileCfg.TodoMarkers); len(findings) > 0 {
+			for _, f := range findings {
+				logger.Warning("%s:%d: new %s: %s", f.File, f.Line, f.Rule, f.Text)
+			}
+			if noTodos {
+				return fmt.Errorf("%d new TODO/FIXME marker(s) in staged changes (remove --no-todos to allow)", len(findings))
+			}
+		}
+
+		// Block credentials before they are committed, let alone pushed
+		if err := checkSecrets(diff, allowSecrets); err != nil {
+			return err
+		}
+
 		// Generate commit message if needed
+		validator := strutil.FirstNonEmpty(commitMsgValidator, fileCfg.CommitMsgValidator)
+		var summary commit.ChangeSummary
+		// handWritten is set for a message given with --commitmsg or typed at the prompt
+		handWritten := cmd.Flags().Changed("commitmsg") && !autoCommit
+		// regenerate writes a new message for the diff once the validator has
+		// rejected one; only generated messages get another try
+		var regenerate func(ctx context.Context, feedback string) (string, error)
+		// Without --commitmsg or 'start', a terminal session picks how to write the message
+		if !autoCommit && commitMsg == "" && !amendCommit && !assumeYes && isInteractive() {
+			style, err := promptChoice("Commit message: generate it, generate a Conventional Commit, or write it?", []string{"generate", "conventional", "write"}, "generate")
+			if err != nil {
+				return err
+			}
+			if style == "write" {
+				if commitMsg, err = promptValue("Commit message", ""); err != nil {
+					return err
+				}
+				handWritten = true
+			} else {
+				if providerErr != nil {
+					return providerErr
+				}
+				autoCommit = true
+				conventional = conventional || style == "conventional"
+			}
+		}
 		if autoCommit {
-			logger.Step("Generating commit message...")
-			result := make(chan ai.GenerateResult, 1)
-			commitGen.GenerateFromDiffAsync(ctx, diff, result)
+			log.SetPhase("generate")
+			breakingOpts := commit.DefaultBreakingOptions()
+			breakingOpts.IncludeInternal = breakingInternal
+			if !detectBreaking && !fileCfg.DetectBreaking {
+				breakingOpts = commit.BreakingOptions{}
+			}
+			files := git.ParseDiff(diff)
+			summary = commit.Summarize(files, breakingOpts)
+
+			language, err := ai.ParseLanguage(strutil.FirstNonEmpty(commitLanguage, fileCfg.CommitLanguage))
+			if err != nil {
+				return err
+			}
+			genOpts := ai.Options{
+				Language:        language,
+				StructuredBody:  structuredBody || fileCfg.StructuredBody,
+				MaxBodyLines:    firstPositive(maxBodyLines, fileCfg.MaxBodyLines),
+				MaxSummaryWords: firstPositive(maxSummaryWords, fileCfg.MaxSummaryWords),
+			}
+			if splitLargeDiff {
+				genOpts.SplitThreshold = maxDiffBytes
+				if !cmd.Flags().Changed("max-diff-bytes") {
+					genOpts.TokenBudget = fileCfg.AITokenBudget
+				}
+			}
 
-			select {
-			case res := <-result:
-				if res.Error != nil {
-					logger.Error("Failed to generate commit message")
-					return fmt.Errorf("failed to generate commit message: %w", res.Error)
+			typ, err := commit.ParseCommitType(commitType)
+			if err != nil {
+				return err
+			}
+			if conventional || fileCfg.Conventional || commitScope != "" || typ != "" {
+				genOpts.Conventional = true
+				genOpts.CommitType = typ
+				genOpts.InferredType = commit.InferType(files)
+				if typ == "" && genOpts.InferredType != "" {
+					logger.Debug("Inferred commit type: %s", genOpts.InferredType)
 				}
-				commitMsg = res.Message
-				logger.Success("Commit message generated: %s", commitMsg)
-			case <-ctx.Done():
-				return ctx.Err()
 			}
+
+			if commitScope != "" {
+				genOpts.Scope = commitScope
+			} else if autoScope || fileCfg.AutoScope {
+				scope := summary.Scope
+				if scope == "" && multiScope {
+					scope = commit.JoinScopes(commit.DeriveScopes(files))
+				}
+				if scope != "" {
+					logger.Debug("Derived commit scope: %s", scope)
+					genOpts.Scope = scope
+				}
+			}
+			flow := &messageFlow{
+				gen:     ai.NewCommitMessageGenerator(provider).WithOptions(genOpts),
+				diff:    diff,
+				opts:    genOpts,
+				summary: summary,
+				confirm: !assumeYes && isInteractive(),
+			}
+			if commitMsg, err = flow.generate(ctx); err != nil {
+				return err
+			}
+			regenerate = flow.regenerate
 		}
 
-		if commitMsg == "" {
+		// Without a new message an amend keeps the existing one untouched
+		if commitMsg == "" && !amendCommit {
 			logger.Error("Commit message is required")
 			return fmt.Errorf("commit message is required (use --commitmsg or 'start' for AI-generated message)")
 		}
 
+		// Generated messages are already formatted; hand-written ones must conform
+		if (conventional || fileCfg.Conventional) && handWritten {
+			if err := commit.ValidateConventional(commitMsg); err != nil {
+				logger.Error("Commit message doesn't follow Conventional Commits")
+				return err
+			}
+		}
+
+		var sc commit.SubjectCase
+		if normalizeSubject || fileCfg.NormalizeSubject {
+			if sc, err = commit.ParseSubjectCase(strutil.FirstNonEmpty(subjectCase, fileCfg.SubjectCase)); err != nil {
+				return err
+			}
+		}
+		// finish adds what every message gets however it was written; the
+		// validator below checks the result, as that is what is committed
+		finish := func(msg string) (string, error) {
+			if msg == "" {
+				return msg, nil
+			}
+			if normalizeSubject || fileCfg.NormalizeSubject {
+				msg = commit.NormalizeSubject(msg, sc)
+			}
+			if testReport != nil {
+				if commit.HasSectionHeadings(msg) {
+					structured := commit.ParseCommitMessage(msg)
+					structured.Testing = testReport.Summary()
+					msg = structured.Render()
+				} else {
+					msg = strings.TrimRight(msg, "\n") + "\n\n" + testReport.Summary()
+				}
+			}
+			prefix := strutil.FirstNonEmpty(messagePrefix, fileCfg.MessagePrefix)
+			suffix := strutil.FirstNonEmpty(messageSuffix, fileCfg.MessageSuffix)
+			if prefix != "" || suffix != "" {
+				var err error
+				if msg, err = commit.Decorate(msg, prefix, suffix); err != nil {
+					return "", err
+				}
+			}
+			if limit := firstPositive(maxMessageBytes, fileCfg.MaxMessageBytes); limit > 0 {
+				if limited, truncated := commit.LimitSize(msg, limit); truncated {
+					logger.Warning("Commit message exceeds %d bytes; body truncated", limit)
+					msg = limited
+				}
+			}
+			if actor := os.Getenv("GITHUB_ACTOR"); asBot && actor != "" && (botTrailer || fileCfg.BotActorTrailer) {
+				msg = strings.TrimRight(msg, "\n") + "\n\nTriggered-by: " + actor
+			}
+			return msg, nil
+		}
+		if commitMsg, err = finish(commitMsg); err != nil {
+			return err
+		}
+
+		if (fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "") && !asBot {
+			gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+		}
+		if asBot {
+			name := strutil.FirstNonEmpty(fileCfg.BotName, defaultBotName)
+			email := strutil.FirstNonEmpty(fileCfg.BotEmail, defaultBotEmail)
+			gitOps.SetCommitIdentity(name, email)
+			logger.Info("Committing as %s <%s>", name, email)
+		}
+
+		if validator != "" && commitMsg != "" {
+			var retry func(ctx context.Context, feedback string) (string, error)
+			if regenerate != nil {
+				retry = func(ctx context.Context, feedback string) (string, error) {
+					msg, err := regenerate(ctx, feedback)
+					if err != nil {
+						return "", err
+					}
+					return finish(msg)
+				}
+			}
+			if commitMsg, err = lintCommitMessage(ctx, wd, validator, commitMsg, retry); err != nil {
+				return err
+			}
+		}
+
+		if limit := strutil.FirstNonEmpty(warnBlobSize, fileCfg.WarnBlobSize); limit != "" {
+			if err := reportBlobSize(ctx, gitOps, limit); err != nil {
+				return err
+			}
+		}
+
+		if !dryRun {
+			if err := confirmCommit(ctx, gitOps, commitMsg); err != nil {
+				return err
+			}
+		}
+
+		log.SetPhase("commit")
+		var staged []string
+		if verifyCommit || log.JSON() {
+			if staged, err = gitOps.StagedFileNames(ctx); err != nil {
+				return err
+			}
+		}
+
 		// Commit changes
-		if err := gitOps.Commit(ctx, commitMsg); err != nil {
+		if dryRun && !amendCommit {
+			if err := reportStagedFiles(ctx, gitOps); err != nil {
+				return err
+			}
+		}
+		parent, _ := gitOps.HeadCommit(ctx)
+		if amendCommit {
+			if err := gitOps.Amend(ctx, commitMsg); err != nil {
+				return err
+			}
+		} else if err := gitOps.Commit(ctx, commitMsg); err != nil {
 			return fmt.Errorf("failed to commit: %w", err)
 		}
+		var sha string
+		var op git.Operation
+		if !dryRun {
+			sha, _ = gitOps.HeadCommit(ctx)
+			localBranch, _ := gitOps.CurrentBranch(ctx)
+			op = git.Operation{SHA: sha, Parent: parent, Branch: localBranch, Amended: amendCommit, Time: time.Now()}
+			recordOperation(ctx, gitOps, op)
+		}
+		logger.Event(map[string]interface{}{"commit_message": commitMsg, "files": staged, "sha": sha, "dry_run": dryRun})
 
-		// Push changes with retry
-		maxRetries := 3
-		for i := 0; i < maxRetries; i++ {
-			if i > 0 {
-				logger.Warning("Retrying push (attempt %d/%d)...", i+1, maxRetries)
-				time.Sleep(2 * time.Second) // Wait before retry
+		// An amended commit also holds files from before, which weren't staged now
+		if verifyCommit && !dryRun && !amendCommit {
+			d, err := gitOps.VerifyCommit(ctx, staged)
+			if err != nil {
+				return err
+			}
+			for _, p := range d.Missing {
+				logger.Warning("Staged but not committed: %s", p)
+			}
+			for _, p := range d.Unexpected {
+				logger.Warning("Committed but not staged: %s", p)
 			}
+			if d.Empty() {
+				logger.Success("Commit matches the staged files")
+			}
+		}
 
-			err := gitOps.Push(ctx, "origin", "main")
-			if err == nil {
-				logger.Success("🚀 Successfully pushed changes to GitHub!")
-				return nil
+		// Push changes to every requested remote, reporting each one
+		log.SetPhase("push")
+		if len(fileCfg.Hooks.PrePush) > 0 {
+			if err := runHooks(ctx, wd, hookPrePush, fileCfg.Hooks.PrePush, hookLimit(fileCfg)); err != nil {
+				printNextStep(ctx, gitOps, fileCfg, outcome{Committed: true})
+				return fmt.Errorf("%w; the commit is kept locally and nothing was pushed", err)
 			}
+		}
 
-			if ctx.Err() != nil {
-				logger.Error("Operation timed out")
-				return fmt.Errorf("operation timed out after %v: %w", timeout, ctx.Err())
+		branch := pushBranch
+		if branch == "" {
+			if branch, err = gitOps.PushBranch(ctx, remotes[0]); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			for _, remote := range remotes {
+				if err := gitOps.Push(ctx, remote, branch); err != nil {
+					return err
+				}
+			}
+			if openPR {
+				logger.Info("[dry-run] Would open a pull request for %s unless it is the base branch", branch)
+			}
+			if watchPushChecks {
+				logger.Info("[dry-run] Would follow the checks for the pushed commit")
+			}
+			logger.Result(map[string]interface{}{"ok": true, "dry_run": true, "branch": branch, "remotes": remotes})
+			return nil
+		}
+		if previewRemote {
+			if err := previewPush(ctx, gitOps, remotes[0], branch); err != nil {
+				printNextStep(ctx, gitOps, fileCfg, outcome{Committed: true})
+				return err
+			}
+		}
+		if window := firstPositiveDuration(confirmWindow, fileCfg.ConfirmWindow); window > 0 && isInteractive() {
+			cancelled, err := graceWindow(ctx, window, "Pushing")
+			if err != nil {
+				return err
 			}
+			if cancelled {
+				logger.Warning("Push cancelled")
+				answer, err := readLine("Undo the commit as well? [y/N]: ")
+				if err != nil {
+					return err
+				}
+				if a := strings.ToLower(answer); a == "y" || a == "yes" {
+					return gitOps.Undo(ctx)
+				}
+				logger.Info("Commit kept locally")
+				printNextStep(ctx, gitOps, fileCfg, outcome{Committed: true})
+				return nil
+			}
+		}
 
-			if i == maxRetries-1 {
-				return fmt.Errorf("failed to push after %d attempts: %w", maxRetries, err)
+		var failed []string
+		state := outcome{Committed: true, Branch: branch}
+		prBase := strutil.FirstNonEmpty(pushPRBase, fileCfg.DefaultBranch)
+		for _, remote := range remotes {
+			err := gitOps.Push(ctx, remote, branch)
+			if err != nil && syncOnReject && errors.Is(err, git.ErrNonFastForward) {
+				err = syncAndRepush(ctx, gitOps, remote, branch)
+			}
+			// Switching branches part way through several remotes would push
+			// different branches to each, so only a single remote falls back
+			if err != nil && errors.Is(err, git.ErrProtectedBranch) && len(remotes) == 1 {
+				var moved string
+				if moved, err = protectedFallback(ctx, gitOps, remote, branch, err); moved != "" {
+					prBase, openPR = branch, true
+					branch, state.Branch, op.Branch = moved, moved, moved
+				}
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					logger.Error("Operation timed out")
+					return fmt.Errorf("operation timed out after %v: %w", timeout, context.Cause(ctx))
+				}
+				logger.Error("Failed to push to %s: %v", remote, err)
+				failed = append(failed, remote)
+				state.Rejected = state.Rejected || isRejectedPush(err)
+				continue
 			}
+			logger.Success("🚀 Successfully pushed changes to %s!", remote)
+			op.Remotes = append(op.Remotes, remote)
+		}
+		state.Pushed = len(failed) < len(remotes)
+		if state.Pushed {
+			op.RemoteBranch = branch
+			recordOperation(ctx, gitOps, op)
+		}
+		var prURL string
+		if openPR && state.Pushed {
+			log.SetPhase("pr")
+			if prURL, err = pushPullRequest(ctx, gitOps, ghClient, wd, branch, prBase, fileCfg); err != nil {
+				logger.Error("Failed to open pull request: %v", err)
+				return fmt.Errorf("pushed %s but failed to open a pull request: %w", branch, err)
+			}
+		}
+		printNextStep(ctx, gitOps, fileCfg, state)
+		result := map[string]interface{}{"ok": true, "sha": sha, "branch": branch, "remotes": remotes, "pr_url": prURL}
+		if watchPushChecks && state.Pushed {
+			log.SetPhase("checks")
+			runs, err := watchPushedChecks(gitOps, ghClient, op.Remotes[0], sha)
+			result["checks"] = runs
+			if err != nil {
+				result["ok"], result["error"] = false, err.Error()
+				logger.Result(result)
+				return fmt.Errorf("pushed %s but its checks didn't pass: %w", shortSHA(sha), err)
+			}
+		}
+		if len(failed) == 0 {
+			logger.Result(result)
 		}
 
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to push to %d of %d remote(s): %s", len(failed), len(remotes), strings.Join(failed, ", "))
+		}
 		return nil
 	},
 }
+
+// runProjectTests runs the configured test command and blocks on failure unless --force is set
+func runProjectTests(ctx context.Context, wd string, fileCfg *config.FileConfig) (*checks.TestReport, error) {
+	command := testCommand
+	if command == "" {
+		command = fileCfg.TestCommand
+	}
+	if command == "" {
+		command = defaultTestCommand
+	}
+
+	logger.Step("Running tests: %s", command)
+	report := checks.RunTests(ctx, wd, command)
+	if report.OK() {
+		logger.Success("%s", report.Summary())
+		return &report, nil
+	}
+
+	logger.Error("%s", report.Summary())
+	logger.Debug("Test output:\n%s", report.Output)
+	if !force {
+		return nil, fmt.Errorf("tests failed (%s); use --force to commit anyway", report.Summary())
+	}
+	logger.Warning("Committing despite failing tests (--force)")
+	return &report, nil
+}
+
+// formatAndRestage runs the configured formatter and re-stages the staged files it
+// rewrote, so the commit contains formatted code
+func formatAndRestage(ctx context.Context, gitOps *git.Operations, wd string, fileCfg *config.FileConfig) error {
+	command := strutil.FirstNonEmpty(fileCfg.FormatCommand, defaultFormatCommand)
+
+	logger.Step("Formatting: %s", command)
+	if output, err := checks.Run(ctx, wd, command); err != nil {
+		logger.Error("Formatter failed")
+		if output = strings.TrimSpace(output); output != "" {
+			logger.Info("Formatter output:\n%s", output)
+		}
+		return fmt.Errorf("formatter failed: %w", err)
+	}
+
+	restaged, err := gitOps.RestageModified(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to re-stage formatted files: %w", err)
+	}
+	if len(restaged) > 0 {
+		logger.Success("Formatted and re-staged %d file(s)", len(restaged))
+	} else {
+		logger.Success("Code already formatted")
+	}
+	return nil
+}
+
+// firstPositive returns the first value greater than zero, or 0
+func firstPositive(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// firstPositiveDuration returns the first duration greater than zero, or 0
+func firstPositiveDuration(values ...time.Duration) time.Duration {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// aiProviderConfig picks the AI provider, model and endpoint from the flags and
+// config file, with the API key for that provider
+func aiProviderConfig(cfg *config.Config, fileCfg *config.FileConfig) ai.ProviderConfig {
+	pc := ai.ProviderConfig{
+		Name:     strings.ToLower(strutil.FirstNonEmpty(aiProvider, fileCfg.AIProvider)),
+		Model:    strutil.FirstNonEmpty(aiModel, fileCfg.AIModel),
+		Endpoint: fileCfg.AIEndpoint,
+	}
+	switch pc.Name {
+	case ai.ProviderAnthropic:
+		pc.APIKey = cfg.AnthropicKey
+	case ai.ProviderOllama:
+	default:
+		pc.APIKey = cfg.OpenAIKey
+	}
+	return pc
+}
+
+// recordOperation saves what this run did for 'ghquick status' and 'ghquick undo'.
+// Failing to record doesn't fail the push.
+func recordOperation(ctx context.Context, gitOps *git.Operations, op git.Operation) {
+	if err := gitOps.RecordOperation(ctx, op); err != nil {
+		logger.Debug("Failed to record operation: %v", err)
+	}
+}
+
+// preflight checks that the GitHub username is set and that every remote the
+// push targets is configured, reachable and accepts the credentials
+func preflight(ctx context.Context, gitOps *git.Operations, username string, remotes []string) error {
+	if strings.TrimSpace(username) == "" {
+		logger.Error("GITHUB_USERNAME is not set")
+		return fmt.Errorf("GITHUB_USERNAME is required; set it or run 'ghquick auth login'")
+	}
+	if len(remotes) == 0 {
+		remotes = []string{"origin"}
+	}
+	for _, remote := range remotes {
+		if err := gitOps.CheckRemoteAccess(ctx, remote); err != nil {
+			return fmt.Errorf("preflight failed, nothing was staged or committed (use --skip-preflight to commit offline): %w", err)
+		}
+	}
+	return nil
+}
+
+// confirmCommit prints the size of the staged change and the final commit
+// message, then asks before committing and pushing. Without a terminal, or with
+// --yes, it goes ahead without asking.
+func confirmCommit(ctx context.Context, gitOps *git.Operations, message string) error {
+	if assumeYes || !isInteractive() {
+		return nil
+	}
+	stat, err := gitOps.StagedDiffStat(ctx, amendCommit)
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		message = "(keeping the existing message)"
+	}
+	fmt.Printf("\n%s\n\nCommit message:\n\n%s\n\n", stat.Summary, message)
+	answer, err := readLine(fmt.Sprintf("Commit %d file(s) (+%d/-%d) and push? [Y/n]: ", stat.Files, stat.Insertions, stat.Deletions))
+	if err != nil {
+		return err
+	}
+	if a := strings.ToLower(answer); a != "" && a != "y" && a != "yes" {
+		return fmt.Errorf("commit cancelled; your changes are still staged")
+	}
+	return nil
+}
+
+// checkNewDirectories warns about untracked directories with many files and asks
+// before staging them; without a terminal it refuses unless --yes is given
+func checkNewDirectories(ctx context.Context, gitOps *git.Operations, threshold int) error {
+	dirs, err := gitOps.LargeNewDirectories(ctx, threshold)
+	if err != nil || len(dirs) == 0 {
+		return err
+	}
+
+	for _, d := range dirs {
+		logger.Warning("New directory %s contains %d files; if it is generated output, add it to .gitignore", d.Path, d.Files)
+	}
+	if assumeYes {
+		return nil
+	}
+	if !isInteractive() {
+		return fmt.Errorf("refusing to stage large new directories without --yes")
+	}
+	answer, err := readLine("Stage them anyway? [y/N]: ")
+	if err != nil {
+		return err
+	}
+	if a := strings.ToLower(answer); a != "y" && a != "yes" {
+		return fmt.Errorf("staging cancelled; add the directories to .gitignore or use --include-untracked")
+	}
+	return nil
+}
+
+// reportBlobSize logs how many bytes of content the commit adds to history and
+// warns, naming the largest files, when that exceeds limit (e.g. "5MB")
+func reportBlobSize(ctx context.Context, gitOps *git.Operations, limit string) error {
+	threshold, err := parseByteSize(limit)
+	if err != nil {
+		return err
+	}
+	sizes, total, err := gitOps.StagedBlobSizes(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Staged content: %s across %d file(s)", formatBytes(total), len(sizes))
+	if total <= threshold {
+		return nil
+	}
+	logger.Warning("Commit adds %s to history, over the %s threshold", formatBytes(total), formatBytes(threshold))
+	for i, s := range sizes {
+		if i == 5 {
+			break
+		}
+		logger.Warning("  %s  %s", formatBytes(s.Bytes), s.Path)
+	}
+	return nil
+}
+
+// parseByteSize parses sizes such as "512", "200KB", "5MB", or "1GB" (powers of 1024)
+func parseByteSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 500KB or 5MB)", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 MB"
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// reportStagedFiles lists what is in the index for --staged-only and fails when it is empty
+func reportStagedFiles(ctx context.Context, gitOps *git.Operations) error {
+	entries, err := gitOps.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	var staged []git.FileStatus
+	for _, e := range entries {
+		if e.IsStaged() {
+			staged = append(staged, e)
+		}
+	}
+	if len(staged) == 0 {
+		logger.Error("Nothing is staged")
+		return fmt.Errorf("nothing staged to commit; stage files with git add or drop --staged-only")
+	}
+
+	logger.Info("Committing %d staged file(s):", len(staged))
+	if log.JSON() {
+		files := make([]string, len(staged))
+		for i, e := range staged {
+			files[i] = e.Path
+		}
+		logger.Event(map[string]interface{}{"staged": files})
+		return nil
+	}
+	for _, e := range staged {
+		fmt.Printf("  %c  %s\n", e.Index, e.Path)
+	}
+	return nil
+}
+
+// deletionMessage describes a commit that only removes files
+func deletionMessage(paths []string) string {
+	if len(paths) == 1 {
+		return "Remove " + paths[0]
+	}
+	msg := fmt.Sprintf("Remove %d files\n", len(paths))
+	for _, p := range paths {
+		msg += "\n- " + p
+	}
+	return msg
+}
+
+// maxLintAttempts is how many AI messages are tried before giving up on the validator
+const maxLintAttempts = 3
+
+// lintCommitMessage runs the validator on msg, the message as it will be
+// committed. With regenerate, a rejected message is replaced by one written with
+// the validator's output as feedback, up to maxLintAttempts times; without it
+// the rejection is final.
+func lintCommitMessage(ctx context.Context, wd, validator, msg string, regenerate func(ctx context.Context, feedback string) (string, error)) (string, error) {
+	for attempt := 1; ; attempt++ {
+		output, err := validateCommitMessage(ctx, wd, validator, msg)
+		if err == nil {
+			return msg, nil
+		}
+		if regenerate == nil {
+			return "", fmt.Errorf("commit message rejected by %q:\n%s", validator, output)
+		}
+		logger.Warning("Commit message failed validation (attempt %d/%d):\n%s", attempt, maxLintAttempts, output)
+		if attempt == maxLintAttempts {
+			return "", fmt.Errorf("no generated commit message passed %q after %d attempts", validator, maxLintAttempts)
+		}
+		if msg, err = regenerate(ctx, output); err != nil {
+			return "", err
+		}
+		logger.Info("New commit message: %s", msg)
+	}
+}
+
+// validateCommitMessage pipes msg to the validator command and returns its
+// trimmed output, with an error when the validator rejects the message
+func validateCommitMessage(ctx context.Context, wd, validator, msg string) (string, error) {
+	logger.Step("Validating commit message: %s", validator)
+	output, err := checks.RunWithInput(ctx, wd, validator, msg+"\n")
+	output = strings.TrimSpace(output)
+	if err != nil {
+		if output == "" {
+			output = err.Error()
+		}
+		return output, err
+	}
+	logger.Success("Commit message passed validation")
+	return output, nil
+}
+
+// generateCommitMessage asks the AI generator for a commit message for the diff
+func generateCommitMessage(ctx context.Context, commitGen *ai.CommitMessageGenerator, diff string) (string, error) {
+	logger.Step("Generating commit message...")
+	result := make(chan ai.GenerateResult, 1)
+	commitGen.GenerateFromDiffAsync(ctx, diff, result)
+
+	select {
+	case res := <-result:
+		if res.Error != nil {
+			logger.Error("Failed to generate commit message")
+			return "", fmt.Errorf("failed to generate commit message: %w", res.Error)
+		}
+		logger.Success("Commit message generated: %s", res.Message)
+		return res.Message, nil
+	case <-ctx.Done():
+		return "", context.Cause(ctx)
+	}
+}
+
+// messageFlow produces an AI commit message the same way for push and amend:
+// generated or taken from the cache, trimmed to the body budget, marked when the
+// change is breaking and, with confirm, offered to the user to accept, edit or
+// regenerate. Every regenerated message goes through the same steps.
+type messageFlow struct {
+	gen     *ai.CommitMessageGenerator
+	diff    string
+	opts    ai.Options
+	summary commit.ChangeSummary
+	confirm bool
+}
+
+// generate returns the first message for the diff
+func (f *messageFlow) generate(ctx context.Context) (string, error) {
+	if f.summary.BreakingChange {
+		logger.Warning("Possible breaking change: %s", strings.Join(f.summary.BreakingReasons, "; "))
+	}
+	msg, err := f.propose(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	return f.refine(ctx, msg)
+}
+
+// regenerate writes a new message after feedback, e.g. a validator's output,
+// rejected the last one
+func (f *messageFlow) regenerate(ctx context.Context, feedback string) (string, error) {
+	logger.Step("Regenerating commit message...")
+	msg, err := f.gen.GenerateWithFeedback(ctx, f.diff, feedback)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	}
+	return f.refine(ctx, f.shape(msg))
+}
+
+// propose generates a message; a fresh one bypasses the cache, which would only
+// repeat the last
+func (f *messageFlow) propose(ctx context.Context, fresh bool) (string, error) {
+	msg, err := cachedCommitMessage(ctx, f.gen, f.diff, f.opts, fresh)
+	if err != nil {
+		return "", err
+	}
+	return f.shape(msg), nil
+}
+
+// refine lets the user accept, edit or regenerate msg when confirm is set
+func (f *messageFlow) refine(ctx context.Context, msg string) (string, error) {
+	if !f.confirm {
+		return msg, nil
+	}
+	return refineCommitMessage(ctx, msg, func(ctx context.Context) (string, error) {
+		return f.propose(ctx, true)
+	})
+}
+
+// shape enforces the body budget and the breaking-change marker on a generated message
+func (f *messageFlow) shape(msg string) string {
+	if limited, truncated := commit.LimitBody(msg, f.opts.MaxBodyLines, f.opts.MaxSummaryWords); truncated {
+		logger.Debug("Trimmed generated message to the body budget")
+		msg = limited
+	}
+	if f.summary.BreakingChange {
+		msg = commit.MarkBreaking(msg, f.summary.BreakingReasons)
+	}
+	return msg
+}
+
+// checkSecrets stops a commit whose staged diff looks like it holds
+// credentials, unless allow is set
+func checkSecrets(diff string, allow bool) error {
+	findings := scan.ScanDiffForSecrets(diff)
+	if len(findings) == 0 {
+		return nil
+	}
+	for _, f := range findings {
+		logger.Error("%s:%d: possible %s: %s", f.File, f.Line, f.Rule, f.Text)
+	}
+	if !allow {
+		return fmt.Errorf("%d possible secret(s) in staged changes; remove them, mark false positives with a %q comment, or pass --allow-secrets", len(findings), scan.AllowSecretMarker)
+	}
+	logger.Warning("Committing possible secrets because --allow-secrets was passed")
+	return nil
+}
+
+// cachedCommitMessage reuses the message generated earlier for an identical diff
+// and options (e.g. when re-running after a failed push), unless --no-cache is
+// set or fresh asks for a new one, which then replaces it
+func cachedCommitMessage(ctx context.Context, commitGen *ai.CommitMessageGenerator, diff string, opts ai.Options, fresh bool) (string, error) {
+	key := cache.Key(diff, commitGen.ProviderName(), fmt.Sprintf("%+v", opts))
+	messages, err := cache.NewMessageCache()
+	if err != nil {
+		logger.Debug("Commit message cache unavailable: %v", err)
+		return generateCommitMessage(ctx, commitGen, diff)
+	}
+	if !noCache && !fresh {
+		if msg, ok := messages.Get(key); ok {
+			logger.Success("Reusing commit message generated for this diff: %s", msg)
+			return msg, nil
+		}
+	}
+
+	msg, err := generateCommitMessage(ctx, commitGen, diff)
+	if err != nil {
+		return "", err
+	}
+	if err := messages.Set(key, msg); err != nil {
+		logger.Debug("Failed to cache commit message: %v", err)
+	}
+	return msg, nil
+}
+
+// previewPush shows what the push would introduce on the remote and, when
+// interactive, asks for confirmation before continuing
+func previewPush(ctx context.Context, gitOps *git.Operations, remote, branch string) error {
+	diff, err := gitOps.GetPushPreviewDiff(ctx, remote, branch, true)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		logger.Info("Push introduces no changes on %s/%s", remote, branch)
+		return nil
+	}
+
+	files := git.ParseDiff(diff)
+	additions, deletions := 0, 0
+	for _, f := range files {
+		additions += f.Additions
+		deletions += f.Deletions
+	}
+	if !log.JSON() {
+		fmt.Printf("\n%s\n\n", diff)
+	}
+	logger.Info("Push introduces %d file(s) changed, +%d/-%d on %s/%s", len(files), additions, deletions, remote, branch)
+
+	if assumeYes || !isInteractive() {
+		return nil
+	}
+	answer, err := readLine("Push these changes? [y/N]: ")
+	if err != nil {
+		return err
+	}
+	if a := strings.ToLower(answer); a != "y" && a != "yes" {
+		return fmt.Errorf("push cancelled; your commit is kept locally")
+	}
+	return nil
+}
+
+// pushPullRequest opens a pull request for the branch just pushed against base
+// (the default branch when empty), titled and described by its last commit, and
+// returns its URL. Pushing to the base branch itself needs no pull request, so
+// that returns "" without error.
+func pushPullRequest(ctx context.Context, gitOps *git.Operations, ghClient *github.Client, wd, branch, base string, fileCfg *config.FileConfig) (string, error) {
+	in, err := buildPullRequest(ctx, gitOps, ghClient, wd, base)
+	if err != nil {
+		return "", err
+	}
+	if in.Base == branch && in.Head == branch {
+		logger.Info("Pushed to the base branch %s; no pull request needed", branch)
+		return "", nil
+	}
+	in.Draft = pushPRDraft || fileCfg.PRDraftDefault
+
+	pr, err := openPullRequest(ctx, ghClient, in, false)
+	if err != nil {
+		return "", err
+	}
+	logger.Success("🔗 %s", pr.URL)
+	return pr.URL, nil
+}
+
+// watchPushedChecks follows the checks for the commit just pushed to remote.
+// CI takes far longer than the push, so it gets its own deadline.
+func watchPushedChecks(gitOps *git.Operations, ghClient *github.Client, remote, sha string) ([]github.CheckRun, error) {
+	ctx, cancel := commandContext(checksTimeout)
+	defer cancel()
+	owner, repo, err := gitOps.RemoteRepo(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return watchChecks(ctx, ghClient, owner, repo, sha)
+}
+
+// protectedFallback handles a push refused because remote's branch is
+// protected: it asks (or with --auto-pr doesn't) whether to move the new
+// commits to a feature branch and push that instead, so a pull request can be
+// opened for them. It returns the new branch, or "" and pushErr when declined.
+func protectedFallback(ctx context.Context, gitOps *git.Operations, remote, branch string, pushErr error) (string, error) {
+	logger.Warning("%s/%s is protected and only accepts changes through pull requests", remote, branch)
+	if !autoPR {
+		if !isInteractive() {
+			logger.Info("Re-run with --auto-pr to push to a new branch and open a pull request instead")
+			return "", pushErr
+		}
+		answer, err := readLine("Push to a new branch and open a pull request instead? [Y/n]: ")
+		if err != nil {
+			return "", err
+		}
+		if a := strings.ToLower(answer); a != "" && a != "y" && a != "yes" {
+			return "", pushErr
+		}
+	}
+
+	subject, err := gitOps.CommitSubject(ctx, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	name := fallbackBranchName(ctx, gitOps, remote, subject)
+	if err := gitOps.MoveToNewBranch(ctx, name); err != nil {
+		return "", err
+	}
+	if err := gitOps.Push(ctx, remote, name); err != nil {
+		// branch still has the commits, so nothing is lost when name never got out
+		logger.Info("%s is unchanged; switch back with: git checkout %s", branch, branch)
+		return name, err
+	}
+	gitOps.ResetToRemote(ctx, remote, branch)
+	return name, nil
+}
+
+// fallbackBranchName derives a branch name from a commit subject, e.g.
+// "feat(api): Add rate limits" becomes ghquick/add-rate-limits, with a numeric
+// suffix when that name is taken locally or on remote
+func fallbackBranchName(ctx context.Context, gitOps *git.Operations, remote, subject string) string {
+	if prefix, description, ok := strings.Cut(subject, ": "); ok && !strings.Contains(prefix, " ") {
+		subject = description
+	}
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(subject) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "changes"
+	}
+
+	name := "ghquick/" + slug
+	for i := 2; gitOps.BranchExists(ctx, name) || gitOps.RemoteBranchExists(ctx, remote, name); i++ {
+		name = fmt.Sprintf("ghquick/%s-%d", slug, i)
+	}
+	return name
+}
+
+// syncAndRepush rebases onto a remote branch that moved ahead and retries the
+// push once. A conflicting rebase is aborted, leaving the branch as it was.
+func syncAndRepush(ctx context.Context, gitOps *git.Operations, remote, branch string) error {
+	logger.Warning("%s/%s has commits you don't have locally; rebasing (--sync)", remote, branch)
+	if err := gitOps.PullRebase(ctx, remote, branch); err != nil {
+		var conflict *git.RebaseConflictError
+		if errors.As(err, &conflict) {
+			logger.Info("Resolve these manually with 'git pull --rebase %s %s':", remote, branch)
+			for _, f := range conflict.Files {
+				logger.Info("  %s", f)
+			}
+		}
+		return err
+	}
+	return gitOps.Push(ctx, remote, branch)
+}
+
+// defaultMaxDiffBytes is the diff size above which diffs are summarized per file
+const defaultMaxDiffBytes = 12000
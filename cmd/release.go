@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saint/ghquick/internal/ai"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseFrom       string
+	releaseNotes      string
+	releaseAI         bool
+	releaseDraft      bool
+	releasePrerelease bool
+	releaseAssets     []string
+	// releaseUploadTimeout limits each asset upload, in place of --timeout
+	releaseUploadTimeout time.Duration
+)
+
+// defaultUploadTimeout is how long one asset may take to upload; release
+// binaries often take longer than the commands' two minutes on a slow link
+const defaultUploadTimeout = 30 * time.Minute
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.Flags().StringVar(&releaseFrom, "from", "", "Tag or commit the notes start from (defaults to the previous tag)")
+	releaseCmd.Flags().StringVar(&releaseNotes, "notes", "", "Release notes to use instead of the generated ones")
+	releaseCmd.Flags().BoolVar(&releaseAI, "ai", false, "Open the notes with an AI-written summary of the release")
+	releaseCmd.Flags().BoolVar(&releaseDraft, "draft", false, "Create the release as a draft")
+	releaseCmd.Flags().BoolVar(&releasePrerelease, "prerelease", false, "Mark the release as a prerelease")
+	releaseCmd.Flags().StringSliceVar(&releaseAssets, "asset", nil, "Upload these files to the release (comma-separated or repeated)")
+	releaseCmd.Flags().DurationVar(&releaseUploadTimeout, "upload-timeout", defaultUploadTimeout, "Give up on an asset upload after this long")
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release <version>",
+	Short: "Tag HEAD, push the tag and publish a GitHub release",
+	Long: `Create an annotated tag for HEAD, push it and create a GitHub release for it.
+The notes list the commits since the previous tag, grouped by Conventional
+Commit type; --ai adds a short summary in front of them.
+Example:
+  ghquick release v1.4.0
+  ghquick release v1.4.0 --ai --asset dist/ghquick-linux-amd64,dist/ghquick-darwin-arm64
+  ghquick release v2.0.0-rc.1 --prerelease --from v1.4.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+		version := args[0]
+
+		log.SetPhase("setup")
+		cfg, err := config.Load(tokenResolver())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		gitOps := newGitOps(wd)
+		gitOps.SetCredentials(cfg.GitHubUsername, cfg.GitHubToken)
+		gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+		if fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "" {
+			gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+		}
+		ghClient := newGitHubClient(cfg.GitHubToken)
+		ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+		// Catch everything that can be checked locally before anything is created
+		if err := gitOps.ValidateTagName(ctx, version); err != nil {
+			return err
+		}
+		if gitOps.TagExists(ctx, version) {
+			return fmt.Errorf("tag %s already exists", version)
+		}
+		for _, asset := range releaseAssets {
+			if info, err := os.Stat(asset); err != nil || info.IsDir() {
+				return fmt.Errorf("asset %s is not a readable file", asset)
+			}
+		}
+		remote := strutil.FirstNonEmpty(fileCfg.Remote, "origin")
+		owner, repo, err := gitOps.RemoteRepo(ctx, remote)
+		if err != nil {
+			return err
+		}
+
+		log.SetPhase("notes")
+		from := strutil.FirstNonEmpty(releaseFrom, gitOps.LatestTag(ctx))
+		notes := releaseNotes
+		if notes == "" {
+			data, err := gitOps.ClassifyCommits(ctx, from, "HEAD")
+			if err != nil {
+				return err
+			}
+			if len(data.Groups) == 0 {
+				return fmt.Errorf("no commits since %s to release", from)
+			}
+			notes = data.Markdown()
+			if releaseAI {
+				provider, err := ai.NewProvider(aiProviderConfig(cfg, fileCfg))
+				if err != nil {
+					return err
+				}
+				logger.Step("Summarizing the release...")
+				summary, err := ai.NewCommitMessageGenerator(provider).GenerateReleaseSummary(ctx, version, notes)
+				if err != nil {
+					return err
+				}
+				notes = summary + "\n\n" + notes
+			}
+		}
+		if from != "" {
+			notes += fmt.Sprintf("\n\n**Full Changelog**: https://%s/%s/%s/compare/%s...%s", strutil.FirstNonEmpty(fileCfg.GitHubHost, "github.com"), owner, repo, from, version)
+		}
+
+		log.SetPhase("tag")
+		if err := gitOps.CreateTag(ctx, version, "Release "+version+"\n\n"+notes); err != nil {
+			return err
+		}
+		if err := gitOps.PushTag(ctx, remote, version); err != nil {
+			// Leave nothing behind so the release can simply be run again
+			_ = gitOps.DeleteTag(ctx, version)
+			return err
+		}
+
+		log.SetPhase("release")
+		release, err := ghClient.CreateRelease(ctx, github.ReleaseInput{
+			Owner:      owner,
+			Repo:       repo,
+			Tag:        version,
+			Name:       version,
+			Body:       notes,
+			Draft:      releaseDraft,
+			Prerelease: releasePrerelease,
+		})
+		if err != nil {
+			logger.Warning("Tag %s is pushed; create the release for it on GitHub, or delete it with 'git tag -d %s && git push --delete %s %s' and run release again",
+				version, version, remote, version)
+			return err
+		}
+		if err := uploadReleaseAssets(ctx, ghClient, owner, repo, release, releaseAssets); err != nil {
+			return err
+		}
+
+		if !dryRun {
+			logger.Success("🔗 %s", release.URL)
+		}
+		logger.Result(map[string]interface{}{"ok": true, "tag": version, "url": release.URL, "assets": releaseAssets})
+		return nil
+	},
+}
+
+// uploadReleaseAssets uploads each asset to release, each within
+// releaseUploadTimeout; the command's own timeout is paused meanwhile
+func uploadReleaseAssets(ctx context.Context, ghClient *github.Client, owner, repo string, release *github.Release, assets []string) error {
+	if len(assets) == 0 {
+		return nil
+	}
+	defer pauseTimeout()()
+	for _, asset := range assets {
+		uploadCtx, cancel := context.WithTimeout(ctx, releaseUploadTimeout)
+		err := ghClient.UploadReleaseAsset(uploadCtx, owner, repo, release, asset)
+		timedOut := errors.Is(uploadCtx.Err(), context.DeadlineExceeded)
+		cancel()
+		if timedOut {
+			return fmt.Errorf("uploading %s timed out after %v", asset, releaseUploadTimeout)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repoCreatePrivate     bool
+	repoCreateDescription string
+	repoCreateLicense     string
+	repoCreateGitignore   string
+	repoCreateMessage     string
+	repoCreateNoPush      bool
+	repoCreateExisting    bool
+)
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoCreateCmd)
+
+	repoCreateCmd.Flags().BoolVar(&repoCreatePrivate, "private", false, "Create the repository as private")
+	repoCreateCmd.Flags().StringVar(&repoCreateDescription, "description", "", "Repository description")
+	repoCreateCmd.Flags().StringVar(&repoCreateLicense, "license", "", "License template, e.g. mit or apache-2.0")
+	_ = repoCreateCmd.RegisterFlagCompletionFunc("license", completeValues(commonLicenses...))
+	repoCreateCmd.Flags().StringVar(&repoCreateGitignore, "gitignore", "", ".gitignore template, e.g. Go or Node")
+	repoCreateCmd.Flags().StringVarP(&repoCreateMessage, "message", "m", "Initial commit", "Message for the initial commit when nothing is committed yet")
+	repoCreateCmd.Flags().BoolVar(&repoCreateNoPush, "no-push", false, "Create the repository and configure origin without pushing")
+	repoCreateCmd.Flags().BoolVar(&repoCreateExisting, "existing", false, "If the repository already exists on GitHub, set up origin and push to it instead of failing")
+}
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Create GitHub repositories",
+}
+
+var repoCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a GitHub repository for this directory and push it",
+	Long: `Create a GitHub repository, initialize git here if needed, point origin at
+the new repository and push the current branch. The name defaults to the
+directory name, which a terminal session asks to confirm. When nothing is
+committed yet, the working tree is committed first; with --license or
+--gitignore, the local branch is built on the commit GitHub creates for those
+files. A repository that already exists is an error, so nothing is pushed
+into it by mistake; --existing uses it instead.
+Example:
+  ghquick repo create
+  ghquick repo create my-tool --private --description "A small tool"
+  ghquick repo create --license mit --gitignore Go
+  ghquick repo create my-tool --existing   # created on github.com already`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		log.SetPhase("setup")
+		cfg, err := config.Load(tokenResolver())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+
+		name := filepath.Base(wd)
+		if len(args) > 0 {
+			name = args[0]
+		} else if isInteractive() {
+			if name, err = promptValue("Repository name", name); err != nil {
+				return err
+			}
+		}
+
+		result, err := publishRepository(ctx, cfg, fileCfg, wd, name, github.RepoOptions{
+			Private:           repoCreatePrivate,
+			Description:       repoCreateDescription,
+			License:           repoCreateLicense,
+			GitignoreTemplate: repoCreateGitignore,
+			Existing:          repoCreateExisting,
+		}, repoCreateMessage, repoCreateNoPush)
+		if err != nil || result == nil {
+			return err
+		}
+		logger.Result(result)
+		return nil
+	},
+}
+
+// publishRepository creates the user's GitHub repository name, initializes git
+// in wd if needed and points origin at it, then unless noPush makes the initial
+// commit with message when nothing is committed and pushes the branch. It
+// returns the fields of the command's result, or nil in dry-run mode.
+func publishRepository(ctx context.Context, cfg *config.Config, fileCfg *config.FileConfig, wd, name string, opts github.RepoOptions, message string, noPush bool) (map[string]interface{}, error) {
+	gitOps := newGitOps(wd)
+	gitOps.SetCredentials(cfg.GitHubUsername, cfg.GitHubToken)
+	gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+	scheme, err := git.ParseRemoteScheme(strutil.FirstNonEmpty(remoteScheme, fileCfg.RemoteScheme))
+	if err != nil {
+		return nil, err
+	}
+	gitOps.SetRemoteScheme(scheme, fileCfg.GitHubHost)
+	if fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "" {
+		gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+	}
+	ghClient := newGitHubClient(cfg.GitHubToken)
+	ghClient.SetRetryPolicy(retryPolicy(fileCfg))
+
+	if dryRun {
+		if err := ghClient.CreateRepo(ctx, name, opts); err != nil {
+			return nil, err
+		}
+		logger.Info("[dry-run] Would initialize git, point origin at %s/%s and push", cfg.GitHubUsername, name)
+		return nil, nil
+	}
+
+	log.SetPhase("create")
+	if err := ghClient.CreateRepo(ctx, name, opts); err != nil {
+		if errors.Is(err, github.ErrRepoExists) {
+			return nil, fmt.Errorf("%w; pass --existing to push to it", err)
+		}
+		return nil, err
+	}
+	applyIdentity(ctx, gitOps, fileCfg, wd, cfg.GitHubUsername+"/"+name)
+	if err := gitOps.EnsureGitSetup(ctx, name); err != nil {
+		return nil, fmt.Errorf("failed to setup git: %w", err)
+	}
+	if noPush {
+		return map[string]interface{}{"ok": true, "repo": cfg.GitHubUsername + "/" + name}, nil
+	}
+
+	log.SetPhase("push")
+	branch, err := gitOps.Bootstrap(ctx, "origin", message)
+	if err != nil {
+		return nil, err
+	}
+	if err := gitOps.Push(ctx, "origin", branch); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"ok": true, "repo": cfg.GitHubUsername + "/" + name, "branch": branch}, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saint/ghquick/internal/config"
+)
+
+// resolveWorkingDir returns the directory commands operate in: --dir when set,
+// the local clone named by --repo, otherwise the current directory
+func resolveWorkingDir() (string, error) {
+	if workDir != "" {
+		if repoSlug != "" {
+			return "", fmt.Errorf("--dir and --repo can't be used together")
+		}
+		dir, err := filepath.Abs(workDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve --dir %s: %w", workDir, err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("--dir %s is not a directory", workDir)
+		}
+		return dir, nil
+	}
+	if repoSlug == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		return wd, nil
+	}
+
+	globalCfg, err := config.LoadFile(configPath, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	return resolveRepoPath(repoSlug, globalCfg.CodeRoot)
+}
+
+// resolveRepoPath maps owner/name to a clone under codeRoot, trying the
+// GOPATH-style <root>/github.com/owner/name layout first, then <root>/owner/name
+func resolveRepoPath(slug, codeRoot string) (string, error) {
+	owner, name, ok := strings.Cut(slug, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid --repo %q (expected owner/name)", slug)
+	}
+
+	root, err := expandCodeRoot(codeRoot)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{
+		filepath.Join(root, "github.com", owner, name),
+		filepath.Join(root, owner, name),
+	}
+	for _, dir := range candidates {
+		if top, err := findRepoRoot(dir); err == nil && top == dir {
+			logger.Debug("Resolved %s to %s", slug, dir)
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no git repository for %s under %s (looked in %s)", slug, root, strings.Join(candidates, ", "))
+}
+
+// expandCodeRoot returns the configured code root with ~ expanded,
+// defaulting to $GOPATH/src and then ~/src
+func expandCodeRoot(codeRoot string) (string, error) {
+	if codeRoot == "" {
+		if gopath := os.Getenv("GOPATH"); gopath != "" {
+			return filepath.Join(filepath.SplitList(gopath)[0], "src"), nil
+		}
+		codeRoot = "~/src"
+	}
+	if codeRoot == "~" || strings.HasPrefix(codeRoot, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		codeRoot = filepath.Join(home, strings.TrimPrefix(codeRoot, "~"))
+	}
+	return filepath.Abs(codeRoot)
+}
+
+// findRepoRoot walks up from dir to the directory containing .git
+func findRepoRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current, nil
+		}
+		if filepath.Dir(current) == current {
+			return "", fmt.Errorf("%s is not inside a git repository", dir)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/saint/ghquick/internal/commit"
+	"github.com/spf13/cobra"
+)
+
+var rewordMsg string
+
+func init() {
+	rootCmd.AddCommand(rewordCmd)
+	rewordCmd.Flags().StringVarP(&rewordMsg, "message", "m", "", "New message for the last commit")
+	rewordCmd.MarkFlagRequired("message")
+}
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword",
+	Short: "Change the last commit's message without touching its files",
+	Long: `Replace the message of the last commit, leaving its tree untouched.
+Refuses to run while changes are staged, so nothing gets folded in by accident.
+Example:
+  ghquick reword -m "fix(auth): handle expired tokens"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		if fileCfg.Conventional {
+			if err := commit.ValidateConventional(rewordMsg); err != nil {
+				return err
+			}
+		}
+		return newGitOps(wd).Reword(ctx, rewordMsg)
+	},
+}
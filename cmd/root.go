@@ -1,21 +1,346 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/saint/ghquick/internal/auth"
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/retry"
+	"github.com/saint/ghquick/internal/strutil"
 	"github.com/spf13/cobra"
 )
 
+var (
+	configPath string
+	repoSlug   string
+	workDir    string
+	debug      bool
+	maxLogOut  int
+	noHints    bool
+	maxPar     int
+	outputFmt  string
+	jsonOutput bool
+	dryRun     bool
+	quiet      bool
+	tokenFlag  string
+	logLevel   string
+	logToFile  bool
+	logger     *log.Logger
+
+	networkTimeout time.Duration
+	localTimeout   time.Duration
+
+	// runCfg is the config PersistentPreRunE loaded for the working directory,
+	// for code that runs before a command has loaded its own
+	runCfg = &config.FileConfig{}
+)
+
+// ErrInterrupted is returned by Execute when Ctrl-C (SIGINT) or SIGTERM
+// stopped the command
+var ErrInterrupted = errors.New("interrupted")
+
+var (
+	// interruptCtx ends on the first Ctrl-C (SIGINT) or SIGTERM; commands run
+	// under contexts derived from it (see commandContext)
+	interruptCtx = context.Background()
+	// opsMu guards lastCtx, the context commandContext made last, which tells
+	// a command that ran out of time, and runOps, the git operations created for
+	// this run, which are rolled back if it's interrupted or times out
+	opsMu   sync.Mutex
+	lastCtx context.Context
+	runOps  []*git.Operations
+	// lastClock is the timeout of lastCtx, which pauseTimeout stops
+	lastClock *commandClock
+)
+
+// commandClock counts down a command's timeout; it can be paused for work
+// with a limit of its own, such as hooks
+type commandClock struct {
+	timer   *time.Timer
+	left    time.Duration
+	started time.Time
+	paused  bool
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "ghquick",
 	Short: "ghquick - Lightning fast GitHub operations with AI-powered automation",
 	Long: `ghquick is a CLI tool that automates GitHub operations with AI assistance.
 It optimizes for speed and developer experience, making git operations instant.`,
+	// main prints the error itself, with credentials redacted
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if jsonOutput {
+			if cmd.Flags().Changed("output") && outputFmt != string(log.FormatJSON) {
+				return fmt.Errorf("--json conflicts with --output %s", outputFmt)
+			}
+			outputFmt = string(log.FormatJSON)
+		}
+		format, err := log.ParseFormat(outputFmt)
+		if err != nil {
+			return err
+		}
+		log.SetFormat(format)
+		log.SetQuiet(quiet)
+		log.AddSecret(tokenFlag, os.Getenv(config.EnvGitHubToken), os.Getenv(config.EnvOpenAIKey), os.Getenv(config.EnvAnthropicKey))
+
+		// The config file is loaded again by each command, which reports its errors
+		fileCfg := &config.FileConfig{}
+		if wd, err := resolveWorkingDir(); err == nil {
+			if loaded, err := loadFileConfig(wd); err == nil {
+				fileCfg = loaded
+			}
+		}
+		runCfg = fileCfg
+		level, err := log.ParseLevel(strutil.FirstNonEmpty(logLevel, fileCfg.LogLevel))
+		if err != nil {
+			return err
+		}
+		if debug {
+			level = log.LevelDebug
+		}
+		debug = level == log.LevelDebug
+		if fileCfg.Timeout > 0 && !pushCmd.Flags().Changed("timeout") {
+			timeout = fileCfg.Timeout
+		}
+		networkTimeout = firstPositiveDuration(networkTimeout, fileCfg.NetworkTimeout)
+		localTimeout = firstPositiveDuration(localTimeout, fileCfg.LocalTimeout)
+		log.SetLevel(level)
+		logger = log.New(debug)
+		if logToFile || fileCfg.LogFile {
+			path, err := log.OpenFile("")
+			if err != nil {
+				logger.Warning("Not writing the log file: %v", err)
+			} else {
+				logger.Debug("Logging to %s", path)
+			}
+		}
+		logger.Debug("Running: %s", strings.Join(os.Args, " "))
+		git.SetMaxParallel(maxPar)
+		return nil
+	},
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	defer log.CloseFile()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	interruptCtx = ctx
+	// stop ends ctx too, which isn't an interrupt
+	defer context.AfterFunc(ctx, func() {
+		// Git gets the signal too; a second Ctrl-C quits without cleaning up
+		stop()
+		if logger != nil {
+			logger.Warning("Interrupted; stopping (press Ctrl-C again to quit at once)")
+		}
+	})
+
+	err := rootCmd.Execute()
+	interrupted := ctx.Err() != nil
+	opsMu.Lock()
+	timedOut := errors.Is(err, git.ErrTimeout) || (lastCtx != nil && errors.Is(context.Cause(lastCtx), context.DeadlineExceeded))
+	opsMu.Unlock()
+	if err != nil && (interrupted || timedOut) {
+		rollback()
+	} else {
+		opsMu.Lock()
+		for _, ops := range runOps {
+			ops.DiscardRollback()
+		}
+		opsMu.Unlock()
+	}
+	if err != nil && interrupted {
+		err = fmt.Errorf("%w: %v", ErrInterrupted, err)
+	}
+	// Scripts reading JSON output always get a final result line, even on failure
+	if logger != nil && !log.ResultWritten() {
+		if err != nil {
+			logger.Result(map[string]interface{}{"ok": false, "error": err.Error()})
+		} else {
+			logger.Result(map[string]interface{}{"ok": true})
+		}
+	}
+	return err
+}
+
+// rollbackTimeout bounds putting the repository back after an interrupted run
+const rollbackTimeout = 30 * time.Second
+
+// rollback puts back what the git operations of a run that stopped halfway
+// changed (see git.Operations.Rollback)
+func rollback() {
+	// The run's own context is already cancelled, so start afresh
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+	opsMu.Lock()
+	defer opsMu.Unlock()
+	for _, ops := range runOps {
+		if err := ops.Rollback(ctx); err != nil && logger != nil {
+			logger.Warning("The repository may be left partly changed: %v", err)
+		}
+	}
+}
+
+// commandContext returns the context a command runs under, which ends after d
+// (not counting time paused with pauseTimeout), with context.DeadlineExceeded
+// as its cause, or when the user interrupts it
+func commandContext(d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(interruptCtx)
+	clock := &commandClock{left: d, started: time.Now()}
+	clock.timer = time.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+	opsMu.Lock()
+	lastCtx, lastClock = ctx, clock
+	opsMu.Unlock()
+	return ctx, func() {
+		clock.timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// pauseTimeout stops the running command's timeout until resume is called, so
+// work with a limit of its own doesn't use up the command's
+func pauseTimeout() (resume func()) {
+	opsMu.Lock()
+	defer opsMu.Unlock()
+	clock := lastClock
+	if clock == nil || clock.paused || !clock.timer.Stop() {
+		// Nothing running, already paused, or already out of time
+		return func() {}
+	}
+	clock.paused = true
+	clock.left -= time.Since(clock.started)
+	return func() {
+		opsMu.Lock()
+		defer opsMu.Unlock()
+		clock.paused = false
+		clock.started = time.Now()
+		clock.timer.Reset(clock.left)
+	}
 }
 
 func init() {
-	// Global flags can be added here
-	rootCmd.PersistentFlags().StringP("config", "c", "", "config file (default is $HOME/.ghquick.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "config file (default is $HOME/.ghquick/config.toml if it exists, else $HOME/.ghquick.yaml)")
+	rootCmd.PersistentFlags().StringVar(&workDir, "dir", "", "Run in this directory instead of the current one")
+	rootCmd.PersistentFlags().StringVar(&repoSlug, "repo", "", "Operate on the local clone of owner/name under code_root")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug logging (same as --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Least severe messages shown: debug, info (default), warn, or error")
+	_ = rootCmd.RegisterFlagCompletionFunc("log-level", completeValues(string(log.LevelDebug), string(log.LevelInfo), string(log.LevelWarn), string(log.LevelError)))
+	rootCmd.PersistentFlags().BoolVar(&logToFile, "log-file", false, "Also record everything, debug messages included, in ~/.ghquick/logs/ghquick.log")
+	rootCmd.PersistentFlags().IntVar(&maxPar, "max-parallel", 0, "Maximum git commands run at once (default: number of CPUs)")
+	rootCmd.PersistentFlags().StringVar(&outputFmt, "output", "text", "Output format: text, or json for one event object per line")
+	_ = rootCmd.RegisterFlagCompletionFunc("output", completeValues(string(log.FormatText), string(log.FormatJSON)))
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Same as --output json")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the git commands and GitHub API calls that would change anything instead of running them")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Don't show git's output and transfer progress while it runs")
+	rootCmd.PersistentFlags().StringVar(&tokenFlag, "token", "", "GitHub token (default: GITHUB_TOKEN, then 'gh auth token', then the keychain)")
+	rootCmd.PersistentFlags().BoolVar(&noHints, "no-hints", false, "Don't print a suggested next command")
+	rootCmd.PersistentFlags().IntVar(&maxLogOut, "max-log-output", git.DefaultMaxLogLines, "Maximum lines of command output shown in debug logs (0 for no limit)")
+	rootCmd.PersistentFlags().DurationVar(&networkTimeout, "network-timeout", 0, "Give up on a git command that talks to a remote after this long, per attempt (default: no limit)")
+	rootCmd.PersistentFlags().DurationVar(&localTimeout, "local-timeout", 0, "Give up on any other git command after this long (default: no limit)")
+}
+
+// newGitOps creates git operations for dir using the global logging, dry-run,
+// --no-verify and timeout flags, rolled back if the run is interrupted
+func newGitOps(dir string) *git.Operations {
+	ops := git.NewOperations(dir, debug)
+	ops.SetMaxLogOutput(maxLogOut)
+	ops.SetDryRun(dryRun)
+	ops.SetNoVerify(noVerify)
+	ops.SetTokenSource(tokenResolver().Get)
+	ops.SetTimeouts(networkTimeout, localTimeout)
+	applyBackend(ops, runCfg)
+	opsMu.Lock()
+	runOps = append(runOps, ops)
+	opsMu.Unlock()
+	return ops
+}
+
+// applyBackend switches ops to the git_backend configured in fileCfg
+func applyBackend(ops *git.Operations, fileCfg *config.FileConfig) {
+	if fileCfg.GitBackend == "" {
+		return
+	}
+	kind, err := git.ParseBackend(fileCfg.GitBackend)
+	if err != nil {
+		logger.Warning("%v; using the git binary", err)
+		return
+	}
+	ops.SetBackend(kind)
+}
+
+// newGitHubClient creates an API client for the configured github_host that
+// honours the global dry-run flag. The token is redacted from all output.
+func newGitHubClient(token string) *github.Client {
+	log.AddSecret(token)
+	client := github.NewClient(token, debug)
+	if err := client.SetHost(githubHost()); err != nil {
+		logger.Warning("%v; using api.github.com", err)
+	}
+	client.SetDryRun(dryRun)
+	return client
+}
+
+// retryPolicy builds the retry policy for network operations from the config
+func retryPolicy(fileCfg *config.FileConfig) retry.Policy {
+	return retry.Policy{
+		Attempts:   fileCfg.RetryAttempts,
+		Backoff:    fileCfg.RetryBackoff,
+		MaxBackoff: fileCfg.RetryMaxBackoff,
+		Jitter:     fileCfg.RetryJitter,
+	}
+}
+
+// applyIdentity makes EnsureGitSetup write the identities entry matching the
+// repository: origin's owner/name when it is set, otherwise fallbackSlug
+func applyIdentity(ctx context.Context, gitOps *git.Operations, fileCfg *config.FileConfig, wd, fallbackSlug string) {
+	slug := fallbackSlug
+	if owner, name, err := gitOps.RemoteRepo(ctx, "origin"); err == nil {
+		slug = owner + "/" + name
+	}
+	if id := fileCfg.IdentityFor(slug, wd); id != nil {
+		logger.Debug("Using identity %s <%s> for %s", id.Name, id.Email, slug)
+		gitOps.SetGitUser(id.Name, id.Email)
+	}
+}
+
+// tokenResolver looks up the GitHub token: --token, GITHUB_TOKEN, the gh
+// CLI's login for the configured github_host, then the OS keychain
+func tokenResolver() *auth.Resolver {
+	r := auth.NewResolver(tokenFlag, auth.NewKeychainStore())
+	r.SetHost(githubHost())
+	return r
+}
+
+// githubHost returns the github_host setting, or "" for github.com. It is
+// global-only, so the config loaded for the working directory has it for every
+// repository the run touches.
+func githubHost() string {
+	return runCfg.GitHubHost
+}
+
+// loadFileConfig loads the global config file and the repository's
+// .ghquick.yaml or .ghquick.toml, warning about global-only settings the latter tried to set
+func loadFileConfig(repoDir string) (*config.FileConfig, error) {
+	fileCfg, err := config.LoadFile(configPath, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	if logger != nil {
+		for _, key := range fileCfg.Ignored {
+			logger.Warning("Ignoring %s in %s: only the global config can set it", key, filepath.Base(config.RepoConfigPath(repoDir)))
+		}
+	}
+	return fileCfg, nil
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/saint/ghquick/internal/commit"
+	"github.com/spf13/cobra"
+)
+
+var squashMsg string
+
+func init() {
+	rootCmd.AddCommand(squashCmd)
+	squashCmd.Flags().StringVarP(&squashMsg, "message", "m", "", "Message for the combined commit")
+	squashCmd.MarkFlagRequired("message")
+}
+
+var squashCmd = &cobra.Command{
+	Use:   "squash <n>",
+	Short: "Combine the last n commits into one",
+	Long: `Replace the last n commits with a single commit of their combined changes.
+Refuses to run when any of them is a merge commit, when changes are staged, or
+when the commits were already pushed, since squashing them would need a
+force-push.
+Example:
+  ghquick squash 3 -m "feat(auth): add token refresh"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid commit count %q", args[0])
+		}
+
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+		if fileCfg.Conventional {
+			if err := commit.ValidateConventional(squashMsg); err != nil {
+				return err
+			}
+		}
+		gitOps := newGitOps(wd)
+		if n > 1 {
+			oldest := "HEAD~" + strconv.Itoa(n-1)
+			pushed, err := gitOps.IsPushed(ctx, oldest)
+			if err != nil {
+				return err
+			}
+			if pushed {
+				return fmt.Errorf("refusing to squash: %s is already pushed, so squashing would need a force-push", oldest)
+			}
+		}
+		if err := gitOps.Squash(ctx, n, squashMsg); err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "squashed": n})
+		return nil
+	},
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stashPaths       []string
+	stashTrackedOnly bool
+)
+
+func init() {
+	rootCmd.AddCommand(stashCmd)
+	stashCmd.AddCommand(stashSaveCmd, stashListCmd, stashPopCmd, stashApplyCmd, stashDropCmd)
+	for _, c := range []*cobra.Command{stashCmd, stashSaveCmd} {
+		c.Flags().StringSliceVar(&stashPaths, "paths", nil, "Stash only these paths")
+		c.Flags().BoolVar(&stashTrackedOnly, "tracked-only", false, "Leave untracked files in the working tree")
+	}
+}
+
+var stashCmd = &cobra.Command{
+	Use:   "stash [name]",
+	Short: "Save, list and restore named stashes",
+	Long: `Save local changes, untracked files included, as a named stash entry and
+restore them later by name. 'ghquick stash <name>' is short for 'stash save'.
+'branch switch', 'branch create' and 'checkout' stash and restore local changes
+on their own, and 'sync' lets git do the same around the rebase or merge.
+Example:
+  ghquick stash wip-login              # Save everything as "wip-login"
+  ghquick stash list
+  ghquick stash pop wip-login          # Restore it and drop it from the stash
+  ghquick stash apply 1                # Restore stash@{1}, keeping it
+  ghquick stash drop wip-login`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStashSave,
+}
+
+var stashSaveCmd = &cobra.Command{
+	Use:   "save [name]",
+	Short: "Stash local changes under a name",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runStashSave,
+}
+
+var stashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stash entries, most recent first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, err := stashSetup()
+		if err != nil {
+			return err
+		}
+		entries, err := gitOps.ListStashes(ctx)
+		if err != nil {
+			return err
+		}
+		if !log.JSON() {
+			if len(entries) == 0 {
+				logger.Info("The stash is empty")
+			}
+			for _, e := range entries {
+				fmt.Printf("%s  %s (on %s, %s)\n", e.Ref, e.Name, e.Branch, e.Time.Local().Format("2006-01-02 15:04"))
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "stashes": entries})
+		return nil
+	},
+}
+
+var stashPopCmd = &cobra.Command{
+	Use:               "pop [name]",
+	Short:             "Restore a stash entry (the most recent by default) and drop it",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: singleArg(completeStashes),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restoreStash(args, true)
+	},
+}
+
+var stashApplyCmd = &cobra.Command{
+	Use:               "apply [name]",
+	Short:             "Restore a stash entry (the most recent by default) and keep it",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: singleArg(completeStashes),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restoreStash(args, false)
+	},
+}
+
+var stashDropCmd = &cobra.Command{
+	Use:               "drop <name>",
+	Short:             "Delete a stash entry",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: singleArg(completeStashes),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, err := stashSetup()
+		if err != nil {
+			return err
+		}
+		entry, err := gitOps.FindStash(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if err := gitOps.DropStash(ctx, entry); err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "dropped": entry})
+		return nil
+	},
+}
+
+func runStashSave(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(timeout)
+	defer cancel()
+
+	gitOps, err := stashSetup()
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+	stashed, err := gitOps.SaveStash(ctx, name, stashTrackedOnly, stashPaths)
+	if err != nil {
+		return err
+	}
+	if !stashed {
+		logger.Info("No local changes to stash")
+	}
+	logger.Result(map[string]interface{}{"ok": true, "stashed": stashed, "name": name})
+	return nil
+}
+
+// restoreStash applies the named stash entry, or the most recent one, and with
+// drop removes it from the stash
+func restoreStash(args []string, drop bool) error {
+	ctx, cancel := commandContext(timeout)
+	defer cancel()
+
+	gitOps, err := stashSetup()
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+	entry, err := gitOps.FindStash(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := gitOps.ApplyStash(ctx, entry, drop); err != nil {
+		return err
+	}
+	logger.Result(map[string]interface{}{"ok": true, "restored": entry, "dropped": drop})
+	return nil
+}
+
+// stashSetup resolves the working directory for the stash commands
+func stashSetup() (*git.Operations, error) {
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	return newGitOps(wd), nil
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/config"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/github"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var statusLocal bool
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusLocal, "local", false, "Only show local state, without asking GitHub about pull requests and CI")
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the branch, local changes, pull request, CI and last ghquick push at a glance",
+	Long: `Show the current branch and how far it is ahead of or behind its upstream, how
+many files are staged, unstaged and untracked, the stash, the branch's open pull
+request and the CI checks on its pushed commit, and what the last ghquick push did
+and whether it can be undone. --local skips the GitHub lookups.
+Example:
+  ghquick status
+  ghquick status --local
+  ghquick status --json | jq .result`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		gitOps := newGitOps(wd)
+
+		result := map[string]interface{}{"ok": true}
+		var rows [][2]string
+		add := func(label, format string, args ...interface{}) {
+			rows = append(rows, [2]string{label, fmt.Sprintf(format, args...)})
+		}
+
+		// Local state
+		var branch, upstream string
+		if branch, err = gitOps.CurrentBranch(ctx); err != nil {
+			if head, err := gitOps.HeadCommit(ctx); err == nil {
+				add("Branch", "none, HEAD is detached at %s", shortSHA(head))
+			} else {
+				add("Branch", "none, no commits yet")
+			}
+		}
+		result["branch"] = branch
+		if branches, err := gitOps.ListBranches(ctx); err == nil && branch != "" {
+			for _, b := range branches {
+				if !b.Current {
+					continue
+				}
+				upstream = b.Upstream
+				result["upstream"], result["ahead"], result["behind"] = b.Upstream, b.Ahead, b.Behind
+				switch {
+				case b.Upstream == "":
+					add("Branch", "%s (no upstream)", branch)
+				case b.Gone:
+					add("Branch", "%s (%s, gone from the remote)", branch, b.Upstream)
+				case b.Ahead == 0 && b.Behind == 0:
+					add("Branch", "%s (up to date with %s)", branch, b.Upstream)
+				default:
+					add("Branch", "%s (%s, ahead %d, behind %d)", branch, b.Upstream, b.Ahead, b.Behind)
+				}
+			}
+		}
+
+		files, err := gitOps.GetStatus(ctx)
+		if err != nil {
+			return err
+		}
+		var staged, unstaged, untracked int
+		for _, f := range files {
+			// A file can have staged and unstaged changes at once
+			if f.IsUntracked() {
+				untracked++
+				continue
+			}
+			if f.IsStaged() {
+				staged++
+			}
+			if f.IsUnstaged() {
+				unstaged++
+			}
+		}
+		result["staged"], result["unstaged"], result["untracked"] = staged, unstaged, untracked
+		if len(files) == 0 {
+			add("Changes", "none")
+		} else {
+			add("Changes", "%d staged, %d unstaged, %d untracked", staged, unstaged, untracked)
+		}
+		if stashes, err := gitOps.StashCount(ctx); err == nil {
+			result["stashes"] = stashes
+			add("Stashes", "%d", stashes)
+		}
+
+		// Remote state
+		if !statusLocal && branch != "" {
+			remoteStatus(ctx, gitOps, fileCfg, branch, upstream, result, add)
+		}
+
+		// The last ghquick operation
+		op, err := gitOps.LastOperation(ctx)
+		if err != nil {
+			return err
+		}
+		result["operation"] = op
+		var hint string
+		if op == nil {
+			add("Last push", "none recorded")
+		} else {
+			action := "Committed"
+			if op.Amended {
+				action = "Amended"
+			}
+			where := "not pushed"
+			if op.Pushed() {
+				where = fmt.Sprintf("pushed to %s (%s)", strings.Join(op.Remotes, ", "), op.RemoteBranch)
+			}
+			add("Last push", "%s %s on %s at %s, %s", action, shortSHA(op.SHA), op.Branch, op.Time.Local().Format("2006-01-02 15:04:05"), where)
+
+			head, _ := gitOps.HeadCommit(ctx)
+			undoable := head == op.SHA && op.Parent != ""
+			result["undoable"] = undoable
+			switch {
+			case head != op.SHA:
+				hint = fmt.Sprintf("HEAD has moved to %s since; only 'ghquick undo --revert' can reverse it now", shortSHA(head))
+			case undoable && op.Pushed():
+				hint = "Run 'ghquick undo --revert' to push a commit reversing it, or 'ghquick undo --force' to remove it"
+			case undoable:
+				hint = "Run 'ghquick undo' to reverse it"
+			}
+		}
+
+		if !log.JSON() {
+			for _, row := range rows {
+				fmt.Printf("%-14s %s\n", row[0]+":", row[1])
+			}
+			if hint != "" {
+				logger.Info("%s", hint)
+			}
+		}
+		logger.Result(result)
+		return nil
+	},
+}
+
+// remoteStatus adds the branch's open pull request and the CI checks on its
+// pushed commit. GitHub being unreachable, or no token, only leaves them out.
+func remoteStatus(ctx context.Context, gitOps *git.Operations, fileCfg *config.FileConfig, branch, upstream string, result map[string]interface{}, add func(label, format string, args ...interface{})) {
+	cfg, err := config.LoadGitHub(tokenResolver())
+	if err != nil {
+		logger.Debug("Skipping GitHub status: %v", err)
+		add("GitHub", "unavailable (%v)", err)
+		return
+	}
+	// A status check shouldn't sit through retries; it just shows less
+	policy := retryPolicy(fileCfg)
+	policy.Attempts = 1
+	ghClient := newGitHubClient(cfg.GitHubToken)
+	ghClient.SetRetryPolicy(policy)
+
+	pr, err := statusPullRequest(ctx, gitOps, ghClient, branch)
+	switch {
+	case err != nil:
+		logger.Debug("Could not look up the pull request: %v", err)
+		add("Pull request", "unavailable")
+	case pr == nil:
+		result["pull_request"] = nil
+		add("Pull request", "none open")
+	case pr.Draft:
+		result["pull_request"] = pr
+		add("Pull request", "#%d %s (draft) %s", pr.Number, pr.Title, pr.URL)
+	default:
+		result["pull_request"] = pr
+		add("Pull request", "#%d %s %s", pr.Number, pr.Title, pr.URL)
+	}
+
+	// CI runs on what was pushed, so the checks are those of the upstream's tip
+	if upstream == "" {
+		add("CI", "branch not pushed")
+		return
+	}
+	remote, _, _ := strings.Cut(upstream, "/")
+	sha, err := gitOps.ResolveCommit(ctx, upstream)
+	if err != nil {
+		add("CI", "unavailable")
+		return
+	}
+	owner, repo, err := gitOps.RemoteRepo(ctx, remote)
+	if err != nil {
+		add("CI", "unavailable")
+		return
+	}
+	runs, err := ghClient.ListCheckRuns(ctx, owner, repo, sha)
+	if err != nil {
+		logger.Debug("Could not list the checks: %v", err)
+		add("CI", "unavailable")
+		return
+	}
+	var failed, pending []string
+	for _, r := range runs {
+		switch {
+		case r.Failed():
+			failed = append(failed, r.Name)
+		case !r.Completed():
+			pending = append(pending, r.Name)
+		}
+	}
+	state := "success"
+	switch {
+	case len(runs) == 0:
+		state = "none"
+		add("CI", "no checks on %s", shortSHA(sha))
+	case len(failed) > 0:
+		state = "failure"
+		add("CI", "%d of %d checks failed on %s: %s", len(failed), len(runs), shortSHA(sha), strings.Join(failed, ", "))
+	case len(pending) > 0:
+		state = "pending"
+		add("CI", "%d of %d checks running on %s", len(pending), len(runs), shortSHA(sha))
+	default:
+		add("CI", "all %d checks passed on %s", len(runs), shortSHA(sha))
+	}
+	result["checks"] = map[string]interface{}{"sha": sha, "state": state, "runs": runs}
+}
+
+// statusPullRequest finds the branch's open pull request where 'ghquick pr'
+// would open it: in upstream's repository when there is one, else origin's
+func statusPullRequest(ctx context.Context, gitOps *git.Operations, ghClient *github.Client, branch string) (*github.PullRequest, error) {
+	headOwner, _, err := gitOps.RemoteRepo(ctx, "origin")
+	if err != nil {
+		return nil, err
+	}
+	target := "origin"
+	if gitOps.HasRemote(ctx, "upstream") {
+		target = "upstream"
+	}
+	owner, repo, err := gitOps.RemoteRepo(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return ghClient.FindOpenPullRequest(ctx, owner, repo, headOwner+":"+branch)
+}
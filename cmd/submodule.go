@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	submoduleMessage  string
+	submoduleNoCommit bool
+)
+
+func init() {
+	rootCmd.AddCommand(submoduleCmd)
+	submoduleCmd.AddCommand(submoduleSyncCmd)
+	submoduleSyncCmd.Flags().StringVarP(&submoduleMessage, "message", "m", "", "Commit message (defaults to one listing each submodule's old and new commit)")
+	submoduleSyncCmd.Flags().BoolVar(&submoduleNoCommit, "no-commit", false, "Update the submodules but leave the new pointers uncommitted")
+}
+
+var submoduleCmd = &cobra.Command{
+	Use:   "submodule",
+	Short: "Work with submodules",
+	Long: `Work with the repository's submodules. push and repo create initialize
+submodules that aren't checked out yet, and staging warns about changes inside
+a submodule, which the parent's commit doesn't include.`,
+}
+
+var submoduleSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update submodules to their tracked branches and commit the new pointers",
+	Long: `Check out every submodule at the tip of the branch it tracks (branch in
+.gitmodules, else the remote's default branch), and its own submodules at the
+commits it records, then commit the moved pointers. Only the submodules are
+committed; anything else staged stays staged. A submodule with uncommitted
+changes stops the sync.
+Example:
+  ghquick submodule sync
+  ghquick submodule sync -m "chore: pick up the new API client"
+  ghquick submodule sync --no-commit && ghquick push`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		log.SetPhase("setup")
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		gitOps := newGitOps(wd)
+		if !gitOps.HasSubmodules(ctx) {
+			logger.Info("No submodules in this repository")
+			logger.Result(map[string]interface{}{"ok": true, "updated": []git.SubmoduleBump{}, "committed": false})
+			return nil
+		}
+		previous, _ := gitOps.HeadCommit(ctx)
+		branch := ""
+		if !submoduleNoCommit {
+			// Fail before moving the submodules if there's no branch to commit to
+			if branch, err = gitOps.CurrentBranch(ctx); err != nil {
+				return err
+			}
+		}
+
+		log.SetPhase("fetch")
+		bumps, err := gitOps.SyncSubmodules(ctx)
+		if err != nil {
+			return err
+		}
+		if bumps == nil {
+			bumps = []git.SubmoduleBump{}
+		}
+		for _, b := range bumps {
+			logger.Info("%s: %.7s -> %.7s", b.Path, b.From, b.To)
+		}
+		if len(bumps) == 0 || submoduleNoCommit || dryRun {
+			logger.Result(map[string]interface{}{"ok": true, "updated": bumps, "committed": false})
+			return nil
+		}
+
+		log.SetPhase("commit")
+		if err := gitOps.CommitSubmoduleBumps(ctx, bumps, submoduleMessage); err != nil {
+			return err
+		}
+		sha, _ := gitOps.HeadCommit(ctx)
+		recordOperation(ctx, gitOps, git.Operation{SHA: sha, Parent: previous, Branch: branch, Time: time.Now()})
+		logger.Result(map[string]interface{}{"ok": true, "updated": bumps, "committed": true, "sha": sha, "branch": branch})
+		return nil
+	},
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncStrategy        string
+	syncAbortOnConflict bool
+	syncAbort           bool
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVar(&syncStrategy, "strategy", "", "How to bring in upstream commits: rebase (default) or merge")
+	syncCmd.Flags().BoolVar(&syncAbortOnConflict, "abort-on-conflict", false, "Abort and leave the branch unchanged when there are conflicts")
+	syncCmd.Flags().BoolVar(&syncAbort, "abort", false, "Abort a sync stopped on conflicts")
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Bring the current branch up to date with its upstream",
+	Long: `Fetch, then rebase or merge the current branch onto its upstream (or the
+branch of the same name on origin). Local changes are stashed and restored.
+On conflicts the conflicting files are listed and the rebase or merge is left
+for you to resolve, unless --abort-on-conflict is given.
+Example:
+  ghquick sync                     # Rebase onto the upstream
+  ghquick sync --strategy merge
+  ghquick sync --abort             # Give up on a sync stopped on conflicts`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return err
+		}
+		gitOps := newGitOps(wd)
+		gitOps.SetAutoPrune(fileCfg.AutoPrune)
+		gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+
+		if syncAbort {
+			return gitOps.AbortSync(ctx)
+		}
+
+		strategy, err := git.ParseSyncStrategy(strutil.FirstNonEmpty(syncStrategy, fileCfg.SyncStrategy))
+		if err != nil {
+			return err
+		}
+		remote, branch, err := gitOps.Upstream(ctx)
+		if err != nil {
+			if branch, err = gitOps.CurrentBranch(ctx); err != nil {
+				return err
+			}
+			remote = strutil.FirstNonEmpty(fileCfg.Remote, "origin")
+			logger.Debug("No upstream configured; syncing with %s/%s", remote, branch)
+		}
+
+		err = gitOps.Sync(ctx, remote, branch, strategy, syncAbortOnConflict)
+		var conflict *git.SyncConflictError
+		if errors.As(err, &conflict) {
+			logger.Result(map[string]interface{}{
+				"ok":        false,
+				"error":     err.Error(),
+				"upstream":  conflict.Upstream,
+				"strategy":  conflict.Strategy,
+				"conflicts": conflict.Files,
+				"aborted":   conflict.Aborted,
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "upstream": remote + "/" + branch, "strategy": strategy})
+		return nil
+	},
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagMessage     string
+	tagLightweight bool
+	tagPush        bool
+	tagForce       bool
+	tagRemote      bool
+	tagAll         bool
+)
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagCreateCmd, tagListCmd, tagDeleteCmd, tagPushCmd)
+
+	tagCreateCmd.Flags().StringVarP(&tagMessage, "message", "m", "", "Message for the annotated tag (defaults to the tag name)")
+	tagCreateCmd.Flags().BoolVar(&tagLightweight, "lightweight", false, "Create a lightweight tag, a plain ref without a message")
+	tagCreateCmd.Flags().BoolVar(&tagPush, "push", false, "Push the tag once it is created")
+	tagCreateCmd.Flags().BoolVar(&tagForce, "force", false, "Move the tag if it already exists (and overwrite the remote's with --push)")
+	tagDeleteCmd.Flags().BoolVar(&tagRemote, "remote", false, "Delete the tags on the remote too")
+	tagPushCmd.Flags().BoolVar(&tagAll, "all", false, "Push every local tag")
+	tagPushCmd.Flags().BoolVar(&tagForce, "force", false, "Overwrite tags the remote already has with a different target")
+}
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Create, list, delete and push tags",
+	Long: `Manage tags. Tags are annotated unless --lightweight is given, and nothing is
+pushed unless asked: use --push, 'tag push', or 'push --follow-tags'.
+Example:
+  ghquick tag create v1.4.0 -m "First stable API" --push
+  ghquick tag create nightly --lightweight --force
+  ghquick tag list 'v1.*'
+  ghquick tag delete v1.4.0-rc.1 --remote
+  ghquick tag push v1.4.0
+  ghquick tag push --all`,
+}
+
+var tagCreateCmd = &cobra.Command{
+	Use:   "create <name> [commit]",
+	Short: "Tag a commit (HEAD by default)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, remote, err := tagSetup()
+		if err != nil {
+			return err
+		}
+		name, rev := args[0], "HEAD"
+		if len(args) > 1 {
+			rev = args[1]
+		}
+		if tagLightweight && tagMessage != "" {
+			return fmt.Errorf("--message needs an annotated tag; drop --lightweight")
+		}
+		if err := gitOps.CreateTagAt(ctx, name, rev, strutil.FirstNonEmpty(tagMessage, name), tagLightweight, tagForce); err != nil {
+			return err
+		}
+		if tagPush {
+			if err := gitOps.PushTags(ctx, remote, []string{name}, tagForce); err != nil {
+				logger.Warning("Tag %s was created locally; push it with 'ghquick tag push %s'", name, name)
+				return err
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "tag": name, "pushed": tagPush})
+		return nil
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [pattern...]",
+	Short: "List tags with their dates and messages, newest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, _, err := tagSetup()
+		if err != nil {
+			return err
+		}
+		tags, err := gitOps.ListTags(ctx, args...)
+		if err != nil {
+			return err
+		}
+		if !log.JSON() {
+			if len(tags) == 0 {
+				logger.Info("No tags")
+			}
+			width := 0
+			for _, t := range tags {
+				width = max(width, len(t.Name))
+			}
+			for _, t := range tags {
+				kind := ""
+				if !t.Annotated {
+					kind = " (lightweight)"
+				}
+				fmt.Printf("%-*s  %s  %s  %s%s\n", width, t.Name, t.Date.Local().Format("2006-01-02"), shortSHA(t.Commit), t.Message, kind)
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "tags": tags})
+		return nil
+	},
+}
+
+var tagDeleteCmd = &cobra.Command{
+	Use:               "delete <name...>",
+	Short:             "Delete tags locally, and with --remote on the remote",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		gitOps, remote, err := tagSetup()
+		if err != nil {
+			return err
+		}
+		for _, name := range args {
+			// A tag deleted locally but still on the remote comes back with the next
+			// fetch, so the remote goes first
+			if tagRemote {
+				if err := gitOps.DeleteRemoteTag(ctx, remote, name); err != nil {
+					return err
+				}
+			}
+			if !gitOps.TagExists(ctx, name) {
+				if !tagRemote {
+					return fmt.Errorf("no tag %s", name)
+				}
+				continue
+			}
+			if err := gitOps.DeleteTag(ctx, name); err != nil {
+				return err
+			}
+			if !dryRun {
+				logger.Success("Deleted tag %s", name)
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "deleted": args, "remote": tagRemote})
+		return nil
+	},
+}
+
+var tagPushCmd = &cobra.Command{
+	Use:               "push [name...]",
+	Short:             "Push tags to the remote (origin, or remote: in .ghquick.yaml)",
+	ValidArgsFunction: completeTags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		if len(args) == 0 && !tagAll {
+			return fmt.Errorf("name the tags to push, or pass --all")
+		}
+		if len(args) > 0 && tagAll {
+			return fmt.Errorf("--all pushes every tag; don't name any")
+		}
+		gitOps, remote, err := tagSetup()
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, name := range args {
+			if !gitOps.TagExists(ctx, name) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("no such tag: %s", strings.Join(missing, ", "))
+		}
+		if err := gitOps.PushTags(ctx, remote, args, tagForce); err != nil {
+			return err
+		}
+		logger.Result(map[string]interface{}{"ok": true, "pushed": args, "all": tagAll, "remote": remote})
+		return nil
+	},
+}
+
+// tagSetup resolves the working directory and the remote tags are pushed to
+func tagSetup() (*git.Operations, string, error) {
+	wd, err := resolveWorkingDir()
+	if err != nil {
+		return nil, "", err
+	}
+	fileCfg, err := loadFileConfig(wd)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	gitOps := newGitOps(wd)
+	gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+	if fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "" {
+		gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+	}
+	return gitOps, strutil.FirstNonEmpty(fileCfg.Remote, "origin"), nil
+}
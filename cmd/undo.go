@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/commit"
+	"github.com/saint/ghquick/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoYes    bool
+	undoForce  bool
+	undoRevert bool
+)
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().BoolVar(&undoRevert, "revert", false, "Add and push a commit that reverts ghquick's commit instead of removing it")
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Allow force-pushing the branch back when the commit was already pushed")
+	undoCmd.Flags().BoolVarP(&undoYes, "yes", "y", false, "With --force, force-push without asking")
+	undoCmd.MarkFlagsMutuallyExclusive("revert", "force")
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last commit ghquick made, keeping its changes staged",
+	Long: `Undo the commit made by the last ghquick push.
+
+By default the commit is soft-reset, leaving its changes staged (an amend is
+undone by restoring the commit as it was before). Refuses to run if HEAD has
+moved since, so other work is never lost.
+
+If the commit was already pushed, removing it rewrites the remote branch, so
+undo refuses unless one of these is given:
+  --revert   add a commit that reverses it and push that (safe for shared branches)
+  --force    force-push the branch back to its state before the push, after
+             confirmation, and only while the remote still points at the commit
+Example:
+  ghquick status      # Show what the last run did
+  ghquick undo
+  ghquick undo --revert`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(timeout)
+		defer cancel()
+
+		wd, err := resolveWorkingDir()
+		if err != nil {
+			return err
+		}
+		fileCfg, err := loadFileConfig(wd)
+		if err != nil {
+			return err
+		}
+		gitOps := newGitOps(wd)
+		gitOps.SetRetryPolicy(retryPolicy(fileCfg))
+		if fileCfg.AuthorName != "" || fileCfg.AuthorEmail != "" {
+			gitOps.SetCommitIdentity(fileCfg.AuthorName, fileCfg.AuthorEmail)
+		}
+
+		op, err := gitOps.LastOperation(ctx)
+		if err != nil {
+			return err
+		}
+		if op == nil {
+			return fmt.Errorf("no ghquick operation recorded in this repository; nothing to undo")
+		}
+
+		head, err := gitOps.HeadCommit(ctx)
+		if err != nil {
+			return err
+		}
+		branch, err := gitOps.CurrentBranch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if undoRevert {
+			return undoByRevert(ctx, gitOps, op, branch, fileCfg.Conventional)
+		}
+
+		if head != op.SHA || branch != op.Branch {
+			logger.Error("HEAD has moved since ghquick committed %s on %s", shortSHA(op.SHA), op.Branch)
+			return fmt.Errorf("refusing to undo: HEAD is %s on %s, not ghquick's commit; undo by hand to avoid losing other work, or use --revert", shortSHA(head), branch)
+		}
+		if op.Parent == "" {
+			return fmt.Errorf("ghquick's commit is the first in the repository; there is no parent to reset to")
+		}
+
+		if op.Pushed() {
+			logger.Warning("%s was pushed to %s; undoing it rewrites %s there", shortSHA(op.SHA), strings.Join(op.Remotes, ", "), op.RemoteBranch)
+			if !undoForce {
+				return fmt.Errorf("refusing to rewrite a pushed branch; pass --revert to push a commit that reverses it, or --force to force-push the branch back")
+			}
+			if !undoYes && !dryRun {
+				if !isInteractive() {
+					return fmt.Errorf("refusing to force-push without confirmation; pass --yes")
+				}
+				answer, err := readLine("Force-push the undone state? [y/N]: ")
+				if err != nil {
+					return err
+				}
+				if a := strings.ToLower(answer); a != "y" && a != "yes" {
+					return fmt.Errorf("undo cancelled; nothing was changed")
+				}
+			}
+		}
+
+		if op.Amended {
+			err = gitOps.UndoAmend(ctx, op.Parent)
+		} else {
+			err = gitOps.Undo(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		for _, remote := range op.Remotes {
+			if err := gitOps.ForcePushUndo(ctx, remote, op.Branch, op.RemoteBranch, op.SHA); err != nil {
+				logger.Warning("The commit is undone locally; %s still has it", remote)
+				return err
+			}
+		}
+		logger.Result(map[string]interface{}{"ok": true, "undone": op.SHA, "head": op.Parent, "force_pushed": op.Remotes})
+		return gitOps.ClearOperation(ctx)
+	},
+}
+
+// undoByRevert commits the reverse of ghquick's commit on top of the branch and
+// pushes it wherever the commit went, leaving history intact
+func undoByRevert(ctx context.Context, gitOps *git.Operations, op *git.Operation, branch string, conventional bool) error {
+	if branch != op.Branch {
+		return fmt.Errorf("ghquick committed %s on %s; switch to it before reverting", shortSHA(op.SHA), op.Branch)
+	}
+	if op.Amended {
+		return fmt.Errorf("the last run amended a commit; reverting would undo the whole commit, not just the amend; use --force, or revert by hand")
+	}
+	if !gitOps.IsAncestor(ctx, op.SHA) {
+		return fmt.Errorf("%s is no longer on %s; nothing to revert", shortSHA(op.SHA), branch)
+	}
+
+	subject, err := gitOps.CommitSubject(ctx, op.SHA)
+	if err != nil {
+		return err
+	}
+	if err := gitOps.Revert(ctx, op.SHA, commit.RevertMessage(subject, op.SHA, conventional)); err != nil {
+		return err
+	}
+	for _, remote := range op.Remotes {
+		if err := gitOps.PushRevert(ctx, remote, branch, op.RemoteBranch); err != nil {
+			logger.Warning("The revert is committed locally; push it with 'git push %s %s:%s'", remote, branch, op.RemoteBranch)
+			return err
+		}
+	}
+	logger.Result(map[string]interface{}{"ok": true, "reverted": op.SHA, "pushed": op.Remotes})
+	return gitOps.ClearOperation(ctx)
+}
+
+// shortSHA abbreviates a commit hash for messages
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
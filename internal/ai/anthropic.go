@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultAnthropicEndpoint = "https://api.anthropic.com"
+	defaultAnthropicModel    = "claude-3-5-haiku-latest"
+	anthropicVersion         = "2023-06-01"
+)
+
+// anthropicProvider talks to the Anthropic Messages API
+type anthropicProvider struct {
+	apiKey   string
+	model    string
+	endpoint string
+}
+
+func newAnthropicProvider(cfg ProviderConfig) *anthropicProvider {
+	p := &anthropicProvider{apiKey: cfg.APIKey, model: cfg.Model, endpoint: strings.TrimRight(cfg.Endpoint, "/")}
+	if p.model == "" {
+		p.model = defaultAnthropicModel
+	}
+	if p.endpoint == "" {
+		p.endpoint = defaultAnthropicEndpoint
+	}
+	return p
+}
+
+func (p *anthropicProvider) Name() string {
+	return ProviderAnthropic + "/" + p.model
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature float64            `json:"temperature"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func (p *anthropicProvider) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+	return NewCommitMessageGenerator(p).GenerateFromDiff(ctx, diff)
+}
+
+func (p *anthropicProvider) Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error) {
+	var resp anthropicResponse
+	err := postJSON(ctx, p.endpoint+"/v1/messages", map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}, anthropicRequest{
+		Model:       p.model,
+		MaxTokens:   maxTokens,
+		System:      systemPrompt,
+		Messages:    []anthropicMessage{{Role: "user", Content: userPrompt}},
+		Temperature: 0.3,
+	}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("failed to generate commit message: empty response")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const changelogPrompt = `You edit the entries of a software changelog in Keep a Changelog format.
+Rewrite each bullet, written from a commit subject, as a short sentence telling
+users what changed for them, in the past tense with no trailing period. Keep
+every "### " heading and every bullet under the heading it's in, one bullet per
+original bullet, in the same order. Keep the **Breaking:** and **scope:**
+markers and the commit hash in parentheses at the end of each bullet. Reply
+with the Markdown only.`
+
+// PolishChangelog rewrites the entries of a changelog section body, made from
+// commit subjects, into prose for users. The headings and the number of
+// entries must survive, or the reply is rejected.
+func (g *CommitMessageGenerator) PolishChangelog(ctx context.Context, body string) (string, error) {
+	if len(body) > maxReleaseNotesBytes {
+		return "", fmt.Errorf("changelog section is too long to polish (%d bytes)", len(body))
+	}
+	reply, err := g.complete(ctx, changelogPrompt, body, 1500)
+	if err != nil {
+		return "", err
+	}
+	polished := strings.TrimPrefix(strings.TrimSpace(reply), "```markdown")
+	polished = strings.Trim(polished, "`\n ")
+	if headings(polished) != headings(body) || bullets(polished) != bullets(body) {
+		return "", fmt.Errorf("polished changelog doesn't keep the original headings and entries")
+	}
+	return polished, nil
+}
+
+// headings returns the "### " heading lines of a Markdown body
+func headings(body string) string {
+	var out []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "### ") {
+			out = append(out, strings.TrimSpace(line))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// bullets counts the list items of a Markdown body
+func bullets(body string) int {
+	n := 0
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "- ") {
+			n++
+		}
+	}
+	return n
+}
@@ -5,19 +5,58 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/sashabaranov/go-openai"
+	"github.com/saint/ghquick/internal/commit"
 )
 
 type CommitMessageGenerator struct {
-	client *openai.Client
+	provider Provider
+	opts     Options
 }
 
-func NewCommitMessageGenerator(apiKey string) *CommitMessageGenerator {
+// Options tunes the prompt sent to the model
+type Options struct {
+	// Scope, when set, is used as the Conventional Commit scope
+	Scope string
+	// SplitThreshold is the diff size in bytes above which the diff is
+	// summarized per file before generating the final message (0 disables)
+	SplitThreshold int
+	// TokenBudget, when set, replaces SplitThreshold with a limit in model
+	// tokens (estimated at four bytes each) on the diff part of the prompt
+	TokenBudget int
+	// Language is the language the description is written in (default English)
+	Language string
+	// StructuredBody asks for a body split into Summary and Changes sections
+	StructuredBody bool
+	// MaxBodyLines and MaxSummaryWords ask for a terser body (0 means no limit);
+	// callers enforce them afterwards with commit.LimitBody
+	MaxBodyLines    int
+	MaxSummaryWords int
+	// Conventional enforces the Conventional Commits header and subject length
+	// on the generated message instead of only asking for it
+	Conventional bool
+	// CommitType pins the Conventional Commit type; InferredType only suggests one
+	CommitType   string
+	InferredType string
+}
+
+// NewCommitMessageGenerator returns a generator that sends its prompts to provider
+func NewCommitMessageGenerator(provider Provider) *CommitMessageGenerator {
 	return &CommitMessageGenerator{
-		client: openai.NewClient(apiKey),
+		provider: provider,
 	}
 }
 
+// ProviderName identifies the provider and model generating the messages
+func (g *CommitMessageGenerator) ProviderName() string {
+	return g.provider.Name()
+}
+
+// WithOptions sets the prompt options used for subsequent generations
+func (g *CommitMessageGenerator) WithOptions(opts Options) *CommitMessageGenerator {
+	g.opts = opts
+	return g
+}
+
 type GenerateResult struct {
 	Message string
 	Error   error
@@ -34,35 +73,80 @@ func (g *CommitMessageGenerator) GenerateFromDiffAsync(ctx context.Context, diff
 }
 
 func (g *CommitMessageGenerator) GenerateFromDiff(ctx context.Context, diff string) (string, error) {
+	return g.generate(ctx, diff, "")
+}
+
+// GenerateWithFeedback generates a new message for the diff, telling the model
+// why a previous attempt was rejected (e.g. a commit message linter's output)
+func (g *CommitMessageGenerator) GenerateWithFeedback(ctx context.Context, diff, feedback string) (string, error) {
+	return g.generate(ctx, diff, feedback)
+}
+
+func (g *CommitMessageGenerator) generate(ctx context.Context, diff, feedback string) (string, error) {
+	// Binary, lock and generated content is useless to the model; mention the files by name instead
+	diff, note := prepareDiff(diff)
+
+	userPrompt := fmt.Sprintf("Generate a commit message for this diff:\n\n%s%s", diff, note)
+	if budget := g.diffBudget(); budget > 0 && len(diff) > budget {
+		prompt, err := g.largeDiffPrompt(ctx, diff)
+		if err != nil {
+			return "", err
+		}
+		userPrompt = prompt + note
+	}
+
+	if feedback != "" {
+		userPrompt += fmt.Sprintf("\n\nA previous message for this diff was rejected:\n%s\nWrite a message that fixes these problems.", feedback)
+	}
+
+	var message string
+	var err error
+	if g.opts.StructuredBody {
+		message, err = g.generateStructured(ctx, userPrompt)
+	} else {
+		message, err = g.complete(ctx, g.systemPrompt(), userPrompt, 60)
+	}
+	if err != nil || !g.opts.Conventional {
+		return message, err
+	}
+	return commit.Conventionalize(message, commit.ConventionalSpec{
+		Type:        g.opts.CommitType,
+		Scope:       g.opts.Scope,
+		DefaultType: g.opts.InferredType,
+	}), nil
+}
+
+// systemPrompt builds the instructions for the final commit message
+func (g *CommitMessageGenerator) systemPrompt() string {
 	systemPrompt := `You are a commit message generator. Given a git diff, generate a concise, 
 descriptive commit message following conventional commits format. Focus on the main changes and their purpose.
 Format: <type>(<scope>): <description>
-Types: feat, fix, docs, style, refactor, test, chore
+Types: ` + strings.Join(commit.ConventionalTypes, ", ") + `
 Keep it under 72 characters.`
-
-	resp, err := g.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: "gpt-4-1106-preview",
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: fmt.Sprintf("Generate a commit message for this diff:\n\n%s", diff),
-				},
-			},
-			MaxTokens:   60,
-			Temperature: 0.3,
-		},
-	)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	if g.opts.Conventional {
+		systemPrompt += "\nMark a breaking change with \"!\" after the type or scope and a final \"BREAKING CHANGE: <what breaks>\" footer."
 	}
+	if g.opts.Scope != "" {
+		systemPrompt += fmt.Sprintf("\nUse %q as the scope.", g.opts.Scope)
+	}
+	if g.opts.CommitType != "" {
+		systemPrompt += fmt.Sprintf("\nUse %q as the type.", g.opts.CommitType)
+	} else if g.opts.InferredType != "" {
+		systemPrompt += fmt.Sprintf("\nThe changed files suggest the type %q; use it unless the diff clearly calls for another.", g.opts.InferredType)
+	}
+	if g.opts.Language != "" && g.opts.Language != DefaultLanguage {
+		systemPrompt += fmt.Sprintf("\nWrite the description in %s, but keep the type, scope, code identifiers, and file names exactly as they appear.", g.opts.Language)
+	}
+	if g.opts.MaxBodyLines > 0 {
+		systemPrompt += fmt.Sprintf("\nKeep the body to at most %d lines.", g.opts.MaxBodyLines)
+	}
+	if g.opts.MaxSummaryWords > 0 {
+		systemPrompt += fmt.Sprintf("\nKeep the summary under %d words.", g.opts.MaxSummaryWords)
+	}
+	return systemPrompt
+}
 
-	message := strings.TrimSpace(resp.Choices[0].Message.Content)
-	return message, nil
+// complete runs a single prompt through the provider and returns the trimmed reply
+func (g *CommitMessageGenerator) complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error) {
+	return g.provider.Complete(ctx, systemPrompt, userPrompt, maxTokens)
 }
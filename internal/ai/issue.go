@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxIssueInputBytes caps how much of a pasted error or diff is sent when drafting an issue
+const maxIssueInputBytes = 16000
+
+const issuePrompt = `You write GitHub issues. Given an error message, log, stack trace or diff,
+write a title under 72 characters naming the problem, and a Markdown body with a
+short description, the relevant output in a code block (trimmed to what matters),
+and, if you can tell, the likely cause. Don't invent reproduction steps, versions
+or environment details that aren't in the input.
+Respond with a JSON object only, no code fences:
+{"title": "<title>", "body": "<markdown body>"}`
+
+// GenerateIssue drafts an issue title and body from a pasted error, log or diff.
+// hint, when set, is the user's own description of the problem.
+func (g *CommitMessageGenerator) GenerateIssue(ctx context.Context, input, hint string) (string, string, error) {
+	if len(input) > maxIssueInputBytes {
+		input = input[:maxIssueInputBytes] + "\n... (truncated)"
+	}
+	userPrompt := "Draft an issue for this:\n\n" + input
+	if hint != "" {
+		userPrompt = fmt.Sprintf("The reporter describes it as: %s\n\n%s", hint, userPrompt)
+	}
+
+	reply, err := g.complete(ctx, issuePrompt, userPrompt, 600)
+	if err != nil {
+		return "", "", err
+	}
+	reply = strings.TrimPrefix(strings.TrimSpace(reply), "```json")
+	reply = strings.Trim(reply, "`\n ")
+
+	var parsed struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		return "", "", fmt.Errorf("failed to parse generated issue: %w", err)
+	}
+	if strings.TrimSpace(parsed.Title) == "" {
+		return "", "", fmt.Errorf("failed to parse generated issue: missing title")
+	}
+	return strings.TrimSpace(parsed.Title), strings.TrimSpace(parsed.Body), nil
+}
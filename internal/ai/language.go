@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used when no commit language is configured
+const DefaultLanguage = "English"
+
+// supportedLanguages maps accepted names and ISO 639-1 codes to the language name used in the prompt
+var supportedLanguages = map[string]string{
+	"en": "English", "english": "English",
+	"es": "Spanish", "spanish": "Spanish",
+	"fr": "French", "french": "French",
+	"de": "German", "german": "German",
+	"it": "Italian", "italian": "Italian",
+	"pt": "Portuguese", "portuguese": "Portuguese",
+	"nl": "Dutch", "dutch": "Dutch",
+	"pl": "Polish", "polish": "Polish",
+	"ru": "Russian", "russian": "Russian",
+	"uk": "Ukrainian", "ukrainian": "Ukrainian",
+	"tr": "Turkish", "turkish": "Turkish",
+	"ja": "Japanese", "japanese": "Japanese",
+	"ko": "Korean", "korean": "Korean",
+	"zh": "Chinese", "chinese": "Chinese",
+	"hi": "Hindi", "hindi": "Hindi",
+	"ar": "Arabic", "arabic": "Arabic",
+	"sv": "Swedish", "swedish": "Swedish",
+}
+
+// ParseLanguage validates a language name or code and returns its canonical name
+func ParseLanguage(s string) (string, error) {
+	if s == "" {
+		return DefaultLanguage, nil
+	}
+	if lang, ok := supportedLanguages[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return lang, nil
+	}
+	return "", fmt.Errorf("unsupported commit language %q (supported: %s)", s, strings.Join(SupportedLanguages(), ", "))
+}
+
+// SupportedLanguages returns the sorted list of supported language names
+func SupportedLanguages() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range supportedLanguages {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultOllamaEndpoint = "http://localhost:11434"
+	defaultOllamaModel    = "llama3.1"
+)
+
+// ollamaProvider talks to a local (or self-hosted) Ollama server's chat API
+type ollamaProvider struct {
+	model    string
+	endpoint string
+}
+
+func newOllamaProvider(cfg ProviderConfig) *ollamaProvider {
+	p := &ollamaProvider{model: cfg.Model, endpoint: strings.TrimRight(cfg.Endpoint, "/")}
+	if p.model == "" {
+		p.model = defaultOllamaModel
+	}
+	if p.endpoint == "" {
+		p.endpoint = defaultOllamaEndpoint
+	}
+	return p
+}
+
+func (p *ollamaProvider) Name() string {
+	return ProviderOllama + "/" + p.model
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  struct {
+		Temperature float64 `json:"temperature"`
+		NumPredict  int     `json:"num_predict"`
+	} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+}
+
+func (p *ollamaProvider) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+	return NewCommitMessageGenerator(p).GenerateFromDiff(ctx, diff)
+}
+
+func (p *ollamaProvider) Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error) {
+	req := ollamaRequest{
+		Model: p.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+	}
+	req.Options.Temperature = 0.3
+	req.Options.NumPredict = maxTokens
+
+	var resp ollamaResponse
+	if err := postJSON(ctx, p.endpoint+"/api/chat", nil, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to generate commit message with %s at %s: %w", p.model, p.endpoint, err)
+	}
+	message := strings.TrimSpace(resp.Message.Content)
+	if message == "" {
+		return "", fmt.Errorf("failed to generate commit message: empty response")
+	}
+	return message, nil
+}
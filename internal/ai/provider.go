@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Provider sends a prompt to a language model and returns its reply. The
+// generator builds the prompts, so a provider only handles transport and auth.
+type Provider interface {
+	// GenerateCommitMessage writes a commit message for diff with the default
+	// prompt; CommitMessageGenerator adds options such as scope and language
+	GenerateCommitMessage(ctx context.Context, diff string) (string, error)
+	// Complete runs a single system+user exchange and returns the trimmed reply
+	Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error)
+	// Name identifies the provider and model, e.g. "openai/gpt-4o"
+	Name() string
+}
+
+// Provider names accepted by NewProvider
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// ProviderConfig selects and configures a Provider
+type ProviderConfig struct {
+	// Name is openai (default), anthropic, or ollama
+	Name string
+	// Model overrides the provider's default model
+	Model string
+	// Endpoint overrides the API base URL, e.g. an OpenAI-compatible proxy or a remote Ollama
+	Endpoint string
+	// APIKey authenticates with openai and anthropic; ollama needs none
+	APIKey string
+}
+
+// ParseProvider validates a provider name, defaulting to openai when empty
+func ParseProvider(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return ProviderOpenAI, nil
+	case ProviderOpenAI, ProviderAnthropic, ProviderOllama:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid AI provider %q (expected openai, anthropic, or ollama)", s)
+}
+
+// NewProvider returns the provider named in cfg, filling in its default model
+// and endpoint. It fails when the provider needs an API key and none is set.
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	name, err := ParseProvider(cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case ProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is required for the anthropic provider")
+		}
+		return newAnthropicProvider(cfg), nil
+	case ProviderOllama:
+		return newOllamaProvider(cfg), nil
+	default:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
+		}
+		return newOpenAIProvider(cfg), nil
+	}
+}
+
+const defaultOpenAIModel = "gpt-4-1106-preview"
+
+type openAIProvider struct {
+	client *openai.Client
+	model  string
+}
+
+func newOpenAIProvider(cfg ProviderConfig) *openAIProvider {
+	clientCfg := openai.DefaultConfig(cfg.APIKey)
+	if cfg.Endpoint != "" {
+		clientCfg.BaseURL = strings.TrimRight(cfg.Endpoint, "/")
+	}
+	model := cfg.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	return &openAIProvider{client: openai.NewClientWithConfig(clientCfg), model: model}
+}
+
+func (p *openAIProvider) Name() string {
+	return ProviderOpenAI + "/" + p.model
+}
+
+func (p *openAIProvider) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+	return NewCommitMessageGenerator(p).GenerateFromDiff(ctx, diff)
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error) {
+	resp, err := p.client.CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: p.model,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: systemPrompt,
+				},
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: userPrompt,
+				},
+			},
+			MaxTokens:   maxTokens,
+			Temperature: 0.3,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("failed to generate commit message: empty response")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// postJSON sends body as JSON to url and decodes a successful JSON reply into out
+func postJSON(ctx context.Context, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
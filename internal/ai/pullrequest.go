@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const pullRequestPrompt = `You write GitHub pull request descriptions. Given the diff of a branch against
+its base, write a title under 72 characters that says what the branch does, and a
+Markdown body with a short summary paragraph followed by a bulleted list of the
+notable changes. Don't invent testing steps or issue numbers.
+Respond with a JSON object only, no code fences:
+{"title": "<title>", "body": "<markdown body>"}`
+
+// GeneratePullRequest writes a pull request title and body for the diff of a
+// branch against its base. Diffs over the budget are summarized per file first.
+func (g *CommitMessageGenerator) GeneratePullRequest(ctx context.Context, diff string) (string, string, error) {
+	diff, note := prepareDiff(diff)
+	if budget := g.diffBudget(); budget > 0 && len(diff) > budget {
+		summaries, err := g.summarizeDiff(ctx, diff)
+		if err != nil {
+			return "", "", err
+		}
+		diff = "Per-file summaries of the changes:\n\n" + summaries
+	}
+	userPrompt := "Describe this branch:\n\n" + diff + note
+
+	reply, err := g.complete(ctx, pullRequestPrompt, userPrompt, 600)
+	if err != nil {
+		return "", "", err
+	}
+	reply = strings.TrimPrefix(strings.TrimSpace(reply), "```json")
+	reply = strings.Trim(reply, "`\n ")
+
+	var parsed struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		return "", "", fmt.Errorf("failed to parse generated pull request: %w", err)
+	}
+	if strings.TrimSpace(parsed.Title) == "" {
+		return "", "", fmt.Errorf("failed to parse generated pull request: missing title")
+	}
+	return strings.TrimSpace(parsed.Title), strings.TrimSpace(parsed.Body), nil
+}
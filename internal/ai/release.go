@@ -0,0 +1,34 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxReleaseNotesBytes caps how much of the commit list is sent for a release summary
+const maxReleaseNotesBytes = 16000
+
+const releaseSummaryPrompt = `You write the introduction to a software release's notes. Given the version
+and the list of changes since the previous release, write one short paragraph
+(under 80 words) telling users what the release brings, most important changes
+first, and mention any breaking changes. Plain Markdown prose, no headings, no
+bullet list, no version number heading.`
+
+// GenerateReleaseSummary writes a short paragraph introducing the release,
+// based on the Markdown list of its changes
+func (g *CommitMessageGenerator) GenerateReleaseSummary(ctx context.Context, version, notes string) (string, error) {
+	if len(notes) > maxReleaseNotesBytes {
+		notes = notes[:maxReleaseNotesBytes] + "\n... (truncated)"
+	}
+	userPrompt := fmt.Sprintf("Version: %s\n\nChanges:\n\n%s", version, notes)
+	reply, err := g.complete(ctx, releaseSummaryPrompt, userPrompt, 250)
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(reply)
+	if summary == "" {
+		return "", fmt.Errorf("failed to generate release summary: empty reply")
+	}
+	return summary, nil
+}
@@ -0,0 +1,372 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/saint/ghquick/internal/git"
+)
+
+// maxFilePatchBytes caps how much of a single file's patch is sent in one
+// request when summarizing it; larger patches are split at hunk boundaries
+const maxFilePatchBytes = 8000
+
+// maxPatchChunks caps how many pieces of one file's patch are summarized
+const maxPatchChunks = 6
+
+// bytesPerToken is a rough average for code and English text
+const bytesPerToken = 4
+
+// maxReduceRounds bounds how often a too-long summary list is condensed further
+const maxReduceRounds = 3
+
+const fileSummaryPrompt = `You summarize the changes made to a single file in a git diff.
+Reply with one short sentence (under 20 words) describing what changed and why, without quoting code.`
+
+const mergeSummaryPrompt = `You are given summaries of successive parts of the diff of a single file.
+Reply with one short sentence (under 20 words) describing what changed in the file overall, without quoting code.`
+
+const groupSummaryPrompt = `You are given one-line summaries of changes to several files in the same commit.
+Reply with one or two short sentences (under 40 words) describing what these changes do together, without listing every file.`
+
+// maxParallelSummaries bounds how many per-file summaries are requested at once
+const maxParallelSummaries = 4
+
+// forEachParallel calls fn for 0..n-1, at most maxParallelSummaries at a time,
+// and stops at the first error
+func forEachParallel(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	slots := make(chan struct{}, maxParallelSummaries)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			if err := fn(ctx, i); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// GeneratePerFileSummaries returns a one-line summary for every file in the diff.
+// Text files are summarized by the model, several at a time; binary files get a
+// heuristic summary.
+func (g *CommitMessageGenerator) GeneratePerFileSummaries(ctx context.Context, files []git.FileDiff) (map[string]string, error) {
+	var (
+		mu   sync.Mutex
+		text []git.FileDiff
+	)
+	summaries := make(map[string]string, len(files))
+	for _, f := range files {
+		if f.Binary {
+			summaries[f.Path] = fmt.Sprintf("%s binary file", f.Change)
+			continue
+		}
+		text = append(text, f)
+	}
+
+	err := forEachParallel(ctx, len(text), func(ctx context.Context, i int) error {
+		summary, err := g.summarizeFile(ctx, text[i])
+		if err != nil {
+			return fmt.Errorf("failed to summarize %s: %w", text[i].Path, err)
+		}
+		mu.Lock()
+		summaries[text[i].Path] = summary
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// summarizeFile summarizes one file's patch, in pieces when it is too large
+// for a single request
+func (g *CommitMessageGenerator) summarizeFile(ctx context.Context, f git.FileDiff) (string, error) {
+	chunks := chunkPatch(f.Patch, maxFilePatchBytes)
+	if len(chunks) == 1 {
+		return g.complete(ctx, fileSummaryPrompt, fmt.Sprintf("File: %s\n\n%s", f.Path, chunks[0]), 40)
+	}
+
+	parts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		summary, err := g.complete(ctx, fileSummaryPrompt, fmt.Sprintf("File: %s (part %d of %d)\n\n%s", f.Path, i+1, len(chunks), chunk), 40)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = "- " + summary
+	}
+	return g.complete(ctx, mergeSummaryPrompt, fmt.Sprintf("File: %s\n\n%s", f.Path, strings.Join(parts, "\n")), 40)
+}
+
+// chunkPatch splits a file's patch at hunk boundaries into pieces of at most
+// max bytes, each starting with the file header. A hunk larger than max is
+// truncated at a line boundary, and hunks beyond maxPatchChunks pieces are left out.
+func chunkPatch(patch string, max int) []string {
+	if len(patch) <= max {
+		return []string{patch}
+	}
+
+	var header string
+	var hunks []string
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunks = append(hunks, line)
+		case len(hunks) == 0:
+			header += line
+		default:
+			hunks[len(hunks)-1] += line
+		}
+	}
+	if len(hunks) == 0 {
+		return []string{strings.TrimSuffix(truncateAtLine(patch, max), "\n") + "\n... (truncated)"}
+	}
+
+	room := max - len(header)
+	if room < max/2 {
+		room = max / 2
+	}
+	var chunks []string
+	var current strings.Builder
+	for i, hunk := range hunks {
+		if len(hunk) > room {
+			hunk = strings.TrimSuffix(truncateAtLine(hunk, room), "\n") + "\n... (hunk truncated)\n"
+		}
+		if current.Len() > 0 && current.Len()+len(hunk) > room {
+			chunks = append(chunks, header+current.String())
+			current.Reset()
+			if len(chunks) == maxPatchChunks {
+				chunks[len(chunks)-1] += fmt.Sprintf("... (%d more hunks omitted)\n", len(hunks)-i)
+				return chunks
+			}
+		}
+		current.WriteString(hunk)
+	}
+	return append(chunks, header+current.String())
+}
+
+// truncateAtLine cuts s to at most max bytes at the end of a line, so neither a
+// line nor a UTF-8 character is split; a first line longer than max is cut at
+// the last character boundary instead
+func truncateAtLine(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if i := strings.LastIndexByte(s[:max], '\n'); i >= 0 {
+		return s[:i+1]
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+// diffBudget is the diff size in bytes above which the diff is summarized
+// instead of sent as is; 0 means never
+func (g *CommitMessageGenerator) diffBudget() int {
+	if g.opts.TokenBudget > 0 {
+		return g.opts.TokenBudget * bytesPerToken
+	}
+	return g.opts.SplitThreshold
+}
+
+// prepareDiff drops binary, lock and generated files from the diff, whose
+// content only wastes the model's context, and returns a note naming them
+func prepareDiff(diff string) (string, string) {
+	diff, binaries := git.StripBinary(diff)
+	diff, generated := git.StripGenerated(diff)
+
+	var note string
+	if len(binaries) > 0 {
+		note += fmt.Sprintf("\n\nAlso updated (binary, content omitted): %s", strings.Join(binaries, ", "))
+	}
+	if len(generated) > 0 {
+		names := make([]string, len(generated))
+		for i, f := range generated {
+			names[i] = fmt.Sprintf("%s (+%d/-%d)", f.Path, f.Additions, f.Deletions)
+		}
+		note += fmt.Sprintf("\n\nAlso updated (lock or generated files, content omitted): %s", strings.Join(names, ", "))
+	}
+	return diff, note
+}
+
+// largeDiffPrompt summarizes each file first and builds the final prompt from those summaries
+func (g *CommitMessageGenerator) largeDiffPrompt(ctx context.Context, diff string) (string, error) {
+	summaries, err := g.summarizeDiff(ctx, diff)
+	if err != nil {
+		return "", err
+	}
+	return "Generate a commit message for a change with these per-file summaries:\n\n" + summaries, nil
+}
+
+// summarizeDiff replaces a diff with a bullet list of per-file summaries,
+// condensed group by group while the list is still over the budget
+func (g *CommitMessageGenerator) summarizeDiff(ctx context.Context, diff string) (string, error) {
+	files := git.ParseDiff(diff)
+	summaries, err := g.GeneratePerFileSummaries(ctx, files)
+	if err != nil {
+		return "", err
+	}
+	entries := summaryEntries(files, summaries)
+	if budget := g.diffBudget(); budget > 0 {
+		if entries, err = g.reduceSummaries(ctx, entries, budget); err != nil {
+			return "", err
+		}
+	}
+	return formatSummaries(entries), nil
+}
+
+// summaryEntry is one line of the summary list: a single file, or after
+// reduction a run of neighbouring files
+type summaryEntry struct {
+	first, last          string
+	files                int
+	change               git.ChangeType
+	additions, deletions int
+	summary              string
+}
+
+func (e summaryEntry) String() string {
+	if e.files == 1 {
+		return fmt.Sprintf("- %s (%s, +%d/-%d): %s\n", e.first, e.change, e.additions, e.deletions, e.summary)
+	}
+	return fmt.Sprintf("- %d files, %s … %s (+%d/-%d): %s\n", e.files, e.first, e.last, e.additions, e.deletions, e.summary)
+}
+
+// summaryEntries pairs each summary with its file's stats, sorted by path so
+// the list is stable and related files sit together
+func summaryEntries(files []git.FileDiff, summaries map[string]string) []summaryEntry {
+	stats := make(map[string]git.FileDiff, len(files))
+	for _, f := range files {
+		stats[f.Path] = f
+	}
+	entries := make([]summaryEntry, 0, len(summaries))
+	for p, summary := range summaries {
+		f := stats[p]
+		entries = append(entries, summaryEntry{
+			first: p, last: p, files: 1, change: f.Change,
+			additions: f.Additions, deletions: f.Deletions, summary: summary,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].first < entries[j].first })
+	return entries
+}
+
+// reduceSummaries condenses runs of neighbouring entries into one line each
+// until the list fits in budget bytes. After maxReduceRounds the list is cut.
+func (g *CommitMessageGenerator) reduceSummaries(ctx context.Context, entries []summaryEntry, budget int) ([]summaryEntry, error) {
+	for round := 0; len(formatSummaries(entries)) > budget && len(entries) > 1; round++ {
+		if round == maxReduceRounds {
+			return truncateSummaries(entries, budget), nil
+		}
+
+		groups := groupSummaries(entries, maxFilePatchBytes)
+		reduced := make([]summaryEntry, len(groups))
+		err := forEachParallel(ctx, len(groups), func(ctx context.Context, i int) error {
+			group := groups[i]
+			if len(group) == 1 {
+				reduced[i] = group[0]
+				return nil
+			}
+			merged := summaryEntry{first: group[0].first, last: group[len(group)-1].last}
+			for _, e := range group {
+				merged.files += e.files
+				merged.additions += e.additions
+				merged.deletions += e.deletions
+			}
+			summary, err := g.complete(ctx, groupSummaryPrompt, formatSummaries(group), 80)
+			if err != nil {
+				return fmt.Errorf("failed to condense summaries of %s … %s: %w", merged.first, merged.last, err)
+			}
+			merged.summary = summary
+			reduced[i] = merged
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = reduced
+	}
+	return entries, nil
+}
+
+// groupSummaries splits entries into consecutive groups of at most max bytes
+// when formatted, with at least two entries per group so every round shrinks the list
+func groupSummaries(entries []summaryEntry, max int) [][]summaryEntry {
+	var groups [][]summaryEntry
+	var current []summaryEntry
+	size := 0
+	for _, e := range entries {
+		line := len(e.String())
+		if len(current) >= 2 && size+line > max {
+			groups = append(groups, current)
+			current, size = nil, 0
+		}
+		current = append(current, e)
+		size += line
+	}
+	if len(current) == 1 && len(groups) > 0 {
+		groups[len(groups)-1] = append(groups[len(groups)-1], current[0])
+		return groups
+	}
+	return append(groups, current)
+}
+
+// truncateSummaries keeps the entries that fit in budget bytes and replaces
+// the rest with a count
+func truncateSummaries(entries []summaryEntry, budget int) []summaryEntry {
+	size := 0
+	for i, e := range entries {
+		size += len(e.String())
+		if size > budget && i > 0 {
+			rest := summaryEntry{first: entries[i].first, last: entries[len(entries)-1].last, change: entries[i].change}
+			for _, e := range entries[i:] {
+				rest.files += e.files
+				rest.additions += e.additions
+				rest.deletions += e.deletions
+			}
+			rest.summary = "further changes, not summarized"
+			return append(entries[:i:i], rest)
+		}
+	}
+	return entries
+}
+
+// formatSummaries renders summary entries as a bullet list
+func formatSummaries(entries []summaryEntry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/saint/ghquick/internal/commit"
+)
+
+const structuredInstructions = `
+Respond with a JSON object only, no code fences:
+{"subject": "<the commit subject line>", "summary": "<one or two sentences on why the change was made>", "changes": ["<notable change>", ...]}
+List at most five changes.`
+
+// structuredReply is the JSON shape requested by structuredInstructions
+type structuredReply struct {
+	Subject string   `json:"subject"`
+	Summary string   `json:"summary"`
+	Changes []string `json:"changes"`
+}
+
+// generateStructured asks for a subject plus Summary/Changes sections and renders them as a commit message
+func (g *CommitMessageGenerator) generateStructured(ctx context.Context, userPrompt string) (string, error) {
+	reply, err := g.complete(ctx, g.systemPrompt()+structuredInstructions, userPrompt, 400)
+	if err != nil {
+		return "", err
+	}
+
+	reply = strings.TrimSpace(reply)
+	reply = strings.TrimPrefix(reply, "```json")
+	reply = strings.Trim(reply, "`\n ")
+
+	var parsed structuredReply
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		return "", fmt.Errorf("failed to parse structured commit message: %w", err)
+	}
+	if strings.TrimSpace(parsed.Subject) == "" {
+		return "", fmt.Errorf("failed to parse structured commit message: missing subject")
+	}
+
+	return commit.CommitMessage{
+		Subject: parsed.Subject,
+		Summary: parsed.Summary,
+		Changes: parsed.Changes,
+	}.Render(), nil
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Source says where a GitHub token was found
+type Source string
+
+const (
+	SourceFlag     Source = "--token"
+	SourceEnv      Source = "GITHUB_TOKEN"
+	SourceGH       Source = "gh auth token"
+	SourceKeychain Source = "keychain"
+)
+
+// ghTimeout bounds how long the gh CLI may take to print its token
+const ghTimeout = 5 * time.Second
+
+// Resolver finds the GitHub token for a user, trying in order an explicit
+// token (the --token flag), the GITHUB_TOKEN environment variable, the gh
+// CLI's login, and the token store
+type Resolver struct {
+	token string
+	store TokenStore
+	// host is the GitHub host to ask gh for, "" for gh's default
+	host string
+	// gh caches the gh CLI's token, which is slow to ask for
+	gh      string
+	ghAsked bool
+}
+
+// NewResolver returns a resolver that prefers token when it isn't empty and
+// falls back to store (which may be nil) last
+func NewResolver(token string, store TokenStore) *Resolver {
+	return &Resolver{token: token, store: store}
+}
+
+// SetHost makes the gh CLI lookup ask for the token of host, e.g.
+// "github.example.com" for GitHub Enterprise, rather than gh's default host
+func (r *Resolver) SetHost(host string) {
+	r.host = host
+}
+
+// Resolve returns the token and where it came from, or ErrTokenNotFound.
+// A store that can't be read (e.g. no keychain on a headless machine) counts
+// as having no token; its error is returned only when nothing else has one.
+func (r *Resolver) Resolve(user string) (string, Source, error) {
+	if r.token != "" {
+		return r.token, SourceFlag, nil
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token, SourceEnv, nil
+	}
+	if !r.ghAsked {
+		r.gh, r.ghAsked = ghToken(r.host), true
+	}
+	if r.gh != "" {
+		return r.gh, SourceGH, nil
+	}
+	if r.store == nil {
+		return "", "", ErrTokenNotFound
+	}
+	token, err := r.store.Get(user)
+	if err != nil {
+		return "", "", err
+	}
+	return token, SourceKeychain, nil
+}
+
+// Get returns the token from Resolve, so a Resolver can stand in for a TokenStore lookup
+func (r *Resolver) Get(user string) (string, error) {
+	token, _, err := r.Resolve(user)
+	return token, err
+}
+
+// ghToken returns the token the gh CLI is logged in with for host (GH_HOST or
+// gh's default when empty), or "" when gh isn't installed or isn't logged in
+func ghToken(host string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), ghTimeout)
+	defer cancel()
+	args := []string{"auth", "token"}
+	if host != "" {
+		args = append(args, "--hostname", host)
+	}
+	out, err := exec.CommandContext(ctx, "gh", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keychainService is the service name tokens are stored under in the OS credential store
+const keychainService = "ghquick"
+
+// ErrTokenNotFound is returned when no token is stored for the user
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore persists GitHub tokens per user
+type TokenStore interface {
+	Get(user string) (string, error)
+	Set(user, token string) error
+	Delete(user string) error
+}
+
+// KeychainStore keeps tokens in the OS credential store
+// (macOS Keychain, Windows Credential Manager, or libsecret on Linux)
+type KeychainStore struct {
+	service string
+}
+
+func NewKeychainStore() *KeychainStore {
+	return &KeychainStore{service: keychainService}
+}
+
+// Get returns the stored token, or ErrTokenNotFound if there is none
+func (s *KeychainStore) Get(user string) (string, error) {
+	token, err := keyring.Get(s.service, user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrTokenNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token from keychain: %w", err)
+	}
+	return token, nil
+}
+
+// Set stores the token for the user, replacing any existing one
+func (s *KeychainStore) Set(user, token string) error {
+	if err := keyring.Set(s.service, user, token); err != nil {
+		return fmt.Errorf("failed to store token in keychain: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the stored token for the user
+func (s *KeychainStore) Delete(user string) error {
+	err := keyring.Delete(s.service, user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrTokenNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete token from keychain: %w", err)
+	}
+	return nil
+}
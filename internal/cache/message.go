@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MessageCache stores generated commit messages on disk, keyed by a hash of
+// the diff they were generated from
+type MessageCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewMessageCache returns a cache under the user's cache directory
+// (e.g. ~/.cache/ghquick/messages)
+func NewMessageCache() (*MessageCache, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return &MessageCache{
+		dir: filepath.Join(base, "ghquick", "messages"),
+		ttl: 7 * 24 * time.Hour, // Messages for a diff nobody commits are not worth keeping
+	}, nil
+}
+
+// Key hashes everything the generated message depends on into a cache key
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached message for key, if there is a fresh one
+func (c *MessageCache) Get(key string) (string, bool) {
+	path := filepath.Join(c.dir, key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if time.Since(info.ModTime()) > c.ttl {
+		os.Remove(path)
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Set stores the message for key. The file is written under a temporary name
+// and renamed, so a concurrent run never reads a partial message.
+func (c *MessageCache) Set(key, message string) error {
+	if err := writeFile(c.dir, key, message); err != nil {
+		return fmt.Errorf("failed to write cached message: %w", err)
+	}
+	return nil
+}
+
+// writeFile writes data to dir/name through a temporary file and a rename
+func writeFile(dir, name, data string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TemplateCache keeps downloaded .gitignore and license templates on disk, so
+// scaffolding a project doesn't need the network every time
+type TemplateCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewTemplateCache returns a cache under the user's cache directory
+// (e.g. ~/.cache/ghquick/templates)
+func NewTemplateCache() (*TemplateCache, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return &TemplateCache{
+		dir: filepath.Join(base, "ghquick", "templates"),
+		ttl: 30 * 24 * time.Hour, // Templates change rarely
+	}, nil
+}
+
+// Get returns the cached template of the given kind ("gitignore", "license")
+// and name. With stale, an entry older than the cache's lifetime is returned
+// too, for when a fresh copy can't be downloaded.
+func (c *TemplateCache) Get(kind, name string, stale bool) (string, bool) {
+	path := filepath.Join(c.dir, templateFile(kind, name))
+	info, err := os.Stat(path)
+	if err != nil || (!stale && time.Since(info.ModTime()) > c.ttl) {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Set stores a template
+func (c *TemplateCache) Set(kind, name, data string) error {
+	if err := writeFile(c.dir, templateFile(kind, name), data); err != nil {
+		return fmt.Errorf("failed to write cached template: %w", err)
+	}
+	return nil
+}
+
+// templateFile names the file a template is cached in; names are matched
+// case-insensitively, like GitHub does
+func templateFile(kind, name string) string {
+	return kind + "-" + strings.ToLower(strings.ReplaceAll(name, "/", "_"))
+}
@@ -0,0 +1,249 @@
+// Package changelog renders classified commits in Keep a Changelog format
+// (https://keepachangelog.com) and merges them into an existing CHANGELOG.md
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/saint/ghquick/internal/git"
+)
+
+// Unreleased is the version of the section collecting changes not released yet
+const Unreleased = "Unreleased"
+
+// header starts a new changelog
+const header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// sectionTitles are Keep a Changelog's kinds of change, in the order they're listed
+var sectionTitles = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// internalTypes are commit types that don't change what users get, left out
+// unless asked for
+var internalTypes = map[string]bool{"docs": true, "test": true, "build": true, "ci": true, "style": true, "chore": true}
+
+var (
+	// versionHeading matches "## [1.4.0] - 2026-10-14" and "## 1.4.0", capturing the version
+	versionHeading = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+	// linkDefinition matches the "[1.4.0]: https://..." lines at the end of a changelog
+	linkDefinition = regexp.MustCompile(`^\[([^\]]+)\]:\s*\S+`)
+)
+
+// Section is one version's part of the changelog
+type Section struct {
+	// Version is Unreleased or a released version such as v1.4.0
+	Version string
+	// Date is the release date as YYYY-MM-DD; Unreleased has none
+	Date string
+	// Body lists the changes under "### Added" and similar headings; it may be empty
+	Body string
+}
+
+// Heading returns the section's heading, e.g. "## [v1.4.0] - 2026-10-14"
+func (s Section) Heading() string {
+	if s.Date == "" {
+		return fmt.Sprintf("## [%s]", s.Version)
+	}
+	return fmt.Sprintf("## [%s] - %s", s.Version, s.Date)
+}
+
+// String renders the section with its heading
+func (s Section) String() string {
+	if s.Body == "" {
+		return s.Heading() + "\n"
+	}
+	return s.Heading() + "\n\n" + s.Body + "\n"
+}
+
+// Body groups the classified commits under Keep a Changelog's headings: new
+// features are Added, fixes Fixed, and other changes users notice Changed.
+// Docs, tests, build, CI, style and chore commits are left out unless all is
+// set. It returns the body and how many commits it lists.
+func Body(data git.ChangelogData, all bool) (string, int) {
+	bySection := map[string][]string{}
+	count := 0
+	for _, g := range data.Groups {
+		for _, e := range g.Commits {
+			section := sectionFor(e, all)
+			if section == "" {
+				continue
+			}
+			bySection[section] = append(bySection[section], entryLine(e))
+			count++
+		}
+	}
+	var blocks []string
+	for _, title := range sectionTitles {
+		if lines := bySection[title]; len(lines) > 0 {
+			blocks = append(blocks, "### "+title+"\n\n"+strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(blocks, "\n\n"), count
+}
+
+// sectionFor picks the heading a commit is listed under, or "" to leave it out
+func sectionFor(e git.ChangelogEntry, all bool) string {
+	subject := strings.ToLower(e.Subject)
+	switch {
+	case internalTypes[e.Type] && !all:
+		return ""
+	case e.Scope == "security" || (e.Type == "fix" && strings.Contains(subject, "vulnerab")):
+		return "Security"
+	case strings.HasPrefix(subject, "deprecate"):
+		return "Deprecated"
+	case e.Type == "feat" && (strings.HasPrefix(subject, "remove") || strings.HasPrefix(subject, "drop")):
+		return "Removed"
+	case e.Type == "feat":
+		return "Added"
+	case e.Type == "fix":
+		return "Fixed"
+	}
+	return "Changed"
+}
+
+// entryLine formats a commit as "- **scope:** subject (sha)", marking breaking changes
+func entryLine(e git.ChangelogEntry) string {
+	sha := e.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	line := "- "
+	if e.Breaking {
+		line += "**Breaking:** "
+	}
+	if e.Scope != "" {
+		line += "**" + e.Scope + ":** "
+	}
+	return fmt.Sprintf("%s%s (%s)", line, e.Subject, sha)
+}
+
+// parsed is a changelog split into its parts
+type parsed struct {
+	preamble string
+	sections []parsedSection
+	// links are the link definitions, in order, by label
+	links      []string
+	linkByName map[string]int
+}
+
+type parsedSection struct {
+	version string
+	text    string
+}
+
+// parse splits a changelog into the text before the first version, the
+// version sections, and the link definitions
+func parse(doc string) parsed {
+	p := parsed{linkByName: map[string]int{}}
+	var current *parsedSection
+	var preamble []string
+	for _, line := range strings.Split(doc, "\n") {
+		if m := linkDefinition.FindStringSubmatch(line); m != nil {
+			p.linkByName[strings.ToLower(m[1])] = len(p.links)
+			p.links = append(p.links, line)
+			continue
+		}
+		if m := versionHeading.FindStringSubmatch(line); m != nil {
+			p.sections = append(p.sections, parsedSection{version: m[1]})
+			current = &p.sections[len(p.sections)-1]
+		}
+		if current == nil {
+			preamble = append(preamble, line)
+		} else {
+			current.text += line + "\n"
+		}
+	}
+	p.preamble = strings.TrimSpace(strings.Join(preamble, "\n"))
+	return p
+}
+
+// find returns the index of the section for version, or -1
+func (p parsed) find(version string) int {
+	for i, s := range p.sections {
+		if strings.EqualFold(s.version, version) {
+			return i
+		}
+	}
+	return -1
+}
+
+// setLink replaces the link definition for label, or adds it in front of the
+// others, where the newest version's goes
+func (p *parsed) setLink(label, url string) {
+	line := fmt.Sprintf("[%s]: %s", label, url)
+	if i, ok := p.linkByName[strings.ToLower(label)]; ok {
+		p.links[i] = line
+		return
+	}
+	p.links = append([]string{line}, p.links...)
+	for name, i := range p.linkByName {
+		p.linkByName[name] = i + 1
+	}
+	p.linkByName[strings.ToLower(label)] = 0
+}
+
+// Update merges s into the changelog doc, which is empty for a new file, and
+// returns the new document. The Unreleased section is replaced with s, or when
+// s is a release, s goes below an emptied Unreleased section; a section for the
+// same version is replaced, so running it again gives the same result. With
+// repoURL (https://github.com/owner/name), the headings' compare links are
+// updated too; previous is the version s follows.
+func Update(doc string, s Section, repoURL, previous string) string {
+	p := parse(doc)
+	if p.preamble == "" {
+		p.preamble = strings.TrimSpace(header)
+	}
+
+	text := s.String()
+	released := !strings.EqualFold(s.Version, Unreleased)
+	unreleased := p.find(Unreleased)
+	switch i := p.find(s.Version); {
+	case i >= 0:
+		p.sections[i].text = text
+	case released && unreleased >= 0:
+		p.sections = append(p.sections[:unreleased+1], append([]parsedSection{{version: s.Version, text: text}}, p.sections[unreleased+1:]...)...)
+	default:
+		p.sections = append([]parsedSection{{version: s.Version, text: text}}, p.sections...)
+	}
+	if released {
+		// The released changes aren't unreleased any more
+		if i := p.find(Unreleased); i >= 0 {
+			p.sections[i].text = Section{Version: Unreleased}.String()
+		} else {
+			p.sections = append([]parsedSection{{version: Unreleased, text: Section{Version: Unreleased}.String()}}, p.sections...)
+		}
+	}
+
+	if repoURL != "" {
+		latest := previous
+		if released {
+			latest = s.Version
+			// Set before Unreleased's, so Unreleased's stays first
+			if previous != "" {
+				p.setLink(s.Version, fmt.Sprintf("%s/compare/%s...%s", repoURL, previous, s.Version))
+			} else {
+				p.setLink(s.Version, fmt.Sprintf("%s/releases/tag/%s", repoURL, s.Version))
+			}
+		}
+		if latest != "" {
+			p.setLink(Unreleased, fmt.Sprintf("%s/compare/%s...HEAD", repoURL, latest))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(p.preamble + "\n\n")
+	for _, sec := range p.sections {
+		b.WriteString(strings.TrimSpace(sec.text) + "\n\n")
+	}
+	if len(p.links) > 0 {
+		b.WriteString(strings.Join(p.links, "\n") + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
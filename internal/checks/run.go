@@ -0,0 +1,90 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Run executes a shell command line in dir and returns its combined output
+func Run(ctx context.Context, dir, command string) (string, error) {
+	return RunWithInput(ctx, dir, command, "")
+}
+
+// RunWithInput is Run with input piped to the command's stdin
+func RunWithInput(ctx context.Context, dir, command, input string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", fmt.Errorf("empty command")
+	}
+
+	cmd := shellCommand(ctx, dir, command)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("%q failed: %w", command, err)
+	}
+	return string(output), nil
+}
+
+// RunStreaming is Run with each line of output passed to onLine as the
+// command writes it
+func RunStreaming(ctx context.Context, dir, command string, onLine func(line string)) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", fmt.Errorf("empty command")
+	}
+	w := &lineWriter{onLine: onLine}
+	cmd := shellCommand(ctx, dir, command)
+	cmd.Stdout, cmd.Stderr = w, w
+	err := cmd.Run()
+	w.flush()
+	if err != nil {
+		return w.output.String(), fmt.Errorf("%q failed: %w", command, err)
+	}
+	return w.output.String(), nil
+}
+
+// shellCommand runs command line through the platform's shell in dir
+func shellCommand(ctx context.Context, dir, command string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	return cmd
+}
+
+// lineWriter collects output and hands it on a line at a time
+type lineWriter struct {
+	onLine  func(string)
+	output  bytes.Buffer
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.onLine(strings.TrimRight(string(w.pending[:i]), "\r"))
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// flush passes on output left without a final newline
+func (w *lineWriter) flush() {
+	if len(w.pending) > 0 {
+		w.onLine(string(w.pending))
+		w.pending = nil
+	}
+}
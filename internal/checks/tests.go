@@ -0,0 +1,101 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TestReport summarizes the result of running the project's test command
+type TestReport struct {
+	Command string
+	Passed  int
+	Failed  int
+	Skipped int
+	Output  string
+	// Err is set when the test command exited with a non-zero status
+	Err error
+}
+
+// OK reports whether the test command succeeded
+func (r TestReport) OK() bool {
+	return r.Err == nil && r.Failed == 0
+}
+
+// Summary renders the report as a single line, e.g. "Tests: 142 passed"
+func (r TestReport) Summary() string {
+	var parts []string
+	if r.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", r.Failed))
+	}
+	parts = append(parts, fmt.Sprintf("%d passed", r.Passed))
+	if r.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", r.Skipped))
+	}
+	if r.Err != nil && r.Failed == 0 {
+		parts = append(parts, "command failed")
+	}
+	return "Tests: " + strings.Join(parts, ", ")
+}
+
+var (
+	goTestResult  = regexp.MustCompile(`(?m)^\s*--- (PASS|FAIL|SKIP): `)
+	goPackageLine = regexp.MustCompile(`(?m)^(ok|FAIL)\s+\S+`)
+	genericCount  = regexp.MustCompile(`(?i)(\d+)\s+(passed|failed|skipped)`)
+)
+
+// RunTests runs the test command and parses a pass/fail summary from its output
+func RunTests(ctx context.Context, dir, command string) TestReport {
+	output, err := Run(ctx, dir, command)
+	report := ParseTestOutput(output)
+	report.Command = command
+	report.Output = output
+	report.Err = err
+	return report
+}
+
+// ParseTestOutput counts results from `go test -v` lines, falling back to
+// per-package `go test` lines and finally to generic "N passed" counters
+func ParseTestOutput(output string) TestReport {
+	var report TestReport
+
+	if matches := goTestResult.FindAllStringSubmatch(output, -1); len(matches) > 0 {
+		for _, m := range matches {
+			switch m[1] {
+			case "PASS":
+				report.Passed++
+			case "FAIL":
+				report.Failed++
+			case "SKIP":
+				report.Skipped++
+			}
+		}
+		return report
+	}
+
+	if matches := goPackageLine.FindAllStringSubmatch(output, -1); len(matches) > 0 {
+		for _, m := range matches {
+			if m[1] == "ok" {
+				report.Passed++
+			} else {
+				report.Failed++
+			}
+		}
+		return report
+	}
+
+	for _, m := range genericCount.FindAllStringSubmatch(output, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch strings.ToLower(m[2]) {
+		case "passed":
+			report.Passed += n
+		case "failed":
+			report.Failed += n
+		case "skipped":
+			report.Skipped += n
+		}
+	}
+	return report
+}
@@ -0,0 +1,110 @@
+package commit
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/strutil"
+)
+
+// BreakingOptions controls which heuristics are used to flag breaking changes
+type BreakingOptions struct {
+	// RemovedSymbols flags exported Go declarations that disappear from a package
+	RemovedSymbols bool
+	// SignatureChanges flags exported Go functions whose signature changed
+	SignatureChanges bool
+	// IncludeInternal also inspects packages under internal/, which are not importable by other modules
+	IncludeInternal bool
+}
+
+// DefaultBreakingOptions enables the removal and signature heuristics for public packages
+func DefaultBreakingOptions() BreakingOptions {
+	return BreakingOptions{
+		RemovedSymbols:   true,
+		SignatureChanges: true,
+	}
+}
+
+var (
+	goFuncDecl  = regexp.MustCompile(`^func\s+(?:\(\s*(?:\w+\s+)?\*?(\w+)(?:\[[^\]]*\])?\s*\)\s*)?([A-Z]\w*)\s*(\(.*)$`)
+	goTypeDecl  = regexp.MustCompile(`^type\s+([A-Z]\w*)\b`)
+	goValueDecl = regexp.MustCompile(`^(?:var|const)\s+([A-Z]\w*)\b`)
+)
+
+// DetectBreakingChanges inspects removed and added Go declarations in the diff and
+// returns a human readable reason for every likely breaking change
+func DetectBreakingChanges(files []git.FileDiff, opts BreakingOptions) []string {
+	removed := make(map[string]string)
+	added := make(map[string]string)
+
+	for _, f := range files {
+		if !isPublicGoFile(f, opts) {
+			continue
+		}
+		// Renames move declarations out of the old package directory
+		pkg := path.Dir(strutil.FirstNonEmpty(f.OldPath, f.Path))
+		for _, line := range strings.Split(f.Patch, "\n") {
+			switch {
+			case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+				continue
+			case strings.HasPrefix(line, "-"):
+				if key, sig, ok := parseGoDecl(line[1:]); ok {
+					removed[pkg+":"+key] = sig
+				}
+			case strings.HasPrefix(line, "+"):
+				if key, sig, ok := parseGoDecl(line[1:]); ok {
+					added[path.Dir(f.Path)+":"+key] = sig
+				}
+			}
+		}
+	}
+
+	var reasons []string
+	for key, oldSig := range removed {
+		newSig, stillThere := added[key]
+		symbol := key[strings.Index(key, ":")+1:]
+		switch {
+		case !stillThere && opts.RemovedSymbols:
+			reasons = append(reasons, fmt.Sprintf("removed exported %s", symbol))
+		case stillThere && opts.SignatureChanges && oldSig != "" && oldSig != newSig:
+			reasons = append(reasons, fmt.Sprintf("changed signature of %s", symbol))
+		}
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// parseGoDecl returns a key identifying an exported top-level declaration and,
+// for functions, its normalized signature
+func parseGoDecl(line string) (key, signature string, ok bool) {
+	if m := goFuncDecl.FindStringSubmatch(line); m != nil {
+		key = m[2]
+		if m[1] != "" {
+			key = m[1] + "." + m[2]
+		}
+		sig := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[3]), "{"))
+		return key, strings.Join(strings.Fields(sig), " "), true
+	}
+	if m := goTypeDecl.FindStringSubmatch(line); m != nil {
+		return m[1], "", true
+	}
+	if m := goValueDecl.FindStringSubmatch(line); m != nil {
+		return m[1], "", true
+	}
+	return "", "", false
+}
+
+func isPublicGoFile(f git.FileDiff, opts BreakingOptions) bool {
+	p := strutil.FirstNonEmpty(f.Path, f.OldPath)
+	if f.Binary || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+		return false
+	}
+	if !opts.IncludeInternal && (strings.HasPrefix(p, "internal/") || strings.Contains(p, "/internal/")) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package commit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saint/ghquick/internal/git"
+	"github.com/saint/ghquick/internal/strutil"
+)
+
+// MaxSubjectLength is the longest subject line a Conventional Commit may have
+const MaxSubjectLength = 72
+
+// ConventionalTypes lists the accepted Conventional Commit types
+var ConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// ParseCommitType validates a Conventional Commit type; an empty string means
+// the type is left to inference
+func ParseCommitType(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+	for _, t := range ConventionalTypes {
+		if s == t {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("invalid commit type %q (expected one of %s)", s, strings.Join(ConventionalTypes, ", "))
+}
+
+// InferType guesses the Conventional Commit type from the changed files: changes
+// confined to tests, docs, CI or build files get that type, and new source files
+// lean feat. It returns an empty string when the diff doesn't say either way.
+func InferType(files []git.FileDiff) string {
+	if len(files) == 0 {
+		return ""
+	}
+	for _, kind := range []struct {
+		typ   string
+		match func(string) bool
+	}{
+		{"test", isTestFile},
+		{"docs", isDocFile},
+		{"ci", isCIFile},
+		{"build", isBuildFile},
+	} {
+		all := true
+		for _, f := range files {
+			if !kind.match(f.Path) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return kind.typ
+		}
+	}
+
+	for _, f := range files {
+		if f.Change == git.ChangeAdded && !isTestFile(f.Path) && !isDocFile(f.Path) {
+			return "feat"
+		}
+	}
+	return ""
+}
+
+func isTestFile(p string) bool {
+	base := path.Base(p)
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || hasDir(p, "test", "tests", "testdata", "__tests__")
+}
+
+func isDocFile(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".rst", ".adoc", ".txt":
+		return true
+	}
+	return hasDir(p, "docs", "doc")
+}
+
+func isCIFile(p string) bool {
+	return strings.HasPrefix(p, ".github/workflows/") || strings.HasPrefix(p, ".circleci/") ||
+		path.Base(p) == ".gitlab-ci.yml" || path.Base(p) == ".travis.yml"
+}
+
+func isBuildFile(p string) bool {
+	switch path.Base(p) {
+	case "go.mod", "go.sum", "Makefile", "Dockerfile", "package.json", "package-lock.json",
+		"yarn.lock", "pnpm-lock.yaml", "Cargo.toml", "Cargo.lock", "requirements.txt", "pyproject.toml":
+		return true
+	}
+	return false
+}
+
+// hasDir reports whether any directory component of p is one of names
+func hasDir(p string, names ...string) bool {
+	parts := strings.Split(path.Dir(p), "/")
+	for _, part := range parts {
+		for _, name := range names {
+			if part == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConventionalSpec pins parts of a Conventional Commit header
+type ConventionalSpec struct {
+	// Type and Scope, when set, replace whatever the message has
+	Type  string
+	Scope string
+	// DefaultType is used when the message has no recognizable type (chore if empty)
+	DefaultType string
+}
+
+// Conventionalize rewrites the subject as "type(scope): description" according
+// to spec, keeping a "!" breaking marker, and moves whatever doesn't fit in
+// MaxSubjectLength characters into the body, separated by a blank line
+func Conventionalize(message string, spec ConventionalSpec) string {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.TrimSpace(subject)
+
+	typ, scope, bang, description := "", "", "", subject
+	if m := conventionalHeader.FindStringSubmatch(subject); m != nil {
+		if t, err := ParseCommitType(m[1]); err == nil && t != "" {
+			typ, scope, bang = t, strings.Trim(m[2], "()"), m[3]
+			description = subject[len(m[0]):]
+		}
+	}
+	typ = strutil.FirstNonEmpty(spec.Type, typ, spec.DefaultType, "chore")
+	scope = strutil.FirstNonEmpty(spec.Scope, scope)
+
+	header := typ
+	if scope != "" {
+		header += "(" + scope + ")"
+	}
+	header += bang + ": "
+
+	description, overflow := splitAtWidth(strings.TrimSpace(description), MaxSubjectLength-utf8.RuneCountInString(header))
+	subject = header + description
+
+	var paragraphs []string
+	if overflow != "" {
+		paragraphs = append(paragraphs, overflow)
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		paragraphs = append(paragraphs, body)
+	}
+	if len(paragraphs) == 0 {
+		return subject
+	}
+	return subject + "\n\n" + strings.Join(paragraphs, "\n\n")
+}
+
+// RevertMessage is the message for a commit that reverts sha, whose subject
+// was subject: git's own `Revert "..."` form, or "revert: ..." when conventional
+func RevertMessage(subject, sha string, conventional bool) string {
+	header := fmt.Sprintf("Revert %q", subject)
+	if conventional {
+		header = "revert: " + subject
+		if utf8.RuneCountInString(header) > MaxSubjectLength {
+			header = string([]rune(header)[:MaxSubjectLength-1]) + "…"
+		}
+	}
+	return fmt.Sprintf("%s\n\nThis reverts commit %s.", header, sha)
+}
+
+// splitAtWidth cuts s to at most width runes, preferring a word boundary, and
+// returns the kept part and the remainder
+func splitAtWidth(s string, width int) (string, string) {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s, ""
+	}
+	cut := 0
+	for i := range s {
+		if utf8.RuneCountInString(s[:i]) > width {
+			break
+		}
+		cut = i
+	}
+	if space := strings.LastIndex(s[:cut+1], " "); space > 0 {
+		cut = space
+	}
+	return strings.TrimSpace(s[:cut]), strings.TrimSpace(s[cut:])
+}
+
+// ValidateConventional checks that a hand-written message is a Conventional
+// Commit: a "type(scope)!: description" subject of at most MaxSubjectLength
+// characters with a known type, a blank line before any body, and breaking
+// change footers written as "BREAKING CHANGE: description". The error says
+// what to fix.
+func ValidateConventional(message string) error {
+	subject, rest, hasBody := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.TrimRight(subject, " \t\r")
+
+	m := conventionalHeader.FindStringSubmatch(subject)
+	if m == nil {
+		return fmt.Errorf("commit message %q is not a Conventional Commit; write it as \"type(scope): description\", e.g. \"fix(api): handle empty responses\"", subject)
+	}
+	if _, err := ParseCommitType(m[1]); err != nil {
+		return fmt.Errorf("commit message %q: %w", subject, err)
+	}
+	if m[2] == "()" {
+		return fmt.Errorf("commit message %q has an empty scope; name one or drop the parentheses", subject)
+	}
+	header := m[1] + m[2] + m[3] + ":"
+	if strings.TrimSpace(subject[len(header):]) == "" {
+		return fmt.Errorf("commit message %q has no description after %q", subject, header)
+	}
+	if !strings.HasPrefix(subject, header+" ") {
+		return fmt.Errorf("commit message %q needs a space after %q", subject, header)
+	}
+	if n := utf8.RuneCountInString(subject); n > MaxSubjectLength {
+		return fmt.Errorf("commit subject is %d characters; keep it to %d and move the details into the body", n, MaxSubjectLength)
+	}
+
+	if !hasBody {
+		return nil
+	}
+	lines := strings.Split(rest, "\n")
+	if strings.TrimSpace(lines[0]) != "" {
+		return fmt.Errorf("separate the commit subject from the body with a blank line")
+	}
+	for _, line := range lines {
+		upper := strings.ToUpper(line)
+		if !strings.HasPrefix(upper, "BREAKING CHANGE:") && !strings.HasPrefix(upper, "BREAKING-CHANGE:") {
+			continue
+		}
+		if !strings.HasPrefix(line, "BREAKING CHANGE: ") && !strings.HasPrefix(line, "BREAKING-CHANGE: ") {
+			return fmt.Errorf("write the breaking change footer as \"BREAKING CHANGE: what breaks\", not %q", line)
+		}
+	}
+	return nil
+}
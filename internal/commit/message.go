@@ -0,0 +1,131 @@
+package commit
+
+import (
+	"strings"
+)
+
+// Section names shared by structured commit bodies and PR templates
+const (
+	SectionSummary = "Summary"
+	SectionChanges = "Changes"
+	SectionTesting = "Testing"
+)
+
+// CommitMessage is a commit message with an optional body structured into the
+// sections a PR template expects, so a single-commit PR body can be derived from it
+type CommitMessage struct {
+	Subject string
+	Summary string
+	Changes []string
+	Testing string
+	// Trailers are footer lines such as "BREAKING CHANGE: ..." kept after the sections
+	Trailers []string
+}
+
+// HasSections reports whether any structured section is set
+func (m CommitMessage) HasSections() bool {
+	return m.Summary != "" || len(m.Changes) > 0 || m.Testing != ""
+}
+
+// Render formats the message for git, using "Section:" headings that survive commit message cleanup
+func (m CommitMessage) Render() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(m.Subject))
+	m.writeSections(&b, func(name string) string { return name + ":" })
+	if len(m.Trailers) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(strings.Join(m.Trailers, "\n"))
+	}
+	return b.String()
+}
+
+// RenderPRBody formats the sections as Markdown for a pull request description
+func (m CommitMessage) RenderPRBody() string {
+	var b strings.Builder
+	m.writeSections(&b, func(name string) string { return "## " + name })
+	if len(m.Trailers) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(strings.Join(m.Trailers, "\n"))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (m CommitMessage) writeSections(b *strings.Builder, heading func(string) string) {
+	if m.Summary != "" {
+		b.WriteString("\n\n" + heading(SectionSummary) + "\n")
+		b.WriteString(strings.TrimSpace(m.Summary))
+	}
+	if len(m.Changes) > 0 {
+		b.WriteString("\n\n" + heading(SectionChanges))
+		for _, c := range m.Changes {
+			b.WriteString("\n- " + strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(c), "- ")))
+		}
+	}
+	if m.Testing != "" {
+		b.WriteString("\n\n" + heading(SectionTesting) + "\n")
+		b.WriteString(strings.TrimSpace(m.Testing))
+	}
+}
+
+// ParseCommitMessage splits a rendered message back into its subject and sections.
+// Bodies without section headings end up in Summary.
+func ParseCommitMessage(message string) CommitMessage {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	m := CommitMessage{Subject: strings.TrimSpace(subject)}
+
+	section := SectionSummary
+	var summary, testing []string
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case isSectionHeading(trimmed, SectionSummary):
+			section = SectionSummary
+			continue
+		case isSectionHeading(trimmed, SectionChanges):
+			section = SectionChanges
+			continue
+		case isSectionHeading(trimmed, SectionTesting):
+			section = SectionTesting
+			continue
+		case isTrailer(trimmed):
+			m.Trailers = append(m.Trailers, trimmed)
+			continue
+		}
+
+		switch section {
+		case SectionSummary:
+			summary = append(summary, line)
+		case SectionChanges:
+			if trimmed != "" {
+				m.Changes = append(m.Changes, strings.TrimPrefix(trimmed, "- "))
+			}
+		case SectionTesting:
+			testing = append(testing, line)
+		}
+	}
+	m.Summary = strings.TrimSpace(strings.Join(summary, "\n"))
+	m.Testing = strings.TrimSpace(strings.Join(testing, "\n"))
+	return m
+}
+
+// HasSectionHeadings reports whether the message body uses structured section headings
+func HasSectionHeadings(message string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		for _, name := range []string{SectionSummary, SectionChanges, SectionTesting} {
+			if isSectionHeading(line, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isSectionHeading(line, name string) bool {
+	return line == name+":" || line == "## "+name
+}
+
+// isTrailer recognizes the footers ghquick itself writes
+func isTrailer(line string) bool {
+	return strings.HasPrefix(line, "BREAKING CHANGE: ")
+}
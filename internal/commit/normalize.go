@@ -0,0 +1,111 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SubjectCase controls the capitalization of the subject description
+type SubjectCase string
+
+const (
+	// CaseKeep leaves the capitalization untouched
+	CaseKeep SubjectCase = ""
+	// CaseSentence capitalizes the first letter of the description
+	CaseSentence SubjectCase = "sentence"
+	// CaseLower lowercases the first letter of the description
+	CaseLower SubjectCase = "lower"
+)
+
+// ParseSubjectCase validates a subject case name
+func ParseSubjectCase(s string) (SubjectCase, error) {
+	switch SubjectCase(strings.ToLower(s)) {
+	case CaseKeep, "keep":
+		return CaseKeep, nil
+	case CaseSentence:
+		return CaseSentence, nil
+	case CaseLower:
+		return CaseLower, nil
+	}
+	return "", fmt.Errorf("invalid subject case %q (expected sentence or lower)", s)
+}
+
+// imperativeVerbs rewrites common past-tense and third-person forms to the imperative mood
+var imperativeVerbs = map[string]string{
+	"added": "add", "adds": "add", "adding": "add",
+	"fixed": "fix", "fixes": "fix", "fixing": "fix",
+	"updated": "update", "updates": "update", "updating": "update",
+	"removed": "remove", "removes": "remove", "removing": "remove",
+	"changed": "change", "changes": "change", "changing": "change",
+	"refactored": "refactor", "refactors": "refactor", "refactoring": "refactor",
+	"implemented": "implement", "implements": "implement", "implementing": "implement",
+	"improved": "improve", "improves": "improve", "improving": "improve",
+	"renamed": "rename", "renames": "rename", "renaming": "rename",
+	"moved": "move", "moves": "move", "moving": "move",
+	"created": "create", "creates": "create", "creating": "create",
+	"deleted": "delete", "deletes": "delete", "deleting": "delete",
+	"replaced": "replace", "replaces": "replace", "replacing": "replace",
+	"introduced": "introduce", "introduces": "introduce", "introducing": "introduce",
+	"bumped": "bump", "bumps": "bump", "bumping": "bump",
+	"cleaned": "clean", "cleans": "clean", "cleaning": "clean",
+	"supported": "support", "supports": "support", "supporting": "support",
+	"enabled": "enable", "enables": "enable", "enabling": "enable",
+	"disabled": "disable", "disables": "disable", "disabling": "disable",
+	"documented": "document", "documents": "document", "documenting": "document",
+	"made": "make", "makes": "make", "making": "make",
+	"wrote": "write", "writes": "write", "writing": "write",
+}
+
+// NormalizeSubject rewrites the first word of the subject to the imperative mood,
+// applies the capitalization policy, and drops a trailing period. For
+// Conventional Commits only the description after "type(scope): " is touched.
+func NormalizeSubject(message string, subjectCase SubjectCase) string {
+	subject, rest, hasRest := strings.Cut(message, "\n")
+
+	prefix, description := "", subject
+	if m := conventionalHeader.FindStringIndex(subject); m != nil {
+		prefix, description = subject[:m[1]], subject[m[1]:]
+	}
+
+	description = strings.TrimSuffix(strings.TrimSpace(description), ".")
+	description = toImperative(description)
+
+	switch subjectCase {
+	case CaseSentence:
+		description = mapFirstRune(description, unicode.ToUpper)
+	case CaseLower:
+		description = mapFirstRune(description, unicode.ToLower)
+	}
+
+	subject = prefix + description
+	if hasRest {
+		return subject + "\n" + rest
+	}
+	return subject
+}
+
+// toImperative replaces the leading verb using the rule table, preserving its capitalization
+func toImperative(description string) string {
+	word, tail, _ := strings.Cut(description, " ")
+	replacement, ok := imperativeVerbs[strings.ToLower(word)]
+	if !ok {
+		return description
+	}
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		replacement = mapFirstRune(replacement, unicode.ToUpper)
+	}
+	if tail == "" {
+		return replacement
+	}
+	return replacement + " " + tail
+}
+
+func mapFirstRune(s string, f func(rune) rune) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(f(r)) + s[size:]
+}
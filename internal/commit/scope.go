@@ -0,0 +1,65 @@
+package commit
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/saint/ghquick/internal/git"
+)
+
+// containerDirs are top-level directories that group several areas of a repo
+// (e.g. services/api, packages/web). The area is the directory below them.
+var containerDirs = map[string]bool{
+	"apps":     true,
+	"cmd":      true,
+	"internal": true,
+	"libs":     true,
+	"packages": true,
+	"pkg":      true,
+	"services": true,
+}
+
+// DeriveScope returns the Conventional Commit scope shared by all changed files,
+// or an empty string when the changes span several areas or only touch the repo root
+func DeriveScope(files []git.FileDiff) string {
+	areas := DeriveScopes(files)
+	if len(areas) != 1 {
+		return ""
+	}
+	return areas[0]
+}
+
+// DeriveScopes returns the sorted, de-duplicated list of areas touched by the changed files
+func DeriveScopes(files []git.FileDiff) []string {
+	seen := make(map[string]bool)
+	var areas []string
+	for _, f := range files {
+		area := areaOf(f.Path)
+		if area == "" || seen[area] {
+			continue
+		}
+		seen[area] = true
+		areas = append(areas, area)
+	}
+	sort.Strings(areas)
+	return areas
+}
+
+// JoinScopes renders multiple areas as a single scope (e.g. "api,web")
+func JoinScopes(areas []string) string {
+	return strings.Join(areas, ",")
+}
+
+// areaOf maps a file path to the area it belongs to
+func areaOf(p string) string {
+	dir := path.Dir(path.Clean(p))
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	parts := strings.Split(dir, "/")
+	if containerDirs[parts[0]] && len(parts) > 1 {
+		return parts[1]
+	}
+	return parts[0]
+}
@@ -0,0 +1,114 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// truncationMarker is appended to a body that was cut to fit the size limit
+const truncationMarker = "[...]"
+
+// trailerLine matches git trailers such as "Signed-off-by: ..." or "BREAKING CHANGE: ..."
+var trailerLine = regexp.MustCompile(`^(?:[A-Za-z][A-Za-z0-9-]*|BREAKING CHANGE): `)
+
+// LimitSize truncates the message body so the whole message fits in maxBytes,
+// always keeping the subject and any trailing trailer block. It reports whether
+// the message was truncated. A maxBytes of 0 or less disables the limit.
+func LimitSize(message string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(message) <= maxBytes {
+		return message, false
+	}
+
+	subject, body, trailers := splitMessage(message)
+	kept := subject
+	suffix := ""
+	if trailers != "" {
+		suffix = "\n\n" + trailers
+	}
+
+	budget := maxBytes - len(kept) - len(suffix) - len("\n\n") - len("\n"+truncationMarker)
+	if budget > 0 && body != "" {
+		cut := truncateUTF8(body, budget)
+		// Prefer cutting at a line boundary
+		if i := strings.LastIndex(cut, "\n"); i > len(cut)/2 {
+			cut = cut[:i]
+		}
+		kept += "\n\n" + strings.TrimRight(cut, " \n") + "\n" + truncationMarker
+	}
+	return kept + suffix, true
+}
+
+// LimitBody enforces a verbosity budget: the first body paragraph (the summary)
+// is cut to maxSummaryWords words and the body to maxLines lines, keeping the
+// subject and trailers. It reports whether anything was cut. Zero disables a limit.
+func LimitBody(message string, maxLines, maxSummaryWords int) (string, bool) {
+	subject, body, trailers := splitMessage(message)
+	if body == "" {
+		return message, false
+	}
+	truncated := false
+
+	if maxSummaryWords > 0 {
+		paragraphs := strings.SplitN(body, "\n\n", 2)
+		heading, text := "", paragraphs[0]
+		if first, rest, ok := strings.Cut(text, "\n"); ok && first == SectionSummary+":" {
+			heading, text = first+"\n", rest
+		}
+		if words := strings.Fields(text); len(words) > maxSummaryWords {
+			paragraphs[0] = heading + strings.Join(words[:maxSummaryWords], " ") + "..."
+			body = strings.Join(paragraphs, "\n\n")
+			truncated = true
+		}
+	}
+
+	if maxLines > 0 {
+		if lines := strings.Split(body, "\n"); len(lines) > maxLines {
+			body = strings.TrimRight(strings.Join(lines[:maxLines], "\n"), " \n")
+			truncated = true
+		}
+	}
+
+	if !truncated {
+		return message, false
+	}
+	limited := subject + "\n\n" + body
+	if trailers != "" {
+		limited += "\n\n" + trailers
+	}
+	return limited, true
+}
+
+// splitMessage separates the subject, the free-form body, and the trailer block
+func splitMessage(message string) (subject, body, trailers string) {
+	subject, rest, _ := strings.Cut(strings.TrimRight(message, "\n"), "\n")
+	rest = strings.Trim(rest, "\n")
+
+	paragraphs := strings.Split(rest, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	if last != "" && isTrailerBlock(last) {
+		trailers = last
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+	return subject, strings.Join(paragraphs, "\n\n"), trailers
+}
+
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a multi-byte rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,54 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saint/ghquick/internal/git"
+)
+
+// ChangeSummary describes the staged changes a commit message is generated for
+type ChangeSummary struct {
+	Files           []git.FileDiff
+	Scope           string
+	BreakingChange  bool
+	BreakingReasons []string
+}
+
+// Summarize builds a ChangeSummary from the parsed diff
+func Summarize(files []git.FileDiff, breaking BreakingOptions) ChangeSummary {
+	reasons := DetectBreakingChanges(files, breaking)
+	return ChangeSummary{
+		Files:           files,
+		Scope:           DeriveScope(files),
+		BreakingChange:  len(reasons) > 0,
+		BreakingReasons: reasons,
+	}
+}
+
+// conventionalHeader matches "type(scope)!: description"
+var conventionalHeader = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?:\s*`)
+
+// MarkBreaking adds a "!" to the Conventional Commit type and a BREAKING CHANGE
+// trailer describing the reasons, if they are not already present
+func MarkBreaking(message string, reasons []string) string {
+	subject, body, _ := strings.Cut(message, "\n")
+	if m := conventionalHeader.FindStringSubmatchIndex(subject); m != nil && m[6] < 0 {
+		// Insert "!" right after the type and optional scope
+		insertAt := m[3]
+		if m[4] >= 0 {
+			insertAt = m[5]
+		}
+		subject = subject[:insertAt] + "!" + subject[insertAt:]
+	}
+
+	message = subject
+	if body != "" {
+		message += "\n" + body
+	}
+	if !strings.Contains(message, "BREAKING CHANGE:") {
+		trailer := "BREAKING CHANGE: " + strings.Join(reasons, "; ")
+		message = strings.TrimRight(message, "\n") + "\n\n" + trailer
+	}
+	return message
+}
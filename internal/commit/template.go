@@ -0,0 +1,85 @@
+package commit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is what message templates can reference, e.g. {{.Subject}}
+type TemplateData struct {
+	Message string
+	Subject string
+	Body    string
+}
+
+// templateFuncs are the helpers available to message templates
+var templateFuncs = template.FuncMap{
+	// env returns the value of an environment variable, or "" when unset
+	"env": os.Getenv,
+	// envOr returns the environment variable, or fallback when it is unset or empty
+	"envOr": func(name, fallback string) string {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		return fallback
+	},
+}
+
+// RenderTemplate executes a message template against the message
+func RenderTemplate(tmpl, message string) (string, error) {
+	t, err := template.New("message").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid message template %q: %w", tmpl, err)
+	}
+
+	subject, body, _ := strings.Cut(message, "\n")
+	data := TemplateData{
+		Message: message,
+		Subject: subject,
+		Body:    strings.TrimSpace(body),
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render message template %q: %w", tmpl, err)
+	}
+	return b.String(), nil
+}
+
+// Decorate adds a rendered prefix and suffix to the subject line,
+// e.g. a prefix of `[CI #{{env "BUILD_NUMBER"}}]` gives "[CI #42] fix: ..."
+func Decorate(message, prefixTmpl, suffixTmpl string) (string, error) {
+	prefix, err := renderOptional(prefixTmpl, message)
+	if err != nil {
+		return "", err
+	}
+	suffix, err := renderOptional(suffixTmpl, message)
+	if err != nil {
+		return "", err
+	}
+
+	subject, rest, hasRest := strings.Cut(message, "\n")
+	if prefix != "" {
+		subject = prefix + " " + subject
+	}
+	if suffix != "" {
+		subject = subject + " " + suffix
+	}
+	if hasRest {
+		return subject + "\n" + rest, nil
+	}
+	return subject, nil
+}
+
+func renderOptional(tmpl, message string) (string, error) {
+	if tmpl == "" {
+		return "", nil
+	}
+	out, err := RenderTemplate(tmpl, message)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -9,33 +9,60 @@ const (
 	EnvGitHubToken    = "GITHUB_TOKEN"
 	EnvGitHubUsername = "GITHUB_USERNAME"
 	EnvOpenAIKey      = "OPENAI_API_KEY"
+	EnvAnthropicKey   = "ANTHROPIC_API_KEY"
 )
 
 type Config struct {
 	GitHubToken    string
 	GitHubUsername string
 	OpenAIKey      string
+	AnthropicKey   string
+}
+
+// TokenSource looks up a stored GitHub token for a user
+type TokenSource interface {
+	Get(user string) (string, error)
 }
 
 // LoadFromEnv loads configuration from environment variables
 func LoadFromEnv() (*Config, error) {
+	return Load(nil)
+}
+
+// Load loads configuration from environment variables, taking the GitHub token
+// from tokens (e.g. an auth.Resolver, which also checks gh and the keychain)
+// when it has one for the user.
+// AI keys are optional here; the selected provider checks for the one it needs.
+func Load(tokens TokenSource) (*Config, error) {
+	cfg, err := LoadGitHub(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OpenAIKey = os.Getenv(EnvOpenAIKey)
+	cfg.AnthropicKey = os.Getenv(EnvAnthropicKey)
+	return cfg, nil
+}
+
+// LoadGitHub loads only the GitHub credentials, for commands that don't use the AI generator
+func LoadGitHub(tokens TokenSource) (*Config, error) {
 	githubToken := os.Getenv(EnvGitHubToken)
 	githubUsername := os.Getenv(EnvGitHubUsername)
-	openAIKey := os.Getenv(EnvOpenAIKey)
 
-	if githubToken == "" {
-		return nil, errors.New("GITHUB_TOKEN environment variable is required")
-	}
 	if githubUsername == "" {
 		return nil, errors.New("GITHUB_USERNAME environment variable is required")
 	}
-	if openAIKey == "" {
-		return nil, errors.New("OPENAI_API_KEY environment variable is required")
+	if tokens != nil {
+		if resolved, err := tokens.Get(githubUsername); err == nil && resolved != "" {
+			githubToken = resolved
+		}
+	}
+	if githubToken == "" {
+		return nil, errors.New("no GitHub token found; pass --token, set GITHUB_TOKEN, run 'gh auth login', or run 'ghquick auth login'")
 	}
 
 	return &Config{
 		GitHubToken:    githubToken,
 		GitHubUsername: githubUsername,
-		OpenAIKey:      openAIKey,
 	}, nil
 }
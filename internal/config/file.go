@@ -0,0 +1,336 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigName is the name of the per-repository config file. The global
+// config shares the name, in the home directory.
+const RepoConfigName = ".ghquick.yaml"
+
+// RepoTOMLConfigName is the TOML spelling of RepoConfigName, read instead of it
+// when present. The global TOML config is ~/.ghquick/config.toml.
+const RepoTOMLConfigName = ".ghquick.toml"
+
+// FileConfig holds settings read from .ghquick.yaml or .ghquick.toml files
+type FileConfig struct {
+	// CheckoutDirtyPolicy is what to do with local changes when switching branches: abort, stash, or carry
+	CheckoutDirtyPolicy string `yaml:"checkout_dirty_policy"`
+	// PreCommitChecks is the older spelling of Hooks.PreCommit. LoadFile moves
+	// them there, ahead of the hooks, so it is always empty afterwards.
+	//
+	// Deprecated: use hooks.pre_commit.
+	PreCommitChecks []string `yaml:"pre_commit_checks"`
+	// Hooks are commands run before push and amend commit (pre_commit) and
+	// before they push (pre_push); --no-verify skips them
+	Hooks Hooks `yaml:"hooks"`
+	// HookTimeout limits each hook, e.g. 15m; the default is 10m
+	HookTimeout time.Duration `yaml:"hook_timeout"`
+	// TestCommand is the command run by `push --test`, e.g. "go test ./..."
+	TestCommand string `yaml:"test_command"`
+	// FormatCommand is the formatter run by `push --format`, e.g. "prettier --write ."
+	FormatCommand string `yaml:"format_command"`
+	// CommitMsgValidator is a command (e.g. "npx commitlint") that receives the commit
+	// message on stdin and exits non-zero to reject it; generated messages are retried
+	CommitMsgValidator string `yaml:"commit_msg_validator"`
+	// NormalizeSubject rewrites commit subjects to the imperative mood
+	NormalizeSubject bool `yaml:"normalize_subject"`
+	// SubjectCase is the subject capitalization applied when normalizing: sentence or lower
+	SubjectCase string `yaml:"subject_case"`
+	// RequireCleanIndex makes push refuse to auto-stage when changes are already
+	// staged, protecting a hand-curated index (override with --force-stage)
+	RequireCleanIndex bool `yaml:"require_clean_index"`
+	// AllowedExtensions limits staging to files with these extensions (e.g. [".go", ".md"])
+	AllowedExtensions []string `yaml:"allowed_extensions"`
+	// Exclude lists glob patterns that are never staged, on top of .gitignore (e.g. [".env", "node_modules"])
+	Exclude []string `yaml:"exclude"`
+	// AllowlistStrict fails staging instead of skipping files outside AllowedExtensions
+	AllowlistStrict bool `yaml:"allowlist_strict"`
+	// CommitLanguage is the language AI-generated commit messages are written in
+	CommitLanguage string `yaml:"commit_language"`
+	// Conventional forces generated messages into Conventional Commits format and
+	// rejects hand-written messages that don't follow it
+	Conventional bool `yaml:"conventional"`
+	// AutoScope derives the commit scope from the changed paths, as push --auto-scope does
+	AutoScope bool `yaml:"auto_scope"`
+	// DetectBreaking flags removed or changed exported Go symbols as breaking
+	// changes in generated messages, as push --detect-breaking does
+	DetectBreaking bool `yaml:"detect_breaking"`
+	// StructuredBody generates commit bodies with Summary/Changes/Testing sections
+	StructuredBody bool `yaml:"structured_body"`
+	// CodeRoot is where --repo owner/name clones live, e.g. ~/src (as <root>/github.com/owner/name)
+	CodeRoot string `yaml:"code_root"`
+	// MaxMessageBytes caps the total commit message size; the body is truncated to fit
+	MaxMessageBytes int `yaml:"max_message_bytes"`
+	// MessagePrefix and MessageSuffix are templates added around the subject; they
+	// can use {{env "NAME"}} to pull in CI metadata such as build numbers
+	MessagePrefix string `yaml:"message_prefix"`
+	MessageSuffix string `yaml:"message_suffix"`
+	// TodoMarkers are the markers warned about in added lines (default TODO, FIXME, XXX)
+	TodoMarkers []string `yaml:"todo_markers"`
+	// DetachedSubmodules is what push does when a submodule has a detached HEAD with
+	// commits no remote has: warn (default), block, or ignore
+	DetachedSubmodules string `yaml:"detached_submodules"`
+	// Symlinks is how staged symbolic links are handled: keep (default), warn, or skip
+	Symlinks string `yaml:"symlinks"`
+	// EOL normalizes staged text files to lf, crlf, or auto (the platform default)
+	EOL string `yaml:"eol"`
+	// BotName and BotEmail are the identity used by `push --as-bot`
+	BotName  string `yaml:"bot_name"`
+	BotEmail string `yaml:"bot_email"`
+	// BotActorTrailer adds a Triggered-by trailer with $GITHUB_ACTOR to bot commits
+	BotActorTrailer bool `yaml:"bot_actor_trailer"`
+	// WarnBlobSize reports the bytes a commit adds to history and warns above this size, e.g. "5MB"
+	WarnBlobSize string `yaml:"warn_blob_size"`
+	// NewDirThreshold is the number of files in a new directory above which push asks before staging it
+	NewDirThreshold int `yaml:"new_dir_threshold"`
+	// MaxBodyLines and MaxSummaryWords bound the length of AI-generated bodies
+	MaxBodyLines    int `yaml:"max_body_lines"`
+	MaxSummaryWords int `yaml:"max_summary_words"`
+	// PRDraftDefault opens pull requests as drafts unless `pr --ready` is given
+	PRDraftDefault bool `yaml:"pr_draft_default"`
+	// PRMergeMethod is how `pr merge` merges: merge (default), squash, or rebase
+	PRMergeMethod string `yaml:"pr_merge_method"`
+	// PRDeleteBranch makes `pr merge` delete the head branch, here and on GitHub
+	PRDeleteBranch bool `yaml:"pr_delete_branch"`
+	// NoHints turns off the "Next:" suggestion printed after commands
+	NoHints bool `yaml:"no_hints"`
+	// LogLevel is the least severe kind of message shown: debug, info (default), warn, or error
+	LogLevel string `yaml:"log_level"`
+	// LogFile also records every run, debug messages included, in ~/.ghquick/logs/ghquick.log
+	LogFile bool `yaml:"log_file"`
+	// Timeout bounds each command as a whole, e.g. 5m (default 2m; push's --timeout wins)
+	Timeout time.Duration `yaml:"timeout"`
+	// NetworkTimeout bounds each git command that talks to a remote, per attempt
+	// when retried, e.g. 1m; LocalTimeout bounds every other git command, e.g.
+	// 30s. Unset, only Timeout applies.
+	NetworkTimeout time.Duration `yaml:"network_timeout"`
+	LocalTimeout   time.Duration `yaml:"local_timeout"`
+	// ConfirmWindow is a grace period before pushing during which a keypress cancels, e.g. 5s
+	ConfirmWindow time.Duration `yaml:"confirm_window"`
+	// LockStrategy is how git lock files are handled: remove (delete stale locks,
+	// the default), wait (poll until released, up to LockWait), or error
+	LockStrategy string        `yaml:"lock_strategy"`
+	LockWait     time.Duration `yaml:"lock_wait"`
+	// GitBackend is how status, diff, staging and commits are done: exec (run the
+	// git binary, the default) or go-git (in-process, for hosts without git)
+	GitBackend string `yaml:"git_backend"`
+	// AutoPrune adds --prune to fetches so deleted remote branches don't linger locally
+	AutoPrune bool `yaml:"auto_prune"`
+	// FollowTags makes push send annotated tags that point at the pushed commits
+	FollowTags bool `yaml:"follow_tags"`
+	// SyncStrategy is how 'ghquick sync' brings in upstream commits: rebase (default) or merge
+	SyncStrategy string `yaml:"sync_strategy"`
+	// SignCommits signs every commit push creates, as --sign does; SigningKey
+	// overrides user.signingkey
+	SignCommits bool   `yaml:"sign_commits"`
+	SigningKey  string `yaml:"signing_key"`
+	// CreateRepo creates the GitHub repository on push when it doesn't exist, as --create-repo does
+	CreateRepo bool `yaml:"create_repo"`
+	// DefaultBranch is the branch pull requests target when no base is given,
+	// instead of asking the API for the repository's default branch
+	DefaultBranch string `yaml:"default_branch"`
+	// Remote is the remote push sends to when --remotes isn't given (default origin)
+	Remote string `yaml:"remote"`
+	// AuthorName and AuthorEmail set the author and committer of commits push creates
+	AuthorName  string `yaml:"author_name"`
+	AuthorEmail string `yaml:"author_email"`
+	// Identities pick the user.name/user.email push writes to a repository's git
+	// config, by GitHub owner/name or directory; the first match wins
+	Identities []Identity `yaml:"identities"`
+	// AIProvider generates commit messages: openai (default), anthropic, or ollama.
+	// AIModel and AIEndpoint override the provider's default model and API URL.
+	AIProvider string `yaml:"ai_provider"`
+	AIModel    string `yaml:"ai_model"`
+	AIEndpoint string `yaml:"ai_endpoint"`
+	// AITokenBudget caps, in model tokens, how much diff a prompt carries; larger
+	// diffs are summarized file by file (default: --max-diff-bytes)
+	AITokenBudget int `yaml:"ai_token_budget"`
+	// RemoteScheme is the URL push configures for origin: auto (keep origin's
+	// scheme, else gh's git_protocol, else https), https, or ssh
+	RemoteScheme string `yaml:"remote_scheme"`
+	// GitHubHost is the host remote URLs point at, for GitHub Enterprise (default
+	// github.com). Only the global config can set it, as the token is sent there.
+	GitHubHost string `yaml:"github_host"`
+	// PushAttempts overrides RetryAttempts for 'ghquick push'
+	PushAttempts int `yaml:"push_attempts"`
+	// RetryAttempts is how many times network git commands and GitHub API calls
+	// are tried on transient errors such as timeouts, 5xx and rate limits (default 3)
+	RetryAttempts int `yaml:"retry_attempts"`
+	// RetryBackoff is the wait before the first retry, doubling after each one (default 2s)
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// RetryMaxBackoff caps the wait between retries (default 30s)
+	RetryMaxBackoff time.Duration `yaml:"retry_max_backoff"`
+	// RetryJitter randomizes each wait by up to this fraction, e.g. 0.2 for ±20% (default 0.2, negative for none)
+	RetryJitter float64 `yaml:"retry_jitter"`
+	// RetryPatterns are extra regular expressions matching error output that push
+	// should retry, e.g. the transient errors of a corporate git proxy
+	RetryPatterns []string `yaml:"retry_patterns"`
+	// ConfigLockRetries is how often a git config write is retried while
+	// another ghquick or git process holds the config lock
+	ConfigLockRetries int `yaml:"config_lock_retries"`
+
+	// Ignored lists the global-only keys LoadFile dropped from the repository's file
+	Ignored []string `yaml:"-"`
+}
+
+// globalOnly are the settings a repository's .ghquick.yaml can't change. They
+// decide where credentials and diffs are sent, so a cloned repository mustn't
+// be able to point them at a host of its choosing.
+var globalOnly = []struct {
+	key   string
+	field func(*FileConfig) *string
+}{
+	{"ai_endpoint", func(c *FileConfig) *string { return &c.AIEndpoint }},
+	{"github_host", func(c *FileConfig) *string { return &c.GitHubHost }},
+}
+
+// GlobalOnly reports whether key can only be set in the global config
+func GlobalOnly(key string) bool {
+	for _, g := range globalOnly {
+		if g.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultConfigPath returns the global config path: $HOME/.ghquick/config.toml
+// when it exists, otherwise $HOME/.ghquick.yaml
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if path := filepath.Join(home, ".ghquick", "config.toml"); fileExists(path) {
+		return path
+	}
+	return filepath.Join(home, RepoConfigName)
+}
+
+// RepoConfigPath returns the config file of the repository in repoDir:
+// .ghquick.toml when it exists, otherwise .ghquick.yaml
+func RepoConfigPath(repoDir string) string {
+	if path := filepath.Join(repoDir, RepoTOMLConfigName); fileExists(path) {
+		return path
+	}
+	return filepath.Join(repoDir, RepoConfigName)
+}
+
+// fileExists reports whether there is a file or directory at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isTOML reports whether the config file at path is TOML rather than YAML
+func isTOML(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".toml")
+}
+
+// LoadFile reads the global config (path, or the default location when empty)
+// and overlays the repository's config found in repoDir (see RepoConfigPath).
+// Missing files are ignored, as are global-only settings in the repository's
+// file; their keys are listed in Ignored.
+func LoadFile(path, repoDir string) (*FileConfig, error) {
+	cfg := &FileConfig{}
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigPath()
+	}
+	if path != "" {
+		if err := mergeFile(cfg, path, explicit); err != nil {
+			return nil, err
+		}
+	}
+
+	if repoDir != "" {
+		repoPath := RepoConfigPath(repoDir)
+		if repoPath != path {
+			global := *cfg
+			if err := mergeFile(cfg, repoPath, false); err != nil {
+				return nil, err
+			}
+			for _, g := range globalOnly {
+				if *g.field(cfg) != *g.field(&global) {
+					*g.field(cfg) = *g.field(&global)
+					cfg.Ignored = append(cfg.Ignored, g.key)
+				}
+			}
+		}
+	}
+	if len(cfg.PreCommitChecks) > 0 {
+		checks := make([]Hook, 0, len(cfg.PreCommitChecks)+len(cfg.Hooks.PreCommit))
+		for _, c := range cfg.PreCommitChecks {
+			checks = append(checks, Hook{Run: c})
+		}
+		cfg.Hooks.PreCommit = append(checks, cfg.Hooks.PreCommit...)
+		cfg.PreCommitChecks = nil
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate checks settings that would otherwise only fail when first used
+func (c *FileConfig) validate() error {
+	for _, p := range c.RetryPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid retry_patterns entry %q: %w", p, err)
+		}
+	}
+	for i, id := range c.Identities {
+		if err := id.validate(); err != nil {
+			return fmt.Errorf("identities entry %d: %w", i+1, err)
+		}
+	}
+	for stage, hooks := range map[string][]Hook{"pre_commit": c.Hooks.PreCommit, "pre_push": c.Hooks.PrePush} {
+		for i, h := range hooks {
+			if err := h.validate(); err != nil {
+				return fmt.Errorf("hooks.%s entry %d: %w", stage, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeFile decodes the YAML or TOML file at path on top of cfg
+func mergeFile(cfg *FileConfig, path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if isTOML(path) {
+		if data, err = tomlToYAML(data); err != nil {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// tomlToYAML re-encodes a TOML config as YAML, so one set of yaml tags, and
+// the duration parsing that comes with them, serves both formats
+func tomlToYAML(data []byte) ([]byte, error) {
+	var settings map[string]interface{}
+	if err := toml.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(settings)
+}
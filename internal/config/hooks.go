@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook is a command run at a point of the commit-and-push pipeline. In the
+// config it is either the command line itself or a mapping with a name.
+type Hook struct {
+	// Name is shown in progress messages; it defaults to the command
+	Name string `yaml:"name"`
+	Run  string `yaml:"run"`
+}
+
+// Hooks lists the hook commands for each stage; they run in order and the
+// first failure stops the pipeline
+type Hooks struct {
+	// PreCommit runs after staging, before anything is committed
+	PreCommit []Hook `yaml:"pre_commit"`
+	// PrePush runs after committing, before anything is pushed
+	PrePush []Hook `yaml:"pre_push"`
+}
+
+// UnmarshalYAML accepts "go test ./..." as well as {name: tests, run: go test ./...}
+func (h *Hook) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		h.Run = node.Value
+		return nil
+	}
+	type plain Hook
+	return node.Decode((*plain)(h))
+}
+
+// Label is the hook's name, or its command when it has none
+func (h Hook) Label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Run
+}
+
+func (h Hook) validate() error {
+	if h.Run == "" {
+		return fmt.Errorf("hook %q has no command to run", h.Name)
+	}
+	return nil
+}
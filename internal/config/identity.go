@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Identity is a git user.name/user.email pair for the repositories it matches
+type Identity struct {
+	// Match is a pattern on the GitHub owner/name, e.g. "my-org/*"
+	Match string `yaml:"match"`
+	// Dir is a pattern on the directories the repository is in, e.g. "~/work"
+	// matches every repository under ~/work
+	Dir   string `yaml:"dir"`
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+}
+
+// IdentityFor returns the first identity matching the repository with the
+// given owner/name slug in dir, or nil when none does
+func (c *FileConfig) IdentityFor(slug, dir string) *Identity {
+	for i, id := range c.Identities {
+		if id.matches(slug, dir) {
+			return &c.Identities[i]
+		}
+	}
+	return nil
+}
+
+func (id Identity) matches(slug, dir string) bool {
+	if id.Match != "" {
+		if ok, _ := path.Match(strings.ToLower(id.Match), strings.ToLower(slug)); !ok {
+			return false
+		}
+	}
+	if id.Dir != "" {
+		pattern := expandHome(id.Dir)
+		for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+			if ok, _ := filepath.Match(pattern, d); ok {
+				break
+			}
+			if d == filepath.Dir(d) {
+				return false
+			}
+		}
+	}
+	return id.Match != "" || id.Dir != ""
+}
+
+// validate checks that the identity can match something and set something
+func (id Identity) validate() error {
+	if id.Match == "" && id.Dir == "" {
+		return fmt.Errorf("needs match or dir")
+	}
+	if id.Name == "" && id.Email == "" {
+		return fmt.Errorf("needs name or email")
+	}
+	if _, err := path.Match(id.Match, ""); err != nil {
+		return fmt.Errorf("invalid match %q: %w", id.Match, err)
+	}
+	if _, err := filepath.Match(id.Dir, ""); err != nil {
+		return fmt.Errorf("invalid dir %q: %w", id.Dir, err)
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(p string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return p
+}
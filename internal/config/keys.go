@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlKey returns the key a FileConfig field is stored under
+func yamlKey(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	return name
+}
+
+// Keys returns every setting FileConfig understands, sorted
+func Keys() []string {
+	t := reflect.TypeOf(FileConfig{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := yamlKey(t.Field(i)); key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Lookup returns the value of key formatted as YAML, e.g. "true", "5s" or "[a, b]"
+func (c *FileConfig) Lookup(key string) (string, error) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if k := yamlKey(v.Type().Field(i)); k != key || k == "-" {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Slice {
+			var items []string
+			for j := 0; j < field.Len(); j++ {
+				items = append(items, fmt.Sprint(field.Index(j).Interface()))
+			}
+			return "[" + strings.Join(items, ", ") + "]", nil
+		}
+		out, err := yaml.Marshal(field.Interface())
+		if err != nil {
+			return "", fmt.Errorf("failed to format %s: %w", key, err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", fmt.Errorf("unknown config key %q (see 'ghquick config list')", key)
+}
+
+// SetValue writes key: value to the YAML or TOML config file at path, creating
+// the file if needed and keeping its other settings, and in YAML its comments.
+// value is parsed as YAML, so "true", "5s" and "[a, b]" become a bool,
+// duration and list.
+func SetValue(path, key, value string) error {
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}
+	if len(parsed.Content) > 0 {
+		valueNode = parsed.Content[0]
+	}
+
+	// Decode the single setting strictly so unknown keys and mistyped values fail now
+	probe, err := yaml.Marshal(map[string]*yaml.Node{key: valueNode})
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(probe))
+	dec.KnownFields(true)
+	var cfg FileConfig
+	if err := dec.Decode(&cfg); err != nil {
+		if _, lookupErr := cfg.Lookup(key); lookupErr != nil {
+			return lookupErr
+		}
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	if isTOML(path) {
+		return setTOMLValue(path, key, valueNode)
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config %s is not a mapping of settings", path)
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			old := root.Content[i+1]
+			valueNode.HeadComment, valueNode.LineComment, valueNode.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			root.Content[i+1] = valueNode
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, valueNode)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// setTOMLValue writes key = value to the TOML config file at path. The file is
+// re-encoded from its settings, so its comments aren't kept; a null value
+// removes the key.
+func setTOMLValue(path, key string, valueNode *yaml.Node) error {
+	var value interface{}
+	if err := valueNode.Decode(&value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	settings := make(map[string]interface{})
+	if err := toml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if value == nil {
+		delete(settings, key)
+	} else {
+		settings[key] = value
+	}
+
+	var out bytes.Buffer
+	if err := toml.NewEncoder(&out).Encode(settings); err != nil {
+		return fmt.Errorf("failed to encode config %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Backend carries out the local operations every push needs: reading the
+// status, diffing, staging and committing. Operations runs the git binary for
+// them by default; SetBackend(BackendGoGit) does them in-process with go-git.
+// Paths in and out are as git reports them: Status and Diff give them relative
+// to the repository root, Add and Reset take them relative to the working
+// directory.
+type Backend interface {
+	// IsBare reports whether the repository has no working tree
+	IsBare(ctx context.Context) (bool, error)
+	// Status lists the changed and untracked files, like `git status --porcelain`
+	Status(ctx context.Context) ([]FileStatus, error)
+	// Diff returns the staged diff, or the working tree's when staged is false
+	Diff(ctx context.Context, staged bool) (string, error)
+	// Add stages paths, deletions included, or every change when paths is empty
+	Add(ctx context.Context, paths []string) error
+	// Reset unstages paths, leaving the working tree alone
+	Reset(ctx context.Context, paths []string) error
+	// Commit records the index as a new commit on the current branch
+	Commit(ctx context.Context, message string) error
+}
+
+// BackendKind names a Backend for SetBackend
+type BackendKind string
+
+const (
+	// BackendExec runs the git binary (the default)
+	BackendExec BackendKind = "exec"
+	// BackendGoGit uses go-git, for machines without git installed. git's own
+	// hooks and commit signing aren't available with it.
+	BackendGoGit BackendKind = "go-git"
+)
+
+// ParseBackend validates a backend name, defaulting to BackendExec when empty
+func ParseBackend(s string) (BackendKind, error) {
+	switch BackendKind(strings.ToLower(s)) {
+	case "":
+		return BackendExec, nil
+	case BackendExec, BackendGoGit:
+		return BackendKind(strings.ToLower(s)), nil
+	}
+	return "", fmt.Errorf("invalid git backend %q (expected exec or go-git)", s)
+}
+
+// SetBackend chooses how status, diff, staging and commits are done. Everything
+// else, e.g. pushing, branches and history, still runs the git binary.
+func (o *Operations) SetBackend(kind BackendKind) {
+	if kind == BackendGoGit {
+		o.backend = &goGitBackend{o: o}
+		return
+	}
+	o.backend = &execBackend{o: o}
+}
+
+// execBackend is the Backend that runs the git binary
+type execBackend struct {
+	o *Operations
+}
+
+func (b *execBackend) IsBare(ctx context.Context) (bool, error) {
+	output, err := b.o.output(ctx, "rev-parse", "--is-bare-repository")
+	if err != nil {
+		return false, err
+	}
+	return output == "true", nil
+}
+
+func (b *execBackend) Status(ctx context.Context) ([]FileStatus, error) {
+	cmd := b.o.gitCommand(ctx, "status", "--porcelain")
+	output, err := b.o.limitedOutput(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(string(output)), nil
+}
+
+func (b *execBackend) Diff(ctx context.Context, staged bool) (string, error) {
+	args := []string{"diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	output, err := b.o.limitedOutput(ctx, b.o.gitCommand(ctx, args...))
+	return string(output), err
+}
+
+func (b *execBackend) Add(ctx context.Context, paths []string) error {
+	if len(paths) > 0 {
+		return b.o.runCommand(ctx, "git", append([]string{"add", "-A", "--"}, paths...)...)
+	}
+	if err := b.o.runCommand(ctx, "git", "add", "-A"); err != nil {
+		b.o.logger.Warning("Failed to stage with -A flag, trying alternative method...")
+		// If that fails, try explicit path
+		return b.o.runCommand(ctx, "git", "add", b.o.workingDir)
+	}
+	return nil
+}
+
+func (b *execBackend) Reset(ctx context.Context, paths []string) error {
+	if err := b.o.runCommand(ctx, "git", append([]string{"reset", "-q", "--"}, paths...)...); err != nil {
+		// An unborn branch has no HEAD to reset to
+		return b.o.runCommand(ctx, "git", append([]string{"rm", "--cached", "-q", "-r", "--"}, paths...)...)
+	}
+	return nil
+}
+
+func (b *execBackend) Commit(ctx context.Context, message string) error {
+	return b.o.runCommit(ctx, b.o.identityEnv(), "-m", message)
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BlobSize is the size of a staged file's new content
+type BlobSize struct {
+	Path  string
+	Bytes int64
+}
+
+// StagedBlobSizes returns the size of the content each staged addition or
+// modification writes into history, largest first, plus the total.
+// Deletions add nothing and are left out.
+func (o *Operations) StagedBlobSizes(ctx context.Context) ([]BlobSize, int64, error) {
+	out, err := o.output(ctx, "diff", "--cached", "--raw", "-z", "--no-renames", "--no-abbrev")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list staged blobs: %w", err)
+	}
+
+	// Records are ":<old mode> <new mode> <old sha> <new sha> <status>\0<path>\0"
+	var paths, shas []string
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) < 5 || meta[4] == "D" || strings.Trim(meta[3], "0") == "" {
+			continue
+		}
+		paths = append(paths, fields[i+1])
+		shas = append(shas, meta[3])
+	}
+	if len(shas) == 0 {
+		return nil, 0, nil
+	}
+
+	cmd := o.gitCommand(ctx, "cat-file", "--batch-check=%(objectsize)")
+	cmd.Stdin = strings.NewReader(strings.Join(shas, "\n") + "\n")
+	o.logger.Command("git", "cat-file", "--batch-check=%(objectsize)")
+	sizeOut, err := o.limitedOutput(ctx, cmd)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read staged blob sizes: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(sizeOut)), "\n")
+	var sizes []BlobSize
+	var total int64
+	for i, line := range lines {
+		if i >= len(paths) {
+			break
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(line), 10, 64)
+		if err != nil {
+			continue // "<sha> missing", e.g. a submodule commit
+		}
+		sizes = append(sizes, BlobSize{Path: paths[i], Bytes: n})
+		total += n
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Bytes > sizes[j].Bytes })
+	return sizes, total, nil
+}
@@ -0,0 +1,130 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// hasCommits reports whether HEAD points at a commit, which it doesn't right after git init
+func (o *Operations) hasCommits(ctx context.Context) bool {
+	_, err := o.output(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+	return err == nil
+}
+
+// unbornBranch returns the branch HEAD names, even before its first commit
+func (o *Operations) unbornBranch(ctx context.Context) (string, error) {
+	branch, err := o.output(ctx, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("HEAD is detached; check out a branch first")
+	}
+	return branch, nil
+}
+
+// Bootstrap prepares a repository for its first push to a remote that was
+// just created. When the remote already has a commit (GitHub adds one for
+// license and .gitignore templates), the local branch is renamed to match the
+// remote's default branch and built on top of that commit; local files with
+// the same names take precedence over the templates. When nothing has been
+// committed yet, the working tree is staged and committed with message.
+// Without a remote only that initial commit is made. It returns the branch to push.
+func (o *Operations) Bootstrap(ctx context.Context, remote, message string) (string, error) {
+	if err := o.requireWorktree(ctx, "bootstrap"); err != nil {
+		return "", err
+	}
+	branch, err := o.unbornBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	unborn := !o.hasCommits(ctx)
+
+	if remote != "" {
+		o.logger.Step("Fetching %s...", remote)
+		if err := o.runCommand(ctx, "git", "fetch", remote); err != nil {
+			o.logger.Error("Failed to fetch %s", remote)
+			return "", fmt.Errorf("failed to fetch %s: %w", remote, err)
+		}
+
+		// An empty remote has no HEAD to report, so there is nothing to build on
+		if remoteBranch, err := o.DefaultBranch(ctx, remote); err == nil && o.RemoteBranchExists(ctx, remote, remoteBranch) {
+			if err := o.adoptRemoteBranch(ctx, remote, branch, remoteBranch, unborn); err != nil {
+				return "", err
+			}
+			branch = remoteBranch
+		}
+	}
+	if !unborn {
+		return branch, nil
+	}
+
+	clean, err := o.IsClean(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	if clean {
+		if o.hasCommits(ctx) {
+			return branch, nil
+		}
+		return "", fmt.Errorf("nothing to commit; add some files before the initial push")
+	}
+	if err := o.StageAll(ctx); err != nil {
+		return "", err
+	}
+	if err := o.Commit(ctx, message); err != nil {
+		return "", err
+	}
+	return branch, nil
+}
+
+// adoptRemoteBranch puts the local branch on top of remote/remoteBranch,
+// renaming it to remoteBranch. unborn says whether the local branch has no commits yet.
+func (o *Operations) adoptRemoteBranch(ctx context.Context, remote, branch, remoteBranch string, unborn bool) error {
+	upstream := remote + "/" + remoteBranch
+
+	if unborn {
+		// Point the unborn branch at the remote commit but keep the working tree;
+		// template files that don't exist locally are then checked out
+		o.logger.Step("Starting %s from %s...", remoteBranch, upstream)
+		if branch != remoteBranch {
+			if err := o.runCommand(ctx, "git", "symbolic-ref", "HEAD", "refs/heads/"+remoteBranch); err != nil {
+				return fmt.Errorf("failed to rename branch %s to %s: %w", branch, remoteBranch, err)
+			}
+		}
+		if err := o.runCommand(ctx, "git", "reset", "--mixed", "--quiet", upstream); err != nil {
+			return fmt.Errorf("failed to start from %s: %w", upstream, err)
+		}
+		entries, err := o.GetStatus(ctx)
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, e := range entries {
+			if e.Index == ' ' && e.Worktree == 'D' {
+				missing = append(missing, e.Path)
+			}
+		}
+		if len(missing) > 0 {
+			args := append([]string{"checkout", "--"}, missing...)
+			if err := o.runCommand(ctx, "git", args...); err != nil {
+				return fmt.Errorf("failed to check out %s: %w", strings.Join(missing, ", "), err)
+			}
+		}
+		o.logger.Success("Started %s from %s", remoteBranch, upstream)
+		return nil
+	}
+
+	if branch != remoteBranch {
+		o.logger.Step("Renaming branch %s to %s...", branch, remoteBranch)
+		if err := o.runCommand(ctx, "git", "branch", "-m", branch, remoteBranch); err != nil {
+			return fmt.Errorf("failed to rename branch %s to %s: %w", branch, remoteBranch, err)
+		}
+	}
+	if err := o.PullRebase(ctx, remote, remoteBranch); err != nil {
+		if branch != remoteBranch {
+			_ = o.runCommand(ctx, "git", "branch", "-m", remoteBranch, branch)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DirtyPolicy decides what happens to local changes when switching branches
+type DirtyPolicy string
+
+const (
+	// DirtyAbort refuses to switch when the working tree has changes
+	DirtyAbort DirtyPolicy = "abort"
+	// DirtyStash stashes local changes and restores them on the new branch
+	DirtyStash DirtyPolicy = "stash"
+	// DirtyCarry leaves it to git, which carries changes over when it can
+	DirtyCarry DirtyPolicy = "carry"
+)
+
+// ParseDirtyPolicy validates a policy name, defaulting to DirtyAbort when empty
+func ParseDirtyPolicy(s string) (DirtyPolicy, error) {
+	switch DirtyPolicy(s) {
+	case "":
+		return DirtyAbort, nil
+	case DirtyAbort, DirtyStash, DirtyCarry:
+		return DirtyPolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid dirty policy %q (expected abort, stash, or carry)", s)
+}
+
+// Checkout switches to the given branch, handling a dirty working tree according to policy
+func (o *Operations) Checkout(ctx context.Context, branch string, policy DirtyPolicy) error {
+	if err := o.requireWorktree(ctx, "switch branches"); err != nil {
+		return err
+	}
+
+	stashed := false
+	if policy != DirtyCarry {
+		clean, err := o.IsClean(ctx, true)
+		if err != nil {
+			return err
+		}
+		if !clean {
+			if policy == DirtyAbort {
+				o.logger.Error("Working tree has uncommitted changes")
+				return fmt.Errorf("working tree is dirty; commit or stash your changes, or use the stash/carry policy")
+			}
+			if stashed, err = o.Stash(ctx, "ghquick: switching to "+branch); err != nil {
+				return err
+			}
+		}
+	}
+
+	o.logger.Step("Switching to branch %s...", branch)
+	if err := o.runCommand(ctx, "git", "checkout", branch); err != nil {
+		o.logger.Error("Failed to switch branch")
+		if stashed {
+			o.logger.Warning("Your changes are still in the stash; restore them with 'ghquick stash pop'")
+		}
+		return fmt.Errorf("failed to checkout %s: %w", branch, err)
+	}
+	o.logger.Success("Switched to branch %s", branch)
+
+	if stashed {
+		return o.StashPop(ctx)
+	}
+	return nil
+}
+
+// CreateBranch creates name from base (a branch, remote branch or commit) and
+// switches to it, handling local changes according to policy
+func (o *Operations) CreateBranch(ctx context.Context, name, base string, policy DirtyPolicy) error {
+	if o.BranchExists(ctx, name) {
+		return fmt.Errorf("branch %s already exists; switch to it with 'ghquick branch switch %s'", name, name)
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git branch --no-track %s %s", name, base)
+		return nil
+	}
+
+	o.logger.Step("Creating branch %s from %s...", name, base)
+	if err := o.runCommand(ctx, "git", "branch", "--no-track", name, base); err != nil {
+		o.logger.Error("Failed to create branch")
+		return fmt.Errorf("failed to create branch %s from %s: %w", name, base, err)
+	}
+	if err := o.Checkout(ctx, name, policy); err != nil {
+		// Don't leave an unused branch behind when the switch was refused
+		if delErr := o.runCommand(ctx, "git", "branch", "-D", name); delErr != nil {
+			o.logger.Debug("Failed to remove branch %s: %v", name, delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// MoveToNewBranch creates name at HEAD and switches to it, carrying local
+// changes over, so commits a protected remote branch refused can be pushed
+// from name instead. branch itself is left alone; see ResetToRemote.
+func (o *Operations) MoveToNewBranch(ctx context.Context, name string) error {
+	if o.BranchExists(ctx, name) {
+		return fmt.Errorf("branch %s already exists", name)
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git checkout -b %s", name)
+		return nil
+	}
+
+	o.logger.Step("Moving your commits to branch %s...", name)
+	if err := o.runCommand(ctx, "git", "checkout", "-b", name); err != nil {
+		o.logger.Error("Failed to create branch")
+		return fmt.Errorf("failed to create branch %s: %w", name, err)
+	}
+	o.logger.Success("Switched to branch %s", name)
+	return nil
+}
+
+// ResetToRemote points branch, which must not be checked out, back at its copy
+// on remote, once the commits it had are safe elsewhere. A branch with no copy
+// on remote is left as is; failing to reset is only warned about.
+func (o *Operations) ResetToRemote(ctx context.Context, remote, branch string) {
+	tracking := remote + "/" + branch
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git branch -f %s %s", branch, tracking)
+		return
+	}
+	if _, err := o.output(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+tracking); err != nil {
+		o.logger.Debug("No %s to reset %s to; leaving it as is", tracking, branch)
+		return
+	}
+	if err := o.runCommand(ctx, "git", "branch", "-f", branch, tracking); err != nil {
+		o.logger.Warning("Failed to reset %s to %s: %v", branch, tracking, err)
+		return
+	}
+	o.logger.Success("%s is back at %s", branch, tracking)
+}
+
+// BranchInfo describes a local branch and how it compares to its upstream
+type BranchInfo struct {
+	Name     string
+	Current  bool
+	Upstream string
+	Ahead    int
+	Behind   int
+	// Gone is set when the upstream branch was deleted on the remote
+	Gone bool
+}
+
+var trackCount = regexp.MustCompile(`(ahead|behind) (\d+)`)
+
+// ListBranches returns the local branches with their ahead/behind counts,
+// as of the last fetch
+func (o *Operations) ListBranches(ctx context.Context) ([]BranchInfo, error) {
+	out, err := o.output(ctx, "for-each-ref", "--format=%(refname:short)%00%(HEAD)%00%(upstream:short)%00%(upstream:track)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var branches []BranchInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		b := BranchInfo{Name: fields[0], Current: fields[1] == "*", Upstream: fields[2], Gone: fields[3] == "[gone]"}
+		for _, m := range trackCount.FindAllStringSubmatch(fields[3], -1) {
+			n, _ := strconv.Atoi(m[2])
+			if m[1] == "ahead" {
+				b.Ahead = n
+			} else {
+				b.Behind = n
+			}
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// MergedBranches returns the local branches fully merged into base, other than
+// base itself and the checked out branch
+func (o *Operations) MergedBranches(ctx context.Context, base string) ([]string, error) {
+	out, err := o.output(ctx, "for-each-ref", "--merged", base, "--format=%(refname:short)%00%(HEAD)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches merged into %s: %w", base, err)
+	}
+	var merged []string
+	for _, line := range strings.Split(out, "\n") {
+		name, head, ok := strings.Cut(line, "\x00")
+		// base may be a remote branch like origin/main; its local namesake stays too
+		if !ok || head == "*" || name == base || strings.HasSuffix(base, "/"+name) {
+			continue
+		}
+		merged = append(merged, name)
+	}
+	return merged, nil
+}
+
+// RemoteBranchExists reports whether a remote-tracking branch remote/name exists
+func (o *Operations) RemoteBranchExists(ctx context.Context, remote, name string) bool {
+	_, err := o.output(ctx, "show-ref", "--verify", "--quiet", "refs/remotes/"+remote+"/"+name)
+	return err == nil
+}
+
+// DeleteBranch deletes a local branch, refusing unmerged work unless force is
+// set. With a remote, the branch is deleted there too.
+func (o *Operations) DeleteBranch(ctx context.Context, name, remote string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git branch %s %s", flag, name)
+		if remote != "" {
+			o.logger.Info("[dry-run] Would run: git push %s --delete %s", remote, name)
+		}
+		return nil
+	}
+
+	o.logger.Step("Deleting branch %s...", name)
+	if err := o.runCommand(ctx, "git", "branch", flag, name); err != nil {
+		o.logger.Error("Failed to delete branch %s", name)
+		if strings.Contains(err.Error(), "not fully merged") {
+			return fmt.Errorf("branch %s has unmerged commits; pass --force to delete it anyway", name)
+		}
+		return fmt.Errorf("failed to delete branch %s: %w", name, err)
+	}
+	if remote != "" {
+		if err := o.runCommand(ctx, "git", "push", remote, "--delete", name); err != nil {
+			if !strings.Contains(err.Error(), "remote ref does not exist") {
+				o.logger.Error("Failed to delete %s on %s", name, remote)
+				return fmt.Errorf("deleted %s locally but not on %s: %w", name, remote, err)
+			}
+			o.logger.Debug("%s has no branch %s", remote, name)
+		}
+	}
+	o.logger.Success("Deleted branch %s", name)
+	return nil
+}
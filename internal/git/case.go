@@ -0,0 +1,81 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CaseRename is a tracked file renamed on disk by changing only its letter case
+type CaseRename struct {
+	From string
+	To   string
+}
+
+// CaseOnlyRenames finds tracked files whose name on disk differs from the index
+// only in letter case. On case-insensitive filesystems (core.ignorecase) git
+// doesn't notice such renames, so the commit would keep the old casing.
+func (o *Operations) CaseOnlyRenames(ctx context.Context) ([]CaseRename, error) {
+	if ignoreCase, _ := o.output(ctx, "config", "--bool", "core.ignorecase"); ignoreCase != "true" {
+		return nil, nil
+	}
+	root, err := o.output(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository root: %w", err)
+	}
+	out, err := o.output(ctx, "ls-files", "-z", "--full-name", ":/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+
+	listings := make(map[string][]string)
+	var renames []CaseRename
+	for _, tracked := range splitNames(out) {
+		dir, name := path.Split(tracked)
+		entries, ok := listings[dir]
+		if !ok {
+			dirEntries, _ := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+			for _, e := range dirEntries {
+				entries = append(entries, e.Name())
+			}
+			listings[dir] = entries
+		}
+
+		exact, folded := false, ""
+		for _, e := range entries {
+			if e == name {
+				exact = true
+				break
+			}
+			if strings.EqualFold(e, name) {
+				folded = e
+			}
+		}
+		if !exact && folded != "" {
+			renames = append(renames, CaseRename{From: tracked, To: dir + folded})
+		}
+	}
+	return renames, nil
+}
+
+// stageCaseRenames records case-only renames in the index in two steps, since
+// `git add` alone can't see them on a case-insensitive filesystem
+func (o *Operations) stageCaseRenames(ctx context.Context) error {
+	renames, err := o.CaseOnlyRenames(ctx)
+	if err != nil || len(renames) == 0 {
+		return err
+	}
+	for _, r := range renames {
+		o.logger.Warning("Case-only rename %s → %s; staging it explicitly because this filesystem ignores case", r.From, r.To)
+		if err := o.runCommand(ctx, "git", "rm", "--cached", "-q", "--", ":/"+r.From); err != nil {
+			return fmt.Errorf("failed to stage rename of %s: %w", r.From, err)
+		}
+		if err := o.runCommand(ctx, "git", "add", "--", ":/"+r.To); err != nil {
+			return fmt.Errorf("failed to stage rename to %s: %w", r.To, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ChangelogEntry is one commit classified by its Conventional Commit type
+type ChangelogEntry struct {
+	SHA      string `json:"sha"`
+	Type     string `json:"type"`
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Breaking bool   `json:"breaking,omitempty"`
+}
+
+// ChangelogGroup collects the commits of one type
+type ChangelogGroup struct {
+	Type    string           `json:"type"`
+	Title   string           `json:"title"`
+	Commits []ChangelogEntry `json:"commits"`
+}
+
+// ChangelogData is the classified commit range, grouped in changelog order
+type ChangelogData struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Groups   []ChangelogGroup `json:"groups"`
+	Breaking []ChangelogEntry `json:"breaking,omitempty"`
+}
+
+// changelogTypes lists the known types in the order groups are emitted
+var changelogTypes = []struct{ Type, Title string }{
+	{"feat", "Features"},
+	{"fix", "Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+	{"revert", "Reverts"},
+	{"other", "Other"},
+}
+
+// commitHeader matches "type(scope)!: description"
+var commitHeader = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.*)$`)
+
+// ClassifyCommits reads the commits in from..to (to defaults to HEAD, an empty
+// from means the whole history) and groups them by Conventional Commit type.
+// Subjects that don't follow the convention land in the "other" group.
+func (o *Operations) ClassifyCommits(ctx context.Context, from, to string) (ChangelogData, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	revRange := to
+	if from != "" {
+		revRange = from + ".." + to
+	}
+
+	entries, err := o.GetLog(ctx, revRange, 0)
+	if err != nil {
+		return ChangelogData{}, err
+	}
+
+	byType := make(map[string][]ChangelogEntry)
+	data := ChangelogData{From: from, To: to}
+	for _, e := range entries {
+		entry := classifyCommit(e)
+		byType[entry.Type] = append(byType[entry.Type], entry)
+		if entry.Breaking {
+			data.Breaking = append(data.Breaking, entry)
+		}
+	}
+
+	data.Groups = []ChangelogGroup{}
+	for _, t := range changelogTypes {
+		if commits := byType[t.Type]; len(commits) > 0 {
+			data.Groups = append(data.Groups, ChangelogGroup{Type: t.Type, Title: t.Title, Commits: commits})
+		}
+	}
+	return data, nil
+}
+
+// classifyCommit parses the type, scope, and breaking marker from a log entry
+func classifyCommit(e LogEntry) ChangelogEntry {
+	entry := ChangelogEntry{SHA: e.SHA, Type: "other", Subject: e.Subject}
+	if m := commitHeader.FindStringSubmatch(e.Subject); m != nil {
+		t := strings.ToLower(m[1])
+		for _, known := range changelogTypes {
+			if known.Type == t {
+				entry.Type = t
+				entry.Scope = m[2]
+				entry.Subject = m[4]
+				entry.Breaking = m[3] == "!"
+				break
+			}
+		}
+	}
+	if strings.Contains(e.Body, "BREAKING CHANGE:") || strings.Contains(e.Body, "BREAKING-CHANGE:") {
+		entry.Breaking = true
+	}
+	return entry
+}
+
+// Markdown renders the classified commits as release notes: breaking changes
+// first, then one section per type with a bullet per commit
+func (d ChangelogData) Markdown() string {
+	var b strings.Builder
+	if len(d.Breaking) > 0 {
+		b.WriteString("### ⚠ Breaking Changes\n\n")
+		for _, e := range d.Breaking {
+			b.WriteString(e.markdownLine())
+		}
+		b.WriteString("\n")
+	}
+	for _, g := range d.Groups {
+		fmt.Fprintf(&b, "### %s\n\n", g.Title)
+		for _, e := range g.Commits {
+			b.WriteString(e.markdownLine())
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// markdownLine formats the entry as "- **scope:** subject (sha)"
+func (e ChangelogEntry) markdownLine() string {
+	sha := e.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	if e.Scope != "" {
+		return fmt.Sprintf("- **%s:** %s (%s)\n", e.Scope, e.Subject, sha)
+	}
+	return fmt.Sprintf("- %s (%s)\n", e.Subject, sha)
+}
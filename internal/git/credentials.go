@@ -0,0 +1,43 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// credentialHelper answers git's credential requests from the environment
+// credentialEnv sets up, so the token never appears in a command line
+const credentialHelper = `!f() { test "$1" = get && printf 'username=%s\npassword=%s\n' "$GHQUICK_GIT_USERNAME" "$GHQUICK_GIT_TOKEN"; }; f`
+
+// credentialEnv returns the environment that hands the token to git through a
+// credential helper for commands that talk to a remote, instead of embedding
+// it in the remote URL where it ends up in .git/config and error output.
+// Other helpers configured for the host are dropped for that command.
+func (o *Operations) credentialEnv(args []string) []string {
+	if !isNetworkCommand(args) {
+		return nil
+	}
+	username, token := o.credentials()
+	if token == "" {
+		return nil
+	}
+	if username == "" {
+		// GitHub ignores the username when the password is a token
+		username = "x-access-token"
+	}
+
+	// Add to, rather than replace, config the caller passed the same way
+	n, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	key := "credential.https://" + o.host() + ".helper"
+	return []string{
+		"GHQUICK_GIT_USERNAME=" + username,
+		"GHQUICK_GIT_TOKEN=" + token,
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+2),
+		// An empty helper resets the list configured so far
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", n, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=", n),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", n+1, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n+1, credentialHelper),
+	}
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"strings"
+)
+
+// ChangeType describes how a file was changed in a diff
+type ChangeType string
+
+const (
+	ChangeModified ChangeType = "modified"
+	ChangeAdded    ChangeType = "added"
+	ChangeDeleted  ChangeType = "deleted"
+	ChangeRenamed  ChangeType = "renamed"
+)
+
+// FileDiff holds the parsed changes for a single file in a unified diff
+type FileDiff struct {
+	Path    string
+	OldPath string
+	Change  ChangeType
+	Binary  bool
+	// Symlink is set when the new side is a symbolic link; its "content" is the target path
+	Symlink   bool
+	Additions int
+	Deletions int
+	Patch     string
+}
+
+// ParseDiff splits a unified diff (as produced by `git diff`) into per-file entries
+func ParseDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var current *FileDiff
+	var patch strings.Builder
+	inHunk := false
+
+	flush := func() {
+		if current != nil {
+			current.Patch = patch.String()
+			files = append(files, *current)
+		}
+		patch.Reset()
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			oldPath, newPath := parseDiffHeader(line)
+			current = &FileDiff{Path: newPath, OldPath: oldPath, Change: ChangeModified}
+			inHunk = false
+		}
+		if current == nil {
+			continue
+		}
+		patch.WriteString(line)
+		patch.WriteString("\n")
+
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Additions++
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Deletions++
+		case inHunk:
+			// context line
+		case strings.HasPrefix(line, "new file mode"):
+			current.Change = ChangeAdded
+			current.Symlink = strings.HasSuffix(line, " "+symlinkMode)
+		case strings.HasPrefix(line, "new mode "):
+			current.Symlink = strings.HasSuffix(line, " "+symlinkMode)
+		case strings.HasPrefix(line, "index ") && strings.HasSuffix(line, " "+symlinkMode):
+			current.Symlink = true
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Change = ChangeDeleted
+		case strings.HasPrefix(line, "rename from "):
+			current.Change = ChangeRenamed
+			current.OldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ"):
+			current.Binary = true
+		case strings.HasPrefix(line, "+++ b/"):
+			current.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "--- a/"):
+			current.OldPath = strings.TrimPrefix(line, "--- a/")
+		}
+	}
+	flush()
+
+	for i := range files {
+		files[i].Patch = strings.TrimSuffix(files[i].Patch, "\n")
+		if files[i].Change != ChangeRenamed {
+			files[i].OldPath = ""
+		}
+	}
+	return files
+}
+
+// parseDiffHeader extracts the old and new paths from a "diff --git a/x b/y" line
+func parseDiffHeader(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.Index(rest, " b/"); idx >= 0 && strings.HasPrefix(rest, "a/") {
+		return rest[2:idx], rest[idx+3:]
+	}
+	return rest, rest
+}
+
+// StripBinary removes binary file entries from the diff and returns the remaining
+// text diff along with the paths of the binary files that were removed
+func StripBinary(diff string) (string, []string) {
+	var text []string
+	var binaries []string
+	for _, f := range ParseDiff(diff) {
+		if f.Binary {
+			binaries = append(binaries, f.Path)
+			continue
+		}
+		text = append(text, f.Patch)
+	}
+	if len(binaries) == 0 {
+		return diff, nil
+	}
+	return strings.Join(text, "\n"), binaries
+}
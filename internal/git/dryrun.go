@@ -0,0 +1,35 @@
+package git
+
+import "strings"
+
+// dryRunSafe reports whether a git command still runs in dry-run mode: it only
+// talks to a remote or refreshes remote-tracking refs, or it only changes the
+// index while IsolateIndex has swapped in a throwaway copy
+func dryRunSafe(args []string, isolatedIndex bool) bool {
+	if len(args) == 0 {
+		return true
+	}
+	switch args[0] {
+	case "fetch", "ls-remote":
+		return true
+	case "add", "update-index", "read-tree":
+		return isolatedIndex
+	case "rm", "reset":
+		// Only the index-only forms: rm --cached and reset -- <paths>
+		for _, a := range args[1:] {
+			if a == "--cached" || a == "--" {
+				return isolatedIndex
+			}
+		}
+	}
+	return false
+}
+
+// dryRunLine formats a skipped git command, hiding credentials in remote URLs
+func dryRunLine(args []string) string {
+	shown := make([]string, len(args))
+	for i, a := range args {
+		shown[i] = redactURL(a)
+	}
+	return "git " + strings.Join(shown, " ")
+}
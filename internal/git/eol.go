@@ -0,0 +1,165 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// EOL is a target line ending for staged text files
+type EOL string
+
+const (
+	EOLLF   EOL = "lf"
+	EOLCRLF EOL = "crlf"
+	// EOLAuto uses the platform's native line ending
+	EOLAuto EOL = "auto"
+)
+
+// ParseEOL validates a line ending name
+func ParseEOL(s string) (EOL, error) {
+	switch EOL(strings.ToLower(s)) {
+	case EOLLF:
+		return EOLLF, nil
+	case EOLCRLF:
+		return EOLCRLF, nil
+	case EOLAuto:
+		return EOLAuto, nil
+	}
+	return "", fmt.Errorf("invalid eol %q (expected lf, crlf, or auto)", s)
+}
+
+// resolve maps auto to the platform's native line ending
+func (e EOL) resolve() EOL {
+	if e != EOLAuto {
+		return e
+	}
+	if runtime.GOOS == "windows" {
+		return EOLCRLF
+	}
+	return EOLLF
+}
+
+// NormalizeLineEndings rewrites staged text files to the target line ending and
+// re-stages them. Files marked binary or -text in .gitattributes are skipped,
+// and an explicit eol attribute overrides the target for that file. It returns
+// the paths that were rewritten.
+func (o *Operations) NormalizeLineEndings(ctx context.Context, target EOL) ([]string, error) {
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsStaged() && e.Index != 'D' {
+			paths = append(paths, e.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	attrs, err := o.checkAttrs(ctx, paths, "binary", "text", "eol")
+	if err != nil {
+		return nil, err
+	}
+
+	var rewritten []string
+	for _, p := range paths {
+		a := attrs[p]
+		if a["binary"] == "set" || a["text"] == "unset" {
+			continue
+		}
+		eol := target.resolve()
+		switch a["eol"] {
+		case "lf":
+			eol = EOLLF
+		case "crlf":
+			eol = EOLCRLF
+		}
+
+		changed, err := rewriteLineEndings(filepath.Join(o.workingDir, p), eol)
+		if err != nil {
+			return rewritten, err
+		}
+		if changed {
+			o.logger.Debug("Normalized line endings in %s to %s", p, eol)
+			rewritten = append(rewritten, p)
+		}
+	}
+
+	if len(rewritten) > 0 {
+		args := append([]string{"add", "--"}, rewritten...)
+		if err := o.runCommand(ctx, "git", args...); err != nil {
+			return rewritten, fmt.Errorf("failed to re-stage normalized files: %w", err)
+		}
+		o.logger.Info("Normalized line endings in %d file(s)", len(rewritten))
+	}
+	return rewritten, nil
+}
+
+// checkAttrs returns the requested gitattributes for each path
+func (o *Operations) checkAttrs(ctx context.Context, paths []string, names ...string) (map[string]map[string]string, error) {
+	args := append([]string{"check-attr"}, names...)
+	args = append(args, "--")
+	args = append(args, paths...)
+	output, err := o.output(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gitattributes: %w", err)
+	}
+
+	attrs := make(map[string]map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		// "<path>: <attr>: <value>"
+		parts := strings.Split(line, ": ")
+		if len(parts) < 3 {
+			continue
+		}
+		path := strings.Join(parts[:len(parts)-2], ": ")
+		if attrs[path] == nil {
+			attrs[path] = make(map[string]string)
+		}
+		attrs[path][parts[len(parts)-2]] = parts[len(parts)-1]
+	}
+	return attrs, nil
+}
+
+// rewriteLineEndings converts a regular text file in place; binary files are left alone
+func rewriteLineEndings(path string, eol EOL) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if isBinary(data) {
+		return false, nil
+	}
+
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if eol == EOLCRLF {
+		normalized = bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+	}
+	if bytes.Equal(normalized, data) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, normalized, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// isBinary uses git's heuristic: a NUL byte in the first 8000 bytes
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
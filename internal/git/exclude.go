@@ -0,0 +1,80 @@
+package git
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// SetExcludePatterns keeps paths matching any of the glob patterns out of
+// StageAll, StageFiles and StageWithUntracked. A pattern without a slash
+// matches any path component (".env", "node_modules"); one with a slash is
+// matched against the path from the repository root ("build/*.log").
+func (o *Operations) SetExcludePatterns(patterns []string) {
+	o.excludes = nil
+	for _, p := range patterns {
+		if p = strings.Trim(strings.TrimSpace(p), "/"); p != "" {
+			o.excludes = append(o.excludes, p)
+		}
+	}
+}
+
+// excluded reports whether a repository-relative path matches an exclude pattern
+func (o *Operations) excluded(p string) bool {
+	p = strings.TrimSuffix(p, "/")
+	parts := strings.Split(p, "/")
+	for _, pattern := range o.excludes {
+		if !strings.Contains(pattern, "/") {
+			for _, part := range parts {
+				if ok, _ := path.Match(pattern, part); ok {
+					return true
+				}
+			}
+			continue
+		}
+		// Match the path and each of its parent directories, so a pattern
+		// naming a directory excludes everything below it
+		for i := len(parts); i > 0; i-- {
+			if ok, _ := path.Match(pattern, strings.Join(parts[:i], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// enforceExcludes unstages staged paths matching the exclude patterns. New
+// files are dropped quietly, but changes to tracked files are reported loudly
+// since leaving them out of the commit is rarely what was meant.
+func (o *Operations) enforceExcludes(ctx context.Context) error {
+	if len(o.excludes) == 0 {
+		return nil
+	}
+
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	var drop []string
+	newFiles := 0
+	for _, e := range entries {
+		if !e.IsStaged() || !o.excluded(e.Path) {
+			continue
+		}
+		drop = append(drop, e.Path)
+		if e.Index == 'A' {
+			newFiles++
+			o.logger.Debug("Excluding new file %s", e.Path)
+		} else {
+			o.logger.Warning("%s is tracked and has changes, but matches an exclude pattern; the change is NOT being committed", e.Path)
+		}
+	}
+	if len(drop) == 0 {
+		return nil
+	}
+	if newFiles > 0 {
+		o.logger.Info("Excluded %d new file(s) matching %s", newFiles, strings.Join(o.excludes, ", "))
+	}
+	return o.Unstage(ctx, drop)
+}
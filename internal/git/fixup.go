@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fixup folds the staged changes into an earlier commit: it commits them as
+// "fixup! <subject>" and autosquashes them into target with a non-interactive
+// rebase. Unstaged changes are stashed around the rebase. When the rebase
+// stops, it is aborted and the fixup commit is left on top of the branch.
+func (o *Operations) Fixup(ctx context.Context, target string) error {
+	if err := o.requireWorktree(ctx, "fix up a commit"); err != nil {
+		return err
+	}
+	sha, err := o.output(ctx, "rev-parse", "--verify", "--quiet", target+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("unknown commit %s", target)
+	}
+	if !o.IsAncestor(ctx, sha) {
+		return fmt.Errorf("%s is not in the current branch's history", target)
+	}
+	if err := o.refuseMerge(ctx, sha, "fix up"); err != nil {
+		return err
+	}
+
+	o.logger.Step("Committing fixup for %s...", sha[:7])
+	if err := o.runCommit(ctx, o.identityEnv(), "--fixup="+sha); err != nil {
+		o.logger.Error("Failed to commit fixup")
+		return fmt.Errorf("failed to commit fixup: %w", err)
+	}
+
+	// The sequence editor accepts the todo list autosquash prepared as is
+	onto := []string{sha + "^"}
+	if _, err := o.output(ctx, "rev-parse", "--verify", "--quiet", sha+"^"); err != nil {
+		onto = []string{"--root"}
+	}
+	// Rewritten commits are signed the same way as new ones
+	args := append(append([]string{"rebase", "--interactive", "--autosquash", "--autostash"}, o.signArgs()...), onto...)
+	env := append(o.identityEnv(), "GIT_SEQUENCE_EDITOR=true")
+	o.logger.Step("Squashing the fixup into %s...", sha[:7])
+	if err := o.runCommandWithEnv(ctx, env, "git", args...); err != nil {
+		o.logger.Error("Failed to squash the fixup")
+		if o.rebaseInProgress(ctx) {
+			if abortErr := o.runCommand(ctx, "git", "rebase", "--abort"); abortErr != nil {
+				o.logger.Warning("Failed to abort the rebase; finish it or run 'git rebase --abort': %v", abortErr)
+			}
+		}
+		o.logger.Warning("The fixup commit is still on top of your branch; squash it later with 'git rebase -i --autosquash %s^'", sha[:7])
+		return fmt.Errorf("failed to squash fixup into %s: %w", sha[:7], err)
+	}
+	o.logger.Success("Folded the changes into %s", sha[:7])
+	return nil
+}
+
+// SharedBranch lists reasons rewriting remote/branch could discard someone
+// else's work: it is the remote's default branch (base), it has commits HEAD
+// doesn't, or commits on it since base were authored by someone other than
+// the configured user.email. No reasons means only you have pushed to it.
+func (o *Operations) SharedBranch(ctx context.Context, remote, branch, base string) ([]string, error) {
+	var reasons []string
+	if branch == base {
+		reasons = append(reasons, fmt.Sprintf("%s is %s's default branch", branch, remote))
+	}
+	tracking := remote + "/" + branch
+	if !o.RemoteBranchExists(ctx, remote, branch) {
+		return reasons, nil
+	}
+
+	ahead, err := o.output(ctx, "rev-list", "--count", "HEAD.."+tracking)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with %s: %w", tracking, err)
+	}
+	if n, _ := strconv.Atoi(ahead); n > 0 {
+		reasons = append(reasons, fmt.Sprintf("%s has %d commit(s) you don't have locally", tracking, n))
+	}
+
+	if branch == base {
+		return reasons, nil
+	}
+	revRange := tracking
+	if o.RemoteBranchExists(ctx, remote, base) {
+		revRange = remote + "/" + base + ".." + tracking
+	}
+	// Both sides go through .mailmap, so your other addresses don't count as others
+	emails, err := o.output(ctx, "log", "--use-mailmap", "--format=%aE", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the authors of %s: %w", tracking, err)
+	}
+	me, _ := o.output(ctx, "config", "user.email")
+	if me != "" {
+		if mapped, err := o.output(ctx, "check-mailmap", "<"+me+">"); err == nil {
+			if start, end := strings.LastIndex(mapped, "<"), strings.LastIndex(mapped, ">"); start >= 0 && end > start {
+				me = mapped[start+1 : end]
+			}
+		}
+	}
+	others := map[string]bool{}
+	for _, email := range strings.Split(emails, "\n") {
+		if email != "" && !strings.EqualFold(email, me) {
+			others[email] = true
+		}
+	}
+	if len(others) > 0 {
+		authors := make([]string, 0, len(others))
+		for email := range others {
+			authors = append(authors, email)
+		}
+		sort.Strings(authors)
+		reasons = append(reasons, fmt.Sprintf("%s has commits by %s", tracking, strings.Join(authors, ", ")))
+	}
+	return reasons, nil
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// lockFiles are dependency lock files, whose hashes and versions say nothing a
+// commit message needs
+var lockFiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true,
+	"pnpm-lock.yaml": true, "bun.lockb": true, "Cargo.lock": true, "poetry.lock": true, "Pipfile.lock": true,
+	"uv.lock": true, "composer.lock": true, "Gemfile.lock": true, "mix.lock": true, "Podfile.lock": true,
+	"flake.lock": true, "pubspec.lock": true, "packages.lock.json": true,
+}
+
+// generatedSuffixes mark files written by code generators or minifiers
+var generatedSuffixes = []string{
+	".pb.go", ".pb.gw.go", "_pb2.py", "_pb2_grpc.py", "_generated.go", ".gen.go",
+	".min.js", ".min.css", ".js.map", ".css.map", ".snap",
+}
+
+// generatedHeader matches an added line that is Go's "// Code generated ... DO
+// NOT EDIT." marker for generated files, so source that only mentions the
+// phrase isn't mistaken for generated
+var generatedHeader = regexp.MustCompile(`(?m)^\+// Code generated .* DO NOT EDIT\.\r?$`)
+
+// IsGenerated reports whether p is a dependency lock file or a generated or
+// minified file, judging by its name
+func IsGenerated(p string) bool {
+	base := path.Base(p)
+	if lockFiles[base] || strings.HasPrefix(base, "zz_generated") {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripGenerated removes lock files and generated files (by name, or by a
+// "Code generated ... DO NOT EDIT." header in the patch) from the diff and
+// returns the remaining diff along with the removed entries
+func StripGenerated(diff string) (string, []FileDiff) {
+	var kept []string
+	var generated []FileDiff
+	for _, f := range ParseDiff(diff) {
+		if !f.Binary && (IsGenerated(f.Path) || generatedHeader.MatchString(f.Patch)) {
+			generated = append(generated, f)
+			continue
+		}
+		kept = append(kept, f.Patch)
+	}
+	if len(generated) == 0 {
+		return diff, nil
+	}
+	return strings.Join(kept, "\n"), generated
+}
@@ -0,0 +1,507 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/saint/ghquick/internal/strutil"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// goGitBackend is the Backend that works on the repository in-process with
+// go-git. The repository is opened afresh for every call, so it sees what git
+// commands run in between changed.
+type goGitBackend struct {
+	o *Operations
+	// mu serializes index writes, which go-git doesn't lock against each other
+	mu sync.Mutex
+}
+
+// open opens the repository holding the working directory. While the index is
+// isolated (see IsolateIndex), the copy is read and written instead of the real one.
+func (b *goGitBackend) open() (*gogit.Repository, *gogit.Worktree, error) {
+	repo, err := gogit.PlainOpenWithOptions(b.o.workingDir, &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return repo, nil, err
+	}
+	if b.o.indexFile != "" {
+		if repo, err = gogit.Open(&isolatedIndex{Storer: repo.Storer, path: b.o.indexFile}, wt.Filesystem); err != nil {
+			return nil, nil, err
+		}
+		if wt, err = repo.Worktree(); err != nil {
+			return nil, nil, err
+		}
+	}
+	// git also honours the global and system excludes files
+	root := osfs.New("/")
+	if ps, err := gitignore.LoadGlobalPatterns(root); err == nil {
+		wt.Excludes = append(wt.Excludes, ps...)
+	}
+	if ps, err := gitignore.LoadSystemPatterns(root); err == nil {
+		wt.Excludes = append(wt.Excludes, ps...)
+	}
+	return repo, wt, nil
+}
+
+func (b *goGitBackend) IsBare(ctx context.Context) (bool, error) {
+	_, _, err := b.open()
+	if errors.Is(err, gogit.ErrIsBareRepository) {
+		return true, nil
+	}
+	return false, err
+}
+
+func (b *goGitBackend) Status(ctx context.Context) ([]FileStatus, error) {
+	_, wt, err := b.open()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]FileStatus, 0, len(status))
+	for path, s := range status {
+		if s.Staging == gogit.Unmodified && s.Worktree == gogit.Unmodified {
+			continue
+		}
+		entries = append(entries, FileStatus{Path: filepath.ToSlash(path), Index: byte(s.Staging), Worktree: byte(s.Worktree)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+func (b *goGitBackend) Diff(ctx context.Context, staged bool) (string, error) {
+	repo, wt, err := b.open()
+	if err != nil {
+		return "", err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+	var head *object.Tree
+	if ref, err := repo.Head(); err == nil {
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return "", err
+		}
+		if head, err = commit.Tree(); err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", err
+	}
+
+	paths := make([]string, 0, len(status))
+	for path, s := range status {
+		code := s.Worktree
+		if staged {
+			code = s.Staging
+		}
+		if code != gogit.Unmodified && code != gogit.Untracked {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	patch := &goGitPatch{}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		name := filepath.ToSlash(path)
+		var from, to *patchFile
+		if staged {
+			if from, err = treeFile(repo, head, name); err != nil {
+				return "", err
+			}
+			to, err = indexFile(repo, idx, name)
+		} else {
+			if from, err = indexFile(repo, idx, name); err != nil {
+				return "", err
+			}
+			to, err = worktreeFile(wt.Filesystem.Root(), name)
+		}
+		if err != nil {
+			return "", err
+		}
+		if fp := newFilePatch(from, to); fp != nil {
+			patch.files = append(patch.files, fp)
+		}
+	}
+	if len(patch.files) == 0 {
+		return "", nil
+	}
+	var out strings.Builder
+	if err := fdiff.NewUnifiedEncoder(&out, fdiff.DefaultContextLines).Encode(patch); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (b *goGitBackend) Add(ctx context.Context, paths []string) error {
+	if b.o.dryRun && b.o.indexFile == "" {
+		b.o.logger.Info("[dry-run] Would stage %s", describePaths(paths))
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, wt, err := b.open()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return wt.AddWithOptions(&gogit.AddOptions{All: true})
+	}
+	for _, p := range paths {
+		rel, err := b.rootRelative(wt, p)
+		if err != nil {
+			return err
+		}
+		if err := wt.AddWithOptions(&gogit.AddOptions{Path: rel}); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func (b *goGitBackend) Reset(ctx context.Context, paths []string) error {
+	if b.o.dryRun && b.o.indexFile == "" {
+		b.o.logger.Info("[dry-run] Would unstage %s", describePaths(paths))
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	repo, wt, err := b.open()
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(paths))
+	for _, p := range paths {
+		rel, err := b.rootRelative(wt, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+	}
+	if _, err := repo.Head(); err == nil {
+		return wt.Restore(&gogit.RestoreOptions{Staged: true, Files: files})
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	// An unborn branch has no HEAD to reset to, so the entries are dropped
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if _, err := idx.Remove(filepath.ToSlash(f)); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+			return err
+		}
+	}
+	return repo.Storer.SetIndex(idx)
+}
+
+func (b *goGitBackend) Commit(ctx context.Context, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	repo, wt, err := b.open()
+	if err != nil {
+		return err
+	}
+	cfg, err := repo.ConfigScoped(gitconfig.SystemScope)
+	if err != nil {
+		return fmt.Errorf("failed to read git config: %w", err)
+	}
+	if b.o.signMode == SignOn || (b.o.signMode == SignDefault && cfg.Raw.Section("commit").Option("gpgsign") == "true") {
+		return errors.New("the go-git backend can't sign commits; set git_backend to exec, or commit unsigned with --no-gpg-sign")
+	}
+
+	// As with git: SetCommitIdentity, then GIT_AUTHOR_* and GIT_COMMITTER_*, then config
+	now := time.Now()
+	author := &object.Signature{
+		Name:  strutil.FirstNonEmpty(b.o.authorName, os.Getenv("GIT_AUTHOR_NAME"), b.o.userName, cfg.Author.Name, cfg.User.Name),
+		Email: strutil.FirstNonEmpty(b.o.authorEmail, os.Getenv("GIT_AUTHOR_EMAIL"), b.o.userEmail, cfg.Author.Email, cfg.User.Email),
+		When:  now,
+	}
+	committer := &object.Signature{
+		Name:  strutil.FirstNonEmpty(b.o.authorName, os.Getenv("GIT_COMMITTER_NAME"), b.o.userName, cfg.Committer.Name, cfg.User.Name),
+		Email: strutil.FirstNonEmpty(b.o.authorEmail, os.Getenv("GIT_COMMITTER_EMAIL"), b.o.userEmail, cfg.Committer.Email, cfg.User.Email),
+		When:  now,
+	}
+	if author.Name == "" || author.Email == "" || committer.Name == "" || committer.Email == "" {
+		return errors.New("no commit identity; set user.name and user.email in git config")
+	}
+	if _, err := wt.Commit(message, &gogit.CommitOptions{Author: author, Committer: committer}); err != nil {
+		if errors.Is(err, gogit.ErrEmptyCommit) {
+			return ErrNoChanges
+		}
+		return err
+	}
+	// What was staged is committed now
+	b.o.dropUndo(undoIndex)
+	return nil
+}
+
+// rootRelative turns a path relative to the working directory, as git takes
+// it, into one relative to the worktree root, as go-git takes it
+func (b *goGitBackend) rootRelative(wt *gogit.Worktree, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(b.o.workingDir, path)
+	}
+	rel, err := filepath.Rel(wt.Filesystem.Root(), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return rel, nil
+}
+
+// describePaths names the paths for a dry-run line
+func describePaths(paths []string) string {
+	if len(paths) == 0 {
+		return "all changes"
+	}
+	return strings.Join(paths, " ")
+}
+
+// isolatedIndex reads and writes the index at path instead of the repository's own
+type isolatedIndex struct {
+	storage.Storer
+	path string
+}
+
+func (s *isolatedIndex) Index() (*index.Index, error) {
+	idx := &index.Index{Version: 2}
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := index.NewDecoder(f).Decode(idx); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (s *isolatedIndex) SetIndex(idx *index.Index) error {
+	tmp := s.path + ".ghquick"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := index.NewEncoder(f).Encode(idx); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// patchFile is one side of a file's diff
+type patchFile struct {
+	path    string
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	content string
+}
+
+func (f *patchFile) Hash() plumbing.Hash     { return f.hash }
+func (f *patchFile) Mode() filemode.FileMode { return f.mode }
+func (f *patchFile) Path() string            { return f.path }
+
+// treeFile returns path as committed in tree, or nil when it isn't there
+func treeFile(repo *gogit.Repository, tree *object.Tree, path string) (*patchFile, error) {
+	if tree == nil {
+		return nil, nil
+	}
+	entry, err := tree.FindEntry(path)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return blobFile(repo, path, entry.Hash, entry.Mode)
+}
+
+// indexFile returns path as staged in idx, or nil when it isn't there
+func indexFile(repo *gogit.Repository, idx *index.Index, path string) (*patchFile, error) {
+	entry, err := idx.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return blobFile(repo, path, entry.Hash, entry.Mode)
+}
+
+func blobFile(repo *gogit.Repository, path string, hash plumbing.Hash, mode filemode.FileMode) (*patchFile, error) {
+	f := &patchFile{path: path, hash: hash, mode: mode}
+	if mode == filemode.Submodule {
+		return f, nil
+	}
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	f.content = string(data)
+	return f, nil
+}
+
+// worktreeFile returns path as it is on disk under root, or nil when it was deleted
+func worktreeFile(root, path string) (*patchFile, error) {
+	full := filepath.Join(root, filepath.FromSlash(path))
+	info, err := os.Lstat(full)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mode, err := filemode.NewFromOSFileMode(info.Mode())
+	if err != nil {
+		return nil, err
+	}
+	f := &patchFile{path: path, mode: mode}
+	switch {
+	case info.IsDir():
+		// A submodule's checkout; its commit isn't diffed here
+		f.mode = filemode.Submodule
+		return f, nil
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(full)
+		if err != nil {
+			return nil, err
+		}
+		f.content = target
+	default:
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return nil, err
+		}
+		f.content = string(data)
+	}
+	f.hash = plumbing.ComputeHash(plumbing.BlobObject, []byte(f.content))
+	return f, nil
+}
+
+// goGitPatch is the diff of a set of files, in the shape go-git's unified encoder prints
+type goGitPatch struct {
+	files []fdiff.FilePatch
+}
+
+func (p *goGitPatch) FilePatches() []fdiff.FilePatch { return p.files }
+func (p *goGitPatch) Message() string                { return "" }
+
+type goGitFilePatch struct {
+	from, to *patchFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+// newFilePatch diffs from into to, either of which is nil for a file that was
+// added or deleted; it returns nil when there is nothing to show
+func newFilePatch(from, to *patchFile) *goGitFilePatch {
+	if from == nil && to == nil {
+		return nil
+	}
+	if from != nil && to != nil && from.hash == to.hash && from.mode == to.mode {
+		return nil
+	}
+	fp := &goGitFilePatch{from: from, to: to}
+	if (from != nil && from.mode == filemode.Submodule) || (to != nil && to.mode == filemode.Submodule) {
+		return nil
+	}
+	var src, dst string
+	if from != nil {
+		src = from.content
+	}
+	if to != nil {
+		dst = to.content
+	}
+	if isBinary([]byte(src)) || isBinary([]byte(dst)) {
+		fp.binary = true
+		return fp
+	}
+	for _, d := range diff.Do(src, dst) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		}
+		fp.chunks = append(fp.chunks, &goGitChunk{content: d.Text, op: op})
+	}
+	return fp
+}
+
+func (p *goGitFilePatch) IsBinary() bool { return p.binary }
+
+// Files returns nil interfaces, not nil pointers, for a missing side, as the encoder checks for them
+func (p *goGitFilePatch) Files() (fdiff.File, fdiff.File) {
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p *goGitFilePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+type goGitChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *goGitChunk) Content() string       { return c.content }
+func (c *goGitChunk) Type() fdiff.Operation { return c.op }
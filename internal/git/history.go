@@ -0,0 +1,248 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IsMergeCommit reports whether the commit has more than one parent
+func (o *Operations) IsMergeCommit(ctx context.Context, sha string) (bool, error) {
+	output, err := o.output(ctx, "rev-list", "--parents", "-n", "1", sha)
+	if err != nil {
+		return false, fmt.Errorf("failed to read parents of %s: %w", sha, err)
+	}
+	// Output is "<sha> <parent1> [<parent2>...]"
+	return len(strings.Fields(output)) > 2, nil
+}
+
+// Amend replaces the HEAD commit with the current index and the given message.
+// An empty message keeps the existing one. Merge commits are refused because
+// amending them is easy to get wrong.
+func (o *Operations) Amend(ctx context.Context, message string) error {
+	if err := o.refuseMerge(ctx, "HEAD", "amend"); err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		if message == "" {
+			o.logger.Info("[dry-run] Would amend the last commit, keeping its message")
+		} else {
+			o.logger.Info("[dry-run] Would amend the last commit with message:\n%s", message)
+		}
+		return nil
+	}
+
+	o.logger.Step("Amending last commit...")
+	args := []string{"--amend", "--no-edit"}
+	if message != "" {
+		args = []string{"--amend", "-m", message}
+	}
+	if err := o.runCommit(ctx, nil, args...); err != nil {
+		o.logger.Error("Failed to amend commit")
+		return fmt.Errorf("failed to amend commit: %w", err)
+	}
+	o.logger.Success("Last commit amended")
+	return nil
+}
+
+// IsPushed reports whether rev is already on a remote-tracking branch, so
+// rewriting it would need a force-push
+func (o *Operations) IsPushed(ctx context.Context, rev string) (bool, error) {
+	output, err := o.output(ctx, "branch", "-r", "--contains", rev)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s was pushed: %w", rev, err)
+	}
+	return output != "", nil
+}
+
+// AmendDiff returns the diff an amended HEAD commit would have: the index
+// compared with HEAD's parent, or with an empty tree for a root commit
+func (o *Operations) AmendDiff(ctx context.Context) (string, error) {
+	base, err := o.amendBase(ctx)
+	if err != nil {
+		return "", err
+	}
+	diff, err := o.output(ctx, "diff", "--cached", base)
+	if err != nil {
+		return "", fmt.Errorf("failed to get amended diff: %w", err)
+	}
+	if diff == "" {
+		return "", ErrNoChanges
+	}
+	return diff, nil
+}
+
+// amendBase returns the parent of HEAD, or the empty tree for a root commit
+func (o *Operations) amendBase(ctx context.Context) (string, error) {
+	base, err := o.output(ctx, "rev-parse", "--verify", "--quiet", "HEAD^")
+	if err == nil {
+		return base, nil
+	}
+	if base, err = o.output(ctx, "hash-object", "-t", "tree", os.DevNull); err != nil {
+		return "", fmt.Errorf("failed to resolve the empty tree: %w", err)
+	}
+	return base, nil
+}
+
+// Reword replaces the message of the HEAD commit without touching its tree.
+// It refuses when changes are staged so they can't be folded into the commit.
+func (o *Operations) Reword(ctx context.Context, message string) error {
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("new commit message is empty")
+	}
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if staged := stagedPaths(entries); len(staged) > 0 {
+		o.logger.Error("Staged changes present: %s", strings.Join(staged, ", "))
+		return fmt.Errorf("%d staged file(s) would be folded into the commit; unstage them or use amend instead", len(staged))
+	}
+
+	o.logger.Step("Rewording last commit...")
+	// --only with no paths commits HEAD's tree as-is, ignoring the index
+	if err := o.runCommit(ctx, nil, "--amend", "--only", "--allow-empty", "-m", message); err != nil {
+		o.logger.Error("Failed to reword commit")
+		return fmt.Errorf("failed to reword commit: %w", err)
+	}
+	o.logger.Success("Last commit reworded")
+	return nil
+}
+
+// Undo soft-resets the HEAD commit, keeping its changes staged. Merge commits
+// are refused since resetting to a single parent silently drops the merge.
+func (o *Operations) Undo(ctx context.Context) error {
+	merge, err := o.IsMergeCommit(ctx, "HEAD")
+	if err != nil {
+		return err
+	}
+	if merge {
+		o.logger.Error("HEAD is a merge commit")
+		return fmt.Errorf("HEAD is a merge commit; undo it with 'git revert -m 1 HEAD' or 'git reset --hard HEAD~1' instead")
+	}
+
+	o.logger.Step("Undoing last commit...")
+	if err := o.runCommand(ctx, "git", "reset", "--soft", "HEAD~1"); err != nil {
+		o.logger.Error("Failed to undo commit")
+		return fmt.Errorf("failed to undo commit: %w", err)
+	}
+	o.logger.Success("Last commit undone; its changes are still staged")
+	return nil
+}
+
+// UndoAmend puts the branch back on previous, the commit as it was before it
+// was amended, keeping the amended changes staged
+func (o *Operations) UndoAmend(ctx context.Context, previous string) error {
+	o.logger.Step("Restoring the commit as it was before the amend...")
+	if err := o.runCommand(ctx, "git", "reset", "--soft", previous); err != nil {
+		o.logger.Error("Failed to undo amend")
+		return fmt.Errorf("failed to undo amend: %w", err)
+	}
+	o.logger.Success("Amend undone; its changes are still staged")
+	return nil
+}
+
+// Revert adds a commit on top of HEAD that reverses sha, with the given
+// message. It refuses while changes are staged. A revert that conflicts with
+// later commits is aborted, leaving the branch as it was.
+func (o *Operations) Revert(ctx context.Context, sha, message string) error {
+	if err := o.requireWorktree(ctx, "revert"); err != nil {
+		return err
+	}
+	if err := o.refuseMerge(ctx, sha, "revert"); err != nil {
+		return err
+	}
+	// The revert is staged on top of the index and aborting it resets the
+	// index, so anything already staged would end up in the revert commit or lost
+	if _, err := o.output(ctx, "diff", "--cached", "--quiet"); err != nil {
+		o.logger.Error("There are staged changes")
+		return fmt.Errorf("cannot revert %s with changes staged; commit or unstage them first", sha)
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would revert %s with message:\n%s", sha, message)
+		return nil
+	}
+
+	o.logger.Step("Reverting %s...", sha)
+	if err := o.runCommand(ctx, "git", "revert", "--no-commit", sha); err != nil {
+		o.logger.Error("Failed to revert %s", sha)
+		_ = o.runCommand(ctx, "git", "revert", "--abort")
+		return fmt.Errorf("failed to revert %s (later commits probably conflict with it; revert it by hand): %w", sha, err)
+	}
+	if err := o.runCommit(ctx, o.identityEnv(), "-m", message); err != nil {
+		o.logger.Error("Failed to commit the revert")
+		_ = o.runCommand(ctx, "git", "revert", "--abort")
+		return fmt.Errorf("failed to commit revert: %w", err)
+	}
+	o.logger.Success("Reverted %s", sha)
+	return nil
+}
+
+// IsAncestor reports whether sha is reachable from HEAD
+func (o *Operations) IsAncestor(ctx context.Context, sha string) bool {
+	_, err := o.output(ctx, "merge-base", "--is-ancestor", sha, "HEAD")
+	return err == nil
+}
+
+// CommitSubject returns the subject line of the given commit
+func (o *Operations) CommitSubject(ctx context.Context, rev string) (string, error) {
+	subject, err := o.output(ctx, "log", "-1", "--format=%s", rev)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", rev, err)
+	}
+	return subject, nil
+}
+
+// Squash combines the last n commits into one with the given message.
+// It refuses when any of them is a merge commit, or when changes are staged
+// so they can't be folded into the result.
+func (o *Operations) Squash(ctx context.Context, n int, message string) error {
+	if n < 2 {
+		return fmt.Errorf("squash needs at least 2 commits, got %d", n)
+	}
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("squashed commit message is empty")
+	}
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if staged := stagedPaths(entries); len(staged) > 0 {
+		o.logger.Error("Staged changes present: %s", strings.Join(staged, ", "))
+		return fmt.Errorf("%d staged file(s) would be folded into the squashed commit; unstage or commit them first", len(staged))
+	}
+	for i := 0; i < n; i++ {
+		if err := o.refuseMerge(ctx, "HEAD~"+strconv.Itoa(i), "squash"); err != nil {
+			return err
+		}
+	}
+
+	o.logger.Step("Squashing last %d commits...", n)
+	if err := o.runCommand(ctx, "git", "reset", "--soft", "HEAD~"+strconv.Itoa(n)); err != nil {
+		o.logger.Error("Failed to squash commits")
+		return fmt.Errorf("failed to squash commits: %w", err)
+	}
+	if err := o.runCommit(ctx, nil, "-m", message); err != nil {
+		o.logger.Error("Failed to commit squashed changes")
+		o.logger.Warning("The squashed changes are staged; restore the old history with 'git reset ORIG_HEAD'")
+		return fmt.Errorf("failed to commit squashed changes: %w", err)
+	}
+	o.logger.Success("Squashed %d commits", n)
+	return nil
+}
+
+// refuseMerge returns an error if rev is a merge commit
+func (o *Operations) refuseMerge(ctx context.Context, rev, action string) error {
+	merge, err := o.IsMergeCommit(ctx, rev)
+	if err != nil {
+		return err
+	}
+	if merge {
+		o.logger.Error("%s is a merge commit", rev)
+		return fmt.Errorf("refusing to %s: %s is a merge commit", action, rev)
+	}
+	return nil
+}
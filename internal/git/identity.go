@@ -0,0 +1,31 @@
+package git
+
+// SetCommitIdentity makes commits created by these operations use the given
+// author and committer, without touching git config (e.g. for bot commits in CI)
+func (o *Operations) SetCommitIdentity(name, email string) {
+	o.authorName = name
+	o.authorEmail = email
+}
+
+// SetGitUser sets the user.name and user.email EnsureGitSetup writes, e.g.
+// from a matching identities entry; empty values fall back to GITHUB_USERNAME
+// and GITHUB_EMAIL
+func (o *Operations) SetGitUser(name, email string) {
+	o.userName = name
+	o.userEmail = email
+}
+
+// identityEnv returns the environment overriding the commit author and committer
+func (o *Operations) identityEnv() []string {
+	if o.authorName == "" && o.authorEmail == "" {
+		return nil
+	}
+	var env []string
+	if o.authorName != "" {
+		env = append(env, "GIT_AUTHOR_NAME="+o.authorName, "GIT_COMMITTER_NAME="+o.authorName)
+	}
+	if o.authorEmail != "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+o.authorEmail, "GIT_COMMITTER_EMAIL="+o.authorEmail)
+	}
+	return env
+}
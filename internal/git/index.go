@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// IsolateIndex points every following git command at a temporary copy of the
+// index, so staging can be previewed (e.g. for a dry run) without touching the
+// real one. The returned function removes the copy and restores the real index.
+func (o *Operations) IsolateIndex(ctx context.Context) (func(), error) {
+	indexPath, err := o.output(ctx, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the index: %w", err)
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(o.workingDir, indexPath)
+	}
+
+	tmp, err := os.CreateTemp("", "ghquick-index-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	src, err := os.Open(indexPath)
+	switch {
+	case err == nil:
+		_, err = io.Copy(tmp, src)
+		src.Close()
+		tmp.Close()
+		if err != nil {
+			os.Remove(tmpPath)
+			return nil, fmt.Errorf("failed to copy the index: %w", err)
+		}
+	case os.IsNotExist(err):
+		// No commits or staged files yet; git treats a missing index as empty
+		tmp.Close()
+		os.Remove(tmpPath)
+	default:
+		tmp.Close()
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to read the index: %w", err)
+	}
+
+	o.indexFile = filepath.Clean(tmpPath)
+	o.logger.Debug("Using temporary index %s", o.indexFile)
+	return func() {
+		os.Remove(o.indexFile)
+		os.Remove(o.indexFile + ".lock")
+		o.indexFile = ""
+	}, nil
+}
+
+// commandEnv adds the isolated index, if any, and for network commands the
+// credentials (see credentialEnv) to extra environment variables for git args
+func (o *Operations) commandEnv(env, args []string) []string {
+	env = append(env, o.credentialEnv(args)...)
+	if o.indexFile != "" {
+		env = append(env, "GIT_INDEX_FILE="+o.indexFile)
+	}
+	return env
+}
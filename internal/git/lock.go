@@ -0,0 +1,119 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockStrategy decides what happens when git lock files exist before a command runs
+type LockStrategy string
+
+const (
+	// LockRemove deletes lock files immediately, assuming they are stale (the default)
+	LockRemove LockStrategy = "remove"
+	// LockWait polls until the lock files disappear, then errors after the wait limit
+	LockWait LockStrategy = "wait"
+	// LockError fails right away, leaving the lock files alone
+	LockError LockStrategy = "error"
+)
+
+// DefaultLockWait is how long LockWait polls when no duration is given
+const DefaultLockWait = 10 * time.Second
+
+const lockPollInterval = 200 * time.Millisecond
+
+// ParseLockStrategy validates a strategy name, defaulting to LockRemove when empty
+func ParseLockStrategy(s string) (LockStrategy, error) {
+	switch LockStrategy(s) {
+	case "":
+		return LockRemove, nil
+	case LockRemove, LockWait, LockError:
+		return LockStrategy(s), nil
+	}
+	return "", fmt.Errorf("invalid lock strategy %q (expected wait, remove, or error)", s)
+}
+
+// SetLockStrategy sets how lock files left by other git processes are handled.
+// wait is the polling limit for LockWait (DefaultLockWait when zero).
+func (o *Operations) SetLockStrategy(strategy LockStrategy, wait time.Duration) {
+	o.lockStrategy = strategy
+	o.lockWait = wait
+}
+
+// lockFiles returns the lock files that currently exist in the git directory
+func (o *Operations) lockFiles(gitDir string) []string {
+	var found []string
+	for _, name := range []string{"index.lock", "HEAD.lock"} {
+		lockFile := filepath.Join(gitDir, name)
+		if _, err := os.Stat(lockFile); err == nil {
+			found = append(found, lockFile)
+		}
+	}
+	return found
+}
+
+func (o *Operations) cleanupLocks(ctx context.Context) error {
+	gitDir, err := o.gitDir(ctx)
+	if err != nil {
+		// Not a repository yet (e.g. before git init), so there are no locks to clean
+		return nil
+	}
+	locks := o.lockFiles(gitDir)
+	if len(locks) == 0 {
+		return nil
+	}
+
+	switch o.lockStrategy {
+	case LockError:
+		o.logger.Error("Git lock file exists: %s", locks[0])
+		return fmt.Errorf("git lock file %s exists; another git process may be running", locks[0])
+	case LockWait:
+		return o.waitForLocks(ctx, gitDir)
+	}
+
+	for _, lockFile := range locks {
+		o.logger.Warning("Found stale lock file: %s", lockFile)
+		if err := os.Remove(lockFile); err != nil {
+			o.logger.Error("Failed to remove lock file: %s", lockFile)
+			return fmt.Errorf("failed to remove lock file %s: %w", lockFile, err)
+		}
+		o.logger.Success("Removed stale lock file: %s", lockFile)
+	}
+	return nil
+}
+
+// waitForLocks polls until no lock files remain or the wait limit passes
+func (o *Operations) waitForLocks(ctx context.Context, gitDir string) error {
+	wait := o.lockWait
+	if wait <= 0 {
+		wait = DefaultLockWait
+	}
+	o.logger.Step("Waiting up to %v for another git process to release its lock...", wait)
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			locks := o.lockFiles(gitDir)
+			if len(locks) == 0 {
+				return nil
+			}
+			o.logger.Error("Git lock file still present after %v: %s", wait, locks[0])
+			return fmt.Errorf("git lock file %s still exists after waiting %v", locks[0], wait)
+		case <-ticker.C:
+			if len(o.lockFiles(gitDir)) == 0 {
+				o.logger.Success("Lock released")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry is a single commit read from `git log`
+type LogEntry struct {
+	SHA     string
+	Author  string
+	Email   string
+	Date    time.Time
+	Subject string
+	Body    string
+}
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// GetLog returns commits in revRange (e.g. "v1.0..HEAD", or "" for HEAD), newest
+// first, limited to limit entries when limit > 0. Author identities are resolved
+// through .mailmap so people who used several emails appear once.
+func (o *Operations) GetLog(ctx context.Context, revRange string, limit int) ([]LogEntry, error) {
+	args := []string{"log", "--use-mailmap", "--format=" + strings.Join([]string{"%H", "%aN", "%aE", "%aI", "%s", "%b"}, fieldSep) + recordSep}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	if revRange != "" {
+		args = append(args, revRange)
+	}
+
+	output, err := o.output(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+
+	var entries []LogEntry
+	for _, record := range strings.Split(output, recordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, fieldSep, 6)
+		if len(fields) < 6 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		entries = append(entries, LogEntry{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    date,
+			Subject: fields[4],
+			Body:    strings.TrimSpace(fields[5]),
+		})
+	}
+	return entries, nil
+}
@@ -2,138 +2,437 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math/rand"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/retry"
+	"github.com/saint/ghquick/internal/strutil"
 )
 
+// ErrNoChanges is returned when there is nothing to stage, diff, or commit
+var ErrNoChanges = errors.New("no changes to commit")
+
+// ErrGitNotFound is returned when the git binary can't be found, e.g. in a
+// container image without git
+var ErrGitNotFound = errors.New("git is not installed")
+
 type Operations struct {
 	workingDir string
 	logger     *log.Logger
+	username   string
+	token      string
+	// tokenSource is asked for a token once, when neither SetCredentials nor
+	// GITHUB_TOKEN provided one
+	tokenSource func(username string) (string, error)
+	tokenOnce   sync.Once
+	sourced     string
+	dryRun      bool
+	gitDirPath  string
+
+	authorName  string
+	authorEmail string
+	userName    string
+	userEmail   string
+
+	allowedExts []string
+	strictExts  bool
+
+	lockStrategy LockStrategy
+	lockWait     time.Duration
+
+	maxLogLines   int
+	configRetries int
+	autoPrune     bool
+	retryPatterns []*regexp.Regexp
+	indexFile     string
+	globalUser    bool
+	excludes      []string
+	signMode      SignMode
+	signingKey    string
+	gitPath       string
+	forcePush     bool
+	noVerify      bool
+	followTags    bool
+	remoteScheme  RemoteScheme
+	remoteHost    string
+	retryPolicy   retry.Policy
+
+	networkTimeout time.Duration
+	localTimeout   time.Duration
+
+	// backend does status, diff, staging and commits (see SetBackend)
+	backend Backend
+
+	// undo holds what Rollback puts back if the run stops halfway
+	undoMu sync.Mutex
+	undo   []undoStep
 }
 
+// DefaultMaxLogLines caps command output shown in debug logs
+const DefaultMaxLogLines = 200
+
+// GitPathEnv names the environment variable that overrides the git binary
+const GitPathEnv = "GHQUICK_GIT_PATH"
+
 func NewOperations(workingDir string, debug bool) *Operations {
-	return &Operations{
-		workingDir: workingDir,
-		logger:     log.New(debug),
+	gitPath := os.Getenv(GitPathEnv)
+	if gitPath == "" {
+		gitPath = "git"
 	}
+	o := &Operations{
+		workingDir:  workingDir,
+		logger:      log.New(debug),
+		maxLogLines: DefaultMaxLogLines,
+		gitPath:     gitPath,
+	}
+	o.backend = &execBackend{o: o}
+	return o
+}
+
+// SetGitPath sets the git binary to run, for when the one on PATH is not the
+// right version (defaults to $GHQUICK_GIT_PATH, then "git")
+func (o *Operations) SetGitPath(path string) {
+	o.gitPath = path
 }
 
-func (o *Operations) cleanupLocks() error {
-	lockFiles := []string{
-		filepath.Join(o.workingDir, ".git", "index.lock"),
-		filepath.Join(o.workingDir, ".git", "HEAD.lock"),
+// gitCommand builds a git command that runs in the working directory, for
+// limitedOutput to run (see command)
+func (o *Operations) gitCommand(ctx context.Context, args ...string) *timedCmd {
+	return o.command(ctx, o.gitPath, args...)
+}
+
+// missingGit turns the error of a git binary that couldn't be started into
+// ErrGitNotFound, naming where it was looked for
+func (o *Operations) missingGit(err error) error {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w (looked for %q); install git, set %s to its path, or set git_backend: go-git for status, staging and commits", ErrGitNotFound, o.gitPath, GitPathEnv)
 	}
+	return err
+}
+
+// SetMaxLogOutput caps how many lines of command output are written to debug
+// logs (0 for no limit). Errors always carry the full output.
+func (o *Operations) SetMaxLogOutput(lines int) {
+	o.maxLogLines = lines
+}
 
-	for _, lockFile := range lockFiles {
-		if _, err := os.Stat(lockFile); err == nil {
-			o.logger.Warning("Found stale lock file: %s", lockFile)
-			if err := os.Remove(lockFile); err != nil {
-				o.logger.Error("Failed to remove lock file: %s", lockFile)
-				return fmt.Errorf("failed to remove lock file %s: %w", lockFile, err)
+// SetForcePush makes Push overwrite the remote branch with --force-with-lease,
+// for history that was rewritten locally (e.g. an amended pushed commit)
+func (o *Operations) SetForcePush(force bool) {
+	o.forcePush = force
+}
+
+// SetNoVerify makes commits and pushes skip git's own pre-commit, commit-msg
+// and pre-push hooks (--no-verify)
+func (o *Operations) SetNoVerify(noVerify bool) {
+	o.noVerify = noVerify
+}
+
+// SetFollowTags makes Push also send annotated tags that point at the pushed
+// commits (--follow-tags)
+func (o *Operations) SetFollowTags(follow bool) {
+	o.followTags = follow
+}
+
+// SetDryRun makes every git command that changes the repository, its config
+// or a remote log what it would run instead of running it. Commands that only
+// read, fetch, or stage into an isolated index (see IsolateIndex) still run.
+func (o *Operations) SetDryRun(dryRun bool) {
+	o.dryRun = dryRun
+}
+
+// SetCredentials sets the GitHub username and token git authenticates HTTPS remotes with.
+// When unset, GITHUB_USERNAME and GITHUB_TOKEN are read from the environment.
+func (o *Operations) SetCredentials(username, token string) {
+	o.username = username
+	o.token = token
+}
+
+// SetTokenSource sets where the token comes from when SetCredentials wasn't
+// given one and GITHUB_TOKEN is unset, e.g. the gh CLI or the OS keychain.
+// It is only asked when a command needs to authenticate to a remote.
+func (o *Operations) SetTokenSource(lookup func(username string) (string, error)) {
+	o.tokenSource = lookup
+}
+
+// credentials returns the configured username and token, falling back to the
+// environment and then the token source
+func (o *Operations) credentials() (string, string) {
+	username, token := o.username, o.token
+	if username == "" {
+		username = os.Getenv("GITHUB_USERNAME")
+	}
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" && o.tokenSource != nil {
+		o.tokenOnce.Do(func() {
+			var err error
+			if o.sourced, err = o.tokenSource(username); err != nil {
+				o.logger.Debug("No token for git: %v", err)
 			}
-			o.logger.Success("Removed stale lock file: %s", lockFile)
-		}
+		})
+		token = o.sourced
 	}
-	return nil
+	return username, token
 }
 
 func (o *Operations) runCommand(ctx context.Context, name string, args ...string) error {
+	return o.runCommandWithEnv(ctx, nil, name, args...)
+}
+
+// runCommandWithEnv runs a command with extra environment variables (KEY=value)
+func (o *Operations) runCommandWithEnv(ctx context.Context, env []string, name string, args ...string) error {
+	if o.dryRun && name == "git" && !dryRunSafe(args, o.indexFile != "") {
+		o.logger.Info("[dry-run] Would run: %s", dryRunLine(args))
+		return nil
+	}
+
 	// Clean up any stale locks before running git commands
+	stream := false
 	if name == "git" {
-		if err := o.cleanupLocks(); err != nil {
+		if err := o.cleanupLocks(ctx); err != nil {
 			return err
 		}
+		if stream = isStreamedCommand(args) && !log.Quiet(); stream {
+			args = withProgress(args)
+		}
+		if isNetworkCommand(args) {
+			return retry.Do(ctx, o.retryPolicy, o.IsRetryable, func(attempt int, delay time.Duration, err error) {
+				o.logger.Warning("git %s failed with a transient error; retrying in %v (attempt %d/%d)...", args[0], delay.Round(100*time.Millisecond), attempt+1, o.retryPolicy.MaxAttempts())
+				o.logger.Debug("Error: %v", err)
+			}, func() error {
+				return o.execCommand(ctx, env, stream, o.gitPath, args...)
+			})
+		}
+		name = o.gitPath
+	}
+	return o.execCommand(ctx, env, stream, name, args...)
+}
+
+// execCommand runs a command once in the working directory, returning its
+// combined output in the error when it fails. With stream, the output is also
+// shown as it arrives.
+func (o *Operations) execCommand(ctx context.Context, env []string, stream bool, name string, args ...string) error {
+	release, err := acquireSlot(ctx, o.logger)
+	if err != nil {
+		return err
 	}
+	defer release()
 
 	o.logger.Command(name, args...)
-	cmd := exec.CommandContext(ctx, name, args...)
-	cmd.Dir = o.workingDir
+	cmd := o.command(ctx, name, args...)
+	defer cmd.cancel()
+	// Only git itself is handed the credentials
+	gitArgs := args
+	if name != o.gitPath {
+		gitArgs = nil
+	}
+	if env = o.commandEnv(env, gitArgs); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stream {
+		w := newStreamWriter(o.logger)
+		cmd.Stdout, cmd.Stderr = w, w
+		err := cmd.Run()
+		w.Flush()
+		if name == o.gitPath && cmd.Process == nil {
+			return o.missingGit(err)
+		}
+		if err != nil {
+			return cmd.wrap(fmt.Errorf("%w: %s", err, w.String()))
+		}
+		return nil
+	}
 	if output, err := cmd.CombinedOutput(); err != nil {
-		o.logger.Debug("Command output: %s", string(output))
-		return fmt.Errorf("%w: %s", err, string(output))
+		if name == o.gitPath && cmd.Process == nil {
+			return o.missingGit(err)
+		}
+		o.logger.Debug("Command output: %s", log.TruncateLines(string(output), o.maxLogLines))
+		return cmd.wrap(fmt.Errorf("%w: %s", err, string(output)))
 	}
 	return nil
 }
 
+// SetGlobalGitUser makes EnsureGitSetup write user.name/user.email to the
+// global git config instead of the repository's own config
+func (o *Operations) SetGlobalGitUser(global bool) {
+	o.globalUser = global
+}
+
+// configureGitUser sets user.name and user.email (see SetGitUser) in the
+// repository's config unless SetGlobalGitUser was called. Values already set in
+// the repository are left alone, so an intentional per-repo identity is never
+// overwritten.
 func (o *Operations) configureGitUser(ctx context.Context) error {
 	o.logger.Step("Configuring git user...")
-	cmd := exec.CommandContext(ctx, "git", "config", "--global", "user.name", os.Getenv("GITHUB_USERNAME"))
-	cmd.Dir = o.workingDir
-	if err := cmd.Run(); err != nil {
-		o.logger.Error("Failed to set git username")
-		return fmt.Errorf("failed to set git user.name: %w", err)
+	username, _ := o.credentials()
+	scope := "--local"
+	if o.globalUser {
+		scope = "--global"
+	}
+
+	for _, setting := range []struct {
+		key, value string
+		explicit   bool
+	}{
+		{"user.name", strutil.FirstNonEmpty(o.userName, username), o.userName != ""},
+		{"user.email", strutil.FirstNonEmpty(o.userEmail, os.Getenv("GITHUB_EMAIL")), o.userEmail != ""},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		// Skipping writes that change nothing also avoids racing other
+		// invocations on a shared ~/.gitconfig
+		current, err := o.output(ctx, "config", scope, "--get", setting.key)
+		if err == nil && (current == setting.value || scope == "--local") {
+			if current != setting.value && setting.explicit {
+				o.logger.Warning("Keeping this repository's %s %q instead of %q; run 'git config --unset %s' to use the configured identity",
+					setting.key, current, setting.value, setting.key)
+			}
+			o.logger.Debug("Keeping %s %s = %s", scope, setting.key, current)
+			continue
+		}
+		if err := o.writeConfigWithRetry(ctx, scope, setting.key, setting.value); err != nil {
+			o.logger.Error("Failed to set git %s", setting.key)
+			return fmt.Errorf("failed to set git %s: %w", setting.key, err)
+		}
 	}
 	o.logger.Success("Git user configured")
 	return nil
 }
 
-func (o *Operations) EnsureGitSetup(ctx context.Context, repoName string) error {
-	// Check if .git directory exists
-	gitDir := filepath.Join(o.workingDir, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+// DefaultConfigLockRetries is how often a git config write is retried while another process holds the lock
+const DefaultConfigLockRetries = 5
+
+// SetConfigLockRetries sets how many times git config writes are retried on lock contention
+func (o *Operations) SetConfigLockRetries(retries int) {
+	o.configRetries = retries
+}
+
+// writeConfigWithRetry runs `git config <args>`, retrying with backoff while
+// another process holds the config file lock. git takes the lock itself, so a
+// failed attempt never leaves a partially written file behind.
+func (o *Operations) writeConfigWithRetry(ctx context.Context, args ...string) error {
+	retries := o.configRetries
+	if retries <= 0 {
+		retries = DefaultConfigLockRetries
+	}
+
+	backoff := 50 * time.Millisecond
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			o.logger.Debug("Git config is locked by another process, retrying in %v...", backoff)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff + time.Duration(rand.Int63n(int64(backoff)))):
+			}
+			backoff *= 2
+		}
+		err = o.runCommand(ctx, "git", append([]string{"config"}, args...)...)
+		if err == nil || !strings.Contains(err.Error(), "could not lock config file") {
+			return err
+		}
+	}
+	return err
+}
+
+// InitRepository runs git init unless the directory is already inside a
+// repository (a worktree, a subdirectory of one, or a bare repo), and sets the
+// git user (see SetGitUser)
+func (o *Operations) InitRepository(ctx context.Context) error {
+	if _, err := o.gitDir(ctx); err != nil {
 		o.logger.Step("Initializing git repository...")
 		if err := o.runCommand(ctx, "git", "init"); err != nil {
 			o.logger.Error("Failed to initialize git repository")
 			return fmt.Errorf("failed to initialize git repository: %w", err)
 		}
 		o.logger.Success("Git repository initialized")
+	} else if bare, _ := o.IsBareRepository(ctx); bare {
+		o.logger.Info("Bare git repository detected; worktree operations are unavailable")
 	} else {
 		o.logger.Info("Git repository already initialized")
 	}
 
 	// Configure git user
-	if err := o.configureGitUser(ctx); err != nil {
+	return o.configureGitUser(ctx)
+}
+
+// EnsureGitSetup initializes the repository (see InitRepository) and its
+// submodules (see InitSubmodules), and points origin at the user's GitHub
+// repository repoName
+func (o *Operations) EnsureGitSetup(ctx context.Context, repoName string) error {
+	if err := o.InitRepository(ctx); err != nil {
+		return err
+	}
+	if err := o.InitSubmodules(ctx); err != nil {
 		return err
 	}
 
 	// Check if remote origin exists
 	o.logger.Step("Checking remote configuration...")
-	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
-	cmd.Dir = o.workingDir
-	if err := cmd.Run(); err != nil {
-		// Add remote origin with authentication
-		token := os.Getenv("GITHUB_TOKEN")
-		username := os.Getenv("GITHUB_USERNAME")
-		remoteURL := fmt.Sprintf("https://%s:%s@github.com/%s/%s.git", username, token, username, repoName)
-		o.logger.Step("Adding remote origin...")
+	username, _ := o.credentials()
+	existing, err := o.RemoteURL(ctx, "origin")
+	if err != nil {
+		existing = ""
+	}
+	scheme := o.resolveRemoteScheme(ctx, existing)
+	remoteURL := o.buildRemoteURL(scheme, username, repoName)
+
+	if existing == "" {
+		// Add remote origin; the URL carries no credentials
+		o.logger.Step("Adding remote origin (%s)...", scheme)
 		if err := o.runCommand(ctx, "git", "remote", "add", "origin", remoteURL); err != nil {
 			o.logger.Error("Failed to add remote origin")
 			return fmt.Errorf("failed to add remote origin: %w", err)
 		}
+		o.addUndo(undoRemote, "remove remote origin", func(ctx context.Context) error {
+			return o.runCommand(ctx, "git", "remote", "remove", "origin")
+		}, nil)
 		o.logger.Success("Remote origin added")
+	} else if existing == remoteURL {
+		o.logger.Info("Remote origin already configured")
 	} else {
-		// Update existing remote to use authentication
-		token := os.Getenv("GITHUB_TOKEN")
-		username := os.Getenv("GITHUB_USERNAME")
-		remoteURL := fmt.Sprintf("https://%s:%s@github.com/%s/%s.git", username, token, username, repoName)
-		o.logger.Step("Updating remote origin...")
+		// Repoint origin at the URL for the configured scheme and host
+		o.logger.Step("Updating remote origin (%s)...", scheme)
 		if err := o.runCommand(ctx, "git", "remote", "set-url", "origin", remoteURL); err != nil {
 			o.logger.Error("Failed to update remote origin")
 			return fmt.Errorf("failed to update remote origin: %w", err)
 		}
+		o.addUndo(undoRemote, "point remote origin back at "+redactURL(existing), func(ctx context.Context) error {
+			return o.runCommand(ctx, "git", "remote", "set-url", "origin", existing)
+		}, nil)
 		o.logger.Success("Remote origin updated")
 	}
 
 	return nil
 }
 
-func (o *Operations) GetDiff(ctx context.Context) (string, error) {
+// GetDiff returns the staged diff. Unless stagedOnly is set, it falls back to the
+// unstaged diff when the staged diff can't be read; with stagedOnly it returns
+// ErrNoChanges when nothing is staged.
+func (o *Operations) GetDiff(ctx context.Context, stagedOnly bool) (string, error) {
 	o.logger.Step("Getting changes...")
-	cmd := exec.CommandContext(ctx, "git", "diff", "--cached")
-	cmd.Dir = o.workingDir
-
-	output, err := cmd.Output()
+	output, err := o.backend.Diff(ctx, true)
 	if err != nil {
+		if stagedOnly {
+			o.logger.Error("Failed to get staged changes")
+			return "", fmt.Errorf("failed to get staged diff: %w", err)
+		}
 		// If nothing is staged, get unstaged changes
 		o.logger.Debug("No staged changes, checking unstaged changes...")
-		cmd = exec.CommandContext(ctx, "git", "diff")
-		cmd.Dir = o.workingDir
-		output, err = cmd.Output()
+		output, err = o.backend.Diff(ctx, false)
 		if err != nil {
 			o.logger.Error("Failed to get changes")
 			return "", fmt.Errorf("failed to get diff: %w", err)
@@ -142,48 +441,71 @@ func (o *Operations) GetDiff(ctx context.Context) (string, error) {
 
 	if len(output) == 0 {
 		o.logger.Warning("No changes detected")
+		if stagedOnly {
+			return "", ErrNoChanges
+		}
 	} else {
 		o.logger.Success("Changes detected")
 	}
-	return string(output), nil
+	return output, nil
 }
 
 func (o *Operations) StageAll(ctx context.Context) error {
+	if err := o.requireWorktree(ctx, "stage changes"); err != nil {
+		return err
+	}
 	o.logger.Step("Staging all changes...")
+	if err := o.snapshotIndex(ctx); err != nil {
+		o.logger.Debug("Staging can't be rolled back if interrupted: %v", err)
+	}
+
+	// Case-only renames are invisible to git add on case-insensitive filesystems
+	if err := o.stageCaseRenames(ctx); err != nil {
+		return err
+	}
 
-	// First try git add -A
-	if err := o.runCommand(ctx, "git", "add", "-A"); err != nil {
-		o.logger.Warning("Failed to stage with -A flag, trying alternative method...")
+	if err := o.backend.Add(ctx, nil); err != nil {
+		o.logger.Error("Failed to stage changes")
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
 
-		// If that fails, try explicit path
-		if err := o.runCommand(ctx, "git", "add", o.workingDir); err != nil {
-			o.logger.Error("Failed to stage changes")
-			return fmt.Errorf("failed to stage files: %w", err)
-		}
+	if err := o.enforceAllowedExtensions(ctx); err != nil {
+		return err
+	}
+	if err := o.enforceExcludes(ctx); err != nil {
+		return err
 	}
 
 	// Verify files were staged
-	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
-	cmd.Dir = o.workingDir
-	output, err := cmd.Output()
+	entries, err := o.GetStatus(ctx)
 	if err != nil {
-		o.logger.Error("Failed to check git status")
-		return fmt.Errorf("failed to check git status: %w", err)
+		return err
 	}
 
-	if len(output) == 0 {
+	// Changes inside submodules can't be staged from here
+	o.warnDirtySubmodules(ctx)
+
+	staged := stagedPaths(entries)
+	if len(staged) == 0 {
 		o.logger.Warning("No changes to stage")
-		return fmt.Errorf("no changes to commit")
+		return ErrNoChanges
 	}
 
 	o.logger.Success("Changes staged")
-	o.logger.Debug("Staged files:\n%s", string(output))
+	o.logger.Debug("Staged files:\n%s", log.TruncateLines(strings.Join(staged, "\n"), o.maxLogLines))
 	return nil
 }
 
 func (o *Operations) Commit(ctx context.Context, message string) error {
+	if err := o.requireWorktree(ctx, "commit"); err != nil {
+		return err
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would commit with message:\n%s", message)
+		return nil
+	}
 	o.logger.Step("Committing changes...")
-	if err := o.runCommand(ctx, "git", "commit", "-m", message); err != nil {
+	if err := o.backend.Commit(ctx, message); err != nil {
 		o.logger.Error("Failed to commit changes")
 		return fmt.Errorf("failed to commit: %w", err)
 	}
@@ -195,15 +517,18 @@ func (o *Operations) HasRemoteDiffs(ctx context.Context, remote, branch string)
 	o.logger.Step("Checking for unpushed changes...")
 
 	// Fetch latest changes
-	if err := o.runCommand(ctx, "git", "fetch", remote, branch); err != nil {
+	if err := o.runCommand(ctx, "git", o.fetchArgs(remote, branch)...); err != nil {
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
+			o.logger.Debug("Remote branch doesn't exist yet")
+			return true, nil
+		}
 		o.logger.Error("Failed to fetch remote changes")
 		return false, fmt.Errorf("failed to fetch: %w", err)
 	}
 
 	// Check if we have any commits to push
-	cmd := exec.CommandContext(ctx, "git", "rev-list", "HEAD", fmt.Sprintf("^%s/%s", remote, branch), "--count")
-	cmd.Dir = o.workingDir
-	output, err := cmd.Output()
+	cmd := o.gitCommand(ctx, "rev-list", "HEAD", fmt.Sprintf("^%s/%s", remote, branch), "--count")
+	output, err := o.limitedOutput(ctx, cmd)
 	if err != nil {
 		// If branch doesn't exist yet, we definitely have changes to push
 		if strings.Contains(string(output), "unknown revision") {
@@ -231,7 +556,26 @@ func (o *Operations) Push(ctx context.Context, remote, branch string) error {
 		remote = "origin"
 	}
 	if branch == "" {
-		branch = "main"
+		detected, err := o.PushBranch(ctx, remote)
+		if err != nil {
+			return err
+		}
+		branch = detected
+	}
+
+	args := []string{"push", "-u", remote, branch}
+	if o.forcePush {
+		args = []string{"push", "--force-with-lease", "-u", remote, branch}
+	}
+	if o.noVerify {
+		args = append([]string{"push", "--no-verify"}, args[1:]...)
+	}
+	if o.followTags {
+		args = append([]string{"push", "--follow-tags"}, args[1:]...)
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git %s", strings.Join(args, " "))
+		return nil
 	}
 
 	// Check if we have any changes to push
@@ -240,16 +584,25 @@ func (o *Operations) Push(ctx context.Context, remote, branch string) error {
 		return err
 	}
 
-	if !hasDiffs {
+	// The branch being up to date doesn't mean its tags are
+	if !hasDiffs && !o.followTags {
+		o.dropUndo(undoRemote)
 		o.logger.Success("Already up to date")
 		return nil
 	}
 
 	o.logger.Step("Pushing to %s/%s...", remote, branch)
-	if err := o.runCommand(ctx, "git", "push", "-u", remote, branch); err != nil {
+	if err := o.runCommand(ctx, "git", args...); err != nil {
 		o.logger.Error("Failed to push changes")
+		if protectedBranch.MatchString(err.Error()) {
+			return fmt.Errorf("failed to push: %w: %w", ErrProtectedBranch, err)
+		}
+		if nonFastForward.MatchString(err.Error()) {
+			return fmt.Errorf("failed to push: %w: %w", ErrNonFastForward, err)
+		}
 		return fmt.Errorf("failed to push: %w", err)
 	}
+	o.dropUndo(undoRemote)
 	o.logger.Success("Changes pushed successfully")
 	return nil
 }
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// operationFile is where the last ghquick run is recorded, inside the git dir
+const operationFile = "ghquick-last-operation.json"
+
+// Operation records what a ghquick run did to the repository, so it can be
+// inspected and undone later
+type Operation struct {
+	// SHA is the commit ghquick created; Parent is what HEAD pointed at before,
+	// empty for a repository's first commit
+	SHA    string `json:"sha"`
+	Parent string `json:"parent,omitempty"`
+	// Branch is the local branch that was committed to
+	Branch  string `json:"branch"`
+	Amended bool   `json:"amended,omitempty"`
+	// Remotes lists the remotes RemoteBranch was pushed to
+	Remotes      []string  `json:"remotes,omitempty"`
+	RemoteBranch string    `json:"remote_branch,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+// Pushed reports whether the commit reached any remote
+func (op *Operation) Pushed() bool {
+	return len(op.Remotes) > 0
+}
+
+func (o *Operations) operationPath(ctx context.Context) (string, error) {
+	dir, err := o.gitDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, operationFile), nil
+}
+
+// RecordOperation saves op as the last ghquick operation, replacing any earlier one
+func (o *Operations) RecordOperation(ctx context.Context, op Operation) error {
+	if o.dryRun {
+		return nil
+	}
+	path, err := o.operationPath(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(op, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode operation: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+	return nil
+}
+
+// LastOperation returns the last recorded ghquick operation, or nil if there is none
+func (o *Operations) LastOperation(ctx context.Context) (*Operation, error) {
+	path, err := o.operationPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last operation: %w", err)
+	}
+	var op Operation
+	if err := json.Unmarshal(data, &op); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &op, nil
+}
+
+// ClearOperation forgets the last recorded operation
+func (o *Operations) ClearOperation(ctx context.Context) error {
+	if o.dryRun {
+		return nil
+	}
+	path, err := o.operationPath(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear last operation: %w", err)
+	}
+	return nil
+}
+
+// PushRevert pushes the local branch, with a revert commit on top, to
+// remoteBranch on the remote; it's a fast-forward, so nothing is rewritten
+func (o *Operations) PushRevert(ctx context.Context, remote, branch, remoteBranch string) error {
+	o.logger.Step("Pushing the revert to %s/%s...", remote, remoteBranch)
+	if err := o.runCommand(ctx, "git", "push", remote, branch+":"+remoteBranch); err != nil {
+		o.logger.Error("Failed to push to %s", remote)
+		return fmt.Errorf("failed to push revert to %s: %w", remote, err)
+	}
+	o.logger.Success("Revert pushed to %s/%s", remote, remoteBranch)
+	return nil
+}
+
+// ForcePushUndo pushes the local branch over remoteBranch on the remote, but
+// only while the remote still points at expected, so commits pushed by someone
+// else since are never overwritten
+func (o *Operations) ForcePushUndo(ctx context.Context, remote, branch, remoteBranch, expected string) error {
+	lease := fmt.Sprintf("--force-with-lease=%s:%s", remoteBranch, expected)
+	o.logger.Step("Force-pushing %s to %s/%s...", branch, remote, remoteBranch)
+	if err := o.runCommand(ctx, "git", "push", lease, remote, branch+":"+remoteBranch); err != nil {
+		o.logger.Error("Failed to force-push to %s", remote)
+		return fmt.Errorf("failed to force-push to %s: %w", remote, err)
+	}
+	o.logger.Success("%s/%s reset", remote, remoteBranch)
+	return nil
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/saint/ghquick/internal/log"
+)
+
+// slots bounds how many git subprocesses run at once across all Operations,
+// so concurrent work over many repositories can't exhaust file descriptors
+var (
+	slotsMu sync.Mutex
+	slots   = make(chan struct{}, runtime.NumCPU())
+	queued  int32
+)
+
+// SetMaxParallel sets how many git commands may run concurrently process-wide;
+// zero or less uses the number of CPUs. Call it before starting any operations.
+func SetMaxParallel(n int) {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	slotsMu.Lock()
+	slots = make(chan struct{}, n)
+	slotsMu.Unlock()
+}
+
+// acquireSlot blocks until a git command may run and returns the function that
+// frees the slot again
+func acquireSlot(ctx context.Context, logger *log.Logger) (func(), error) {
+	slotsMu.Lock()
+	sem := slots
+	slotsMu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	default:
+	}
+
+	depth := atomic.AddInt32(&queued, 1)
+	defer atomic.AddInt32(&queued, -1)
+	logger.Debug("Waiting for a free git slot (%d queued, %d of %d running)", depth, len(sem), cap(sem))
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limitedOutput runs cmd.Output while holding a git slot
+func (o *Operations) limitedOutput(ctx context.Context, cmd *timedCmd) ([]byte, error) {
+	defer cmd.cancel()
+	release, err := acquireSlot(ctx, o.logger)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if env := o.commandEnv(nil, cmd.Args[1:]); len(env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, env...)
+	}
+	out, err := cmd.Output()
+	if err != nil && cmd.Process == nil {
+		return out, o.missingGit(err)
+	}
+	return out, cmd.wrap(err)
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+var (
+	authFailure    = regexp.MustCompile(`(?i)authentication failed|invalid username or password|could not read (username|password)|returned error: 40[13]|permission (to .* )?denied`)
+	remoteNotFound = regexp.MustCompile(`(?i)repository not found|returned error: 404`)
+)
+
+// CheckRemoteAccess confirms the remote is configured and that git can reach it
+// and authenticate, using a lightweight ls-remote. git is told not to prompt for
+// credentials, so a bad token fails instead of waiting for input.
+func (o *Operations) CheckRemoteAccess(ctx context.Context, remote string) error {
+	url, err := o.RemoteURL(ctx, remote)
+	if err != nil {
+		o.logger.Error("Remote %s is not configured", remote)
+		return fmt.Errorf("remote %s is not configured: %w", remote, err)
+	}
+
+	o.logger.Step("Checking access to %s...", remote)
+	err = o.runCommandWithEnv(ctx, []string{"GIT_TERMINAL_PROMPT=0"}, "git", "ls-remote", "--heads", remote)
+	if err == nil {
+		o.logger.Success("Remote %s is reachable", remote)
+		return nil
+	}
+
+	o.logger.Error("Cannot access remote %s", remote)
+	switch msg := err.Error(); {
+	case authFailure.MatchString(msg):
+		return fmt.Errorf("cannot authenticate to %s; check that the GitHub token is valid and has not expired (ghquick auth status): %w", remote, err)
+	case remoteNotFound.MatchString(msg):
+		return fmt.Errorf("repository behind %s (%s) was not found, or the token can't see it: %w", remote, redactURL(url), err)
+	default:
+		return fmt.Errorf("cannot reach %s: %w", remote, err)
+	}
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// emptyTree is the hash of git's empty tree, used to diff against when there is no base
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// GetPushPreviewDiff returns the diff a push of HEAD would introduce on remote/branch.
+// With fetch set, the remote branch is refreshed first. When the remote branch
+// doesn't exist yet, HEAD is diffed against its merge base with the remote's
+// default branch, or against the empty tree for a brand-new repository.
+func (o *Operations) GetPushPreviewDiff(ctx context.Context, remote, branch string, fetch bool) (string, error) {
+	remoteRef := remote + "/" + branch
+	if fetch {
+		if err := o.runCommand(ctx, "git", o.fetchArgs(remote, branch)...); err != nil && !strings.Contains(err.Error(), "couldn't find remote ref") {
+			o.logger.Warning("Failed to fetch %s; previewing against the last known state", remoteRef)
+		}
+	}
+
+	base := remoteRef
+	if _, err := o.output(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remoteRef); err != nil {
+		base = o.newBranchBase(ctx, remote)
+		o.logger.Info("%s doesn't exist yet; all commits since %s are new", remoteRef, shortRev(base))
+	}
+
+	diff, err := o.output(ctx, "diff", base, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s against HEAD: %w", base, err)
+	}
+	return diff, nil
+}
+
+// BranchDiff returns what HEAD changes relative to its merge base with base,
+// preferring remote/base over a local branch of the same name
+func (o *Operations) BranchDiff(ctx context.Context, remote, base string) (string, error) {
+	mergeBase, err := o.output(ctx, "merge-base", "HEAD", remote+"/"+base)
+	if err != nil {
+		if mergeBase, err = o.output(ctx, "merge-base", "HEAD", base); err != nil {
+			return "", fmt.Errorf("failed to find where HEAD branched off %s: %w", base, err)
+		}
+	}
+	diff, err := o.output(ctx, "diff", mergeBase, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to diff HEAD against %s: %w", base, err)
+	}
+	return diff, nil
+}
+
+// newBranchBase finds what a brand-new remote branch would be compared against
+func (o *Operations) newBranchBase(ctx context.Context, remote string) string {
+	if defaultBranch, err := o.DefaultBranch(ctx, remote); err == nil {
+		if base, err := o.output(ctx, "merge-base", "HEAD", remote+"/"+defaultBranch); err == nil {
+			return base
+		}
+	}
+	return emptyTree
+}
+
+func shortRev(rev string) string {
+	if rev == emptyTree {
+		return "the beginning of history"
+	}
+	if len(rev) > 7 {
+		return rev[:7]
+	}
+	return rev
+}
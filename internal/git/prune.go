@@ -0,0 +1,35 @@
+package git
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetAutoPrune makes fetches also delete remote-tracking refs whose branch is
+// gone from the remote (git fetch --prune)
+func (o *Operations) SetAutoPrune(prune bool) {
+	o.autoPrune = prune
+}
+
+// fetchArgs builds the arguments of a fetch, adding --prune when auto-prune is on
+func (o *Operations) fetchArgs(args ...string) []string {
+	fetch := []string{"fetch"}
+	if o.autoPrune {
+		fetch = append(fetch, "--prune")
+	}
+	return append(fetch, args...)
+}
+
+// Prune fetches remote and removes remote-tracking refs for branches deleted on it
+func (o *Operations) Prune(ctx context.Context, remote string) error {
+	if remote == "" {
+		remote = "origin"
+	}
+	o.logger.Step("Pruning stale remote-tracking branches of %s...", remote)
+	if err := o.runCommand(ctx, "git", "fetch", "--prune", remote); err != nil {
+		o.logger.Error("Failed to prune %s", remote)
+		return fmt.Errorf("failed to prune %s: %w", remote, err)
+	}
+	o.logger.Success("Remote-tracking branches of %s are up to date", remote)
+	return nil
+}
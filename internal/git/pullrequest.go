@@ -0,0 +1,114 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CheckoutPullRequest fetches pull request number from remote into a local
+// branch and switches to it, handling local changes according to policy, and
+// returns the branch's name. head is the pull request's head branch: with track
+// set it's on remote itself and the branch tracks remote/head, so new commits
+// can be pushed back. Otherwise it's fetched from the pull request's read-only
+// ref, and git pull keeps following it. An empty branch picks the branch an
+// earlier checkout made, else head, or pr-<number> when a branch of that name
+// is already used for something else.
+// A branch that already exists is fast-forwarded to the pull request.
+func (o *Operations) CheckoutPullRequest(ctx context.Context, remote string, number int, branch, head string, track bool, policy DirtyPolicy) (string, error) {
+	pullRef := fmt.Sprintf("refs/pull/%d/head", number)
+	if branch == "" {
+		branch = o.PullRequestBranch(ctx, number, remote, head, track)
+	}
+	if branch == "" {
+		branch = head
+		if !track && o.BranchExists(ctx, branch) {
+			branch = fmt.Sprintf("pr-%d", number)
+		}
+	}
+	if o.dryRun {
+		if track {
+			o.logger.Info("[dry-run] Would run: git fetch %s %s", remote, head)
+			o.logger.Info("[dry-run] Would switch to %s, tracking %s/%s", branch, remote, head)
+		} else {
+			o.logger.Info("[dry-run] Would run: git fetch %s %s:refs/heads/%s", remote, pullRef, branch)
+			o.logger.Info("[dry-run] Would switch to %s", branch)
+		}
+		return branch, nil
+	}
+
+	current, _ := o.CurrentBranch(ctx)
+	existed := o.BranchExists(ctx, branch)
+	// What an existing branch is fast-forwarded to once it's checked out
+	source := "FETCH_HEAD"
+	o.logger.Step("Fetching pull request #%d into %s...", number, branch)
+	var err error
+	switch {
+	case track:
+		if err = o.runCommand(ctx, "git", o.fetchArgs(remote, head)...); err == nil && !existed {
+			err = o.runCommand(ctx, "git", "branch", "--track", branch, remote+"/"+head)
+		}
+		source = remote + "/" + head
+	case existed:
+		// git won't fetch into the checked out branch, so existing branches
+		// are fast-forwarded after the switch instead
+		err = o.runCommand(ctx, "git", "fetch", remote, pullRef)
+	default:
+		if err = o.runCommand(ctx, "git", "fetch", remote, pullRef+":refs/heads/"+branch); err == nil {
+			// Like a tracking branch, so git pull fetches the pull request's new commits
+			_ = o.runCommand(ctx, "git", "config", "branch."+branch+".remote", remote)
+			_ = o.runCommand(ctx, "git", "config", "branch."+branch+".merge", pullRef)
+		}
+	}
+	if err != nil {
+		o.logger.Error("Failed to fetch pull request #%d", number)
+		return "", fmt.Errorf("failed to fetch pull request #%d: %w", number, err)
+	}
+	o.logger.Success("Fetched pull request #%d", number)
+
+	if branch != current {
+		if err := o.Checkout(ctx, branch, policy); err != nil {
+			return "", err
+		}
+	}
+	if existed {
+		if err := o.runCommand(ctx, "git", "merge", "--ff-only", source); err != nil {
+			o.logger.Warning("%s has diverged from pull request #%d and was left as is", branch, number)
+			o.logger.Debug("Fast-forward failed: %v", err)
+		}
+	}
+	return branch, nil
+}
+
+// PullRequestBranch returns the local branch that follows pull request number:
+// one CheckoutPullRequest fetched from its read-only ref, or with track set,
+// one tracking remote/head. It returns "" when there is none.
+func (o *Operations) PullRequestBranch(ctx context.Context, number int, remote, head string, track bool) string {
+	out, err := o.output(ctx, "config", "--get-regexp", `^branch\..*\.(remote|merge)$`)
+	if err != nil {
+		return ""
+	}
+	remotes, merges := map[string]string{}, map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		name, ok := strings.CutPrefix(key, "branch.")
+		if !ok {
+			continue
+		}
+		if name, ok := strings.CutSuffix(name, ".remote"); ok {
+			remotes[name] = value
+		} else if name, ok := strings.CutSuffix(name, ".merge"); ok {
+			merges[name] = value
+		}
+	}
+	pullRef := fmt.Sprintf("refs/pull/%d/head", number)
+	for name, merge := range merges {
+		if remotes[name] != remote || !o.BranchExists(ctx, name) {
+			continue
+		}
+		if merge == pullRef || (track && merge == "refs/heads/"+head) {
+			return name
+		}
+	}
+	return ""
+}
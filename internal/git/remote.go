@@ -0,0 +1,175 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// githubURL matches https and ssh GitHub remote URLs, with or without embedded credentials
+var githubURL = regexp.MustCompile(`^(?:https?://(?:[^@/]+@)?|ssh://(?:[^@/]+@)?|[^@/]+@)?github\.com[/:]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseGitHubURL extracts the owner and repository name from a GitHub remote URL
+func ParseGitHubURL(url string) (owner, name string, err error) {
+	m := githubURL.FindStringSubmatch(strings.TrimSpace(url))
+	if m == nil {
+		return "", "", fmt.Errorf("not a GitHub remote URL: %s", redactURL(url))
+	}
+	return m[1], m[2], nil
+}
+
+// RemoteURL returns the URL configured for the remote
+func (o *Operations) RemoteURL(ctx context.Context, remote string) (string, error) {
+	output, err := o.output(ctx, "remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote %s: %w", remote, err)
+	}
+	return output, nil
+}
+
+// RemoteRepo returns the GitHub owner and repository name the remote points at
+func (o *Operations) RemoteRepo(ctx context.Context, remote string) (string, string, error) {
+	url, err := o.RemoteURL(ctx, remote)
+	if err != nil {
+		return "", "", err
+	}
+	return ParseGitHubURL(url)
+}
+
+// HasRemote reports whether a remote with the given name is configured
+func (o *Operations) HasRemote(ctx context.Context, remote string) bool {
+	_, err := o.RemoteURL(ctx, remote)
+	return err == nil
+}
+
+// CurrentBranch returns the name of the checked out branch
+func (o *Operations) CurrentBranch(ctx context.Context) (string, error) {
+	branch, err := o.output(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if branch == "HEAD" {
+		return "", fmt.Errorf("HEAD is detached; check out a branch first")
+	}
+	return branch, nil
+}
+
+// HeadCommit returns the full SHA of the HEAD commit
+func (o *Operations) HeadCommit(ctx context.Context) (string, error) {
+	sha, err := o.output(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return sha, nil
+}
+
+// ResolveCommit returns the full SHA of the commit rev names
+func (o *Operations) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	sha, err := o.output(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %s", rev)
+	}
+	return sha, nil
+}
+
+// RemoteDefaultBranch returns the default branch recorded for the remote
+// (refs/remotes/<remote>/HEAD), which is set by clone or `git remote set-head`
+func (o *Operations) RemoteDefaultBranch(ctx context.Context, remote string) (string, error) {
+	ref, err := o.output(ctx, "symbolic-ref", "--short", "refs/remotes/"+remote+"/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve default branch of %s: %w", remote, err)
+	}
+	return strings.TrimPrefix(ref, remote+"/"), nil
+}
+
+// DefaultBranch returns the remote's default branch: the one recorded locally
+// in refs/remotes/<remote>/HEAD, or else the branch the remote itself reports
+// for HEAD, which is then recorded so later lookups stay offline
+func (o *Operations) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	if branch, err := o.RemoteDefaultBranch(ctx, remote); err == nil {
+		return branch, nil
+	}
+
+	cmd := o.gitCommand(ctx, "ls-remote", "--symref", remote, "HEAD")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := o.limitedOutput(ctx, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to query default branch of %s: %w", remote, err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// ref: refs/heads/main	HEAD
+		if ref, ok := strings.CutPrefix(line, "ref: refs/heads/"); ok {
+			branch, _, _ := strings.Cut(ref, "\t")
+			if err := o.runCommand(ctx, "git", "remote", "set-head", remote, branch); err != nil {
+				o.logger.Debug("Could not record %s/HEAD: %v", remote, err)
+			}
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("%s doesn't report a default branch", remote)
+}
+
+// BranchExists reports whether a local branch with the given name exists
+func (o *Operations) BranchExists(ctx context.Context, name string) bool {
+	_, err := o.output(ctx, "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// PushBranch returns the branch to push when none is given: the checked out
+// branch, or for a fresh clone or repository with no commits yet, the branch
+// HEAD will be born on or the remote's default branch
+func (o *Operations) PushBranch(ctx context.Context, remote string) (string, error) {
+	if branch, err := o.output(ctx, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		if branch == "HEAD" {
+			return "", fmt.Errorf("HEAD is detached; check out a branch or pass --branch")
+		}
+		return branch, nil
+	}
+	if branch, err := o.DefaultBranch(ctx, remote); err == nil {
+		return branch, nil
+	}
+	// An unborn branch has no commit for rev-parse to resolve, but HEAD still names it
+	if branch, err := o.output(ctx, "symbolic-ref", "--short", "HEAD"); err == nil {
+		return branch, nil
+	}
+	return "", fmt.Errorf("failed to determine the branch to push; pass --branch")
+}
+
+// LastCommitMessage returns the subject and body of the HEAD commit
+func (o *Operations) LastCommitMessage(ctx context.Context) (string, string, error) {
+	message, err := o.output(ctx, "log", "-1", "--format=%B")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read last commit: %w", err)
+	}
+	subject, body, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body), nil
+}
+
+// output runs a read-only git command and returns its trimmed stdout
+func (o *Operations) output(ctx context.Context, args ...string) (string, error) {
+	o.logger.Command("git", args...)
+	cmd := o.gitCommand(ctx, args...)
+	out, err := o.limitedOutput(ctx, cmd)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// redactURL hides credentials embedded in a remote URL
+func redactURL(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		if at := strings.Index(url[i+3:], "@"); at >= 0 {
+			return url[:i+3] + "***@" + url[i+3+at+1:]
+		}
+	}
+	return url
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RemoteScheme decides which URL EnsureGitSetup configures for origin
+type RemoteScheme string
+
+const (
+	// RemoteAuto keeps the scheme origin already uses, otherwise follows gh's
+	// git_protocol setting, otherwise uses HTTPS (the default)
+	RemoteAuto RemoteScheme = "auto"
+	// RemoteHTTPS uses https://host/owner/repo.git, with the token passed to git
+	// through a credential helper rather than stored in the URL
+	RemoteHTTPS RemoteScheme = "https"
+	// RemoteSSH uses git@host:owner/repo.git and the user's SSH keys
+	RemoteSSH RemoteScheme = "ssh"
+)
+
+// DefaultGitHubHost is the host remote URLs point at unless SetRemoteScheme sets another
+const DefaultGitHubHost = "github.com"
+
+// ParseRemoteScheme validates a scheme name, defaulting to RemoteAuto when empty
+func ParseRemoteScheme(s string) (RemoteScheme, error) {
+	switch RemoteScheme(strings.ToLower(s)) {
+	case "":
+		return RemoteAuto, nil
+	case RemoteAuto, RemoteHTTPS, RemoteSSH:
+		return RemoteScheme(strings.ToLower(s)), nil
+	}
+	return "", fmt.Errorf("invalid remote scheme %q (expected auto, https, or ssh)", s)
+}
+
+// SetRemoteScheme sets the URL scheme and host EnsureGitSetup uses for origin.
+// An empty host means DefaultGitHubHost, e.g. "github.example.com" for GitHub Enterprise.
+func (o *Operations) SetRemoteScheme(scheme RemoteScheme, host string) {
+	o.remoteScheme = scheme
+	o.remoteHost = host
+}
+
+func (o *Operations) host() string {
+	if o.remoteHost != "" {
+		return o.remoteHost
+	}
+	return DefaultGitHubHost
+}
+
+// isSSHURL reports whether a remote URL uses SSH (ssh:// or scp-like user@host:path)
+func isSSHURL(url string) bool {
+	if strings.HasPrefix(url, "ssh://") {
+		return true
+	}
+	return !strings.Contains(url, "://") && strings.Contains(url, "@") && strings.Contains(url, ":")
+}
+
+// resolveRemoteScheme turns RemoteAuto into a concrete scheme. existing is
+// origin's current URL, or "" when there is no origin yet.
+func (o *Operations) resolveRemoteScheme(ctx context.Context, existing string) RemoteScheme {
+	if o.remoteScheme == RemoteHTTPS || o.remoteScheme == RemoteSSH {
+		return o.remoteScheme
+	}
+	if existing != "" {
+		if isSSHURL(existing) {
+			return RemoteSSH
+		}
+		return RemoteHTTPS
+	}
+
+	// gh records the protocol chosen during `gh auth login`
+	out, err := exec.CommandContext(ctx, "gh", "config", "get", "git_protocol", "--host", o.host()).Output()
+	if err == nil && strings.TrimSpace(string(out)) == "ssh" {
+		o.logger.Debug("Using SSH for origin, as configured for gh")
+		return RemoteSSH
+	}
+	return RemoteHTTPS
+}
+
+// buildRemoteURL returns the origin URL for owner/repo in the given scheme.
+// HTTPS URLs carry no credentials; git gets the token from credentialEnv.
+func (o *Operations) buildRemoteURL(scheme RemoteScheme, owner, repo string) string {
+	if scheme == RemoteSSH {
+		return fmt.Sprintf("git@%s:%s/%s.git", o.host(), owner, repo)
+	}
+	return fmt.Sprintf("https://%s/%s/%s.git", o.host(), owner, repo)
+}
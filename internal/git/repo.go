@@ -0,0 +1,43 @@
+package git
+
+import (
+	"context"
+	"fmt"
+)
+
+// gitDir returns the absolute path of the repository's git directory, which is
+// <worktree>/.git for normal clones and the repository itself for bare repos
+func (o *Operations) gitDir(ctx context.Context) (string, error) {
+	if o.gitDirPath != "" {
+		return o.gitDirPath, nil
+	}
+	dir, err := o.output(ctx, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	o.gitDirPath = dir
+	return dir, nil
+}
+
+// IsBareRepository reports whether the working directory is a bare repository
+func (o *Operations) IsBareRepository(ctx context.Context) (bool, error) {
+	bare, err := o.backend.IsBare(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for bare repository: %w", err)
+	}
+	return bare, nil
+}
+
+// requireWorktree returns a clear error when an operation needs a working tree
+// but the repository is bare (e.g. on a server)
+func (o *Operations) requireWorktree(ctx context.Context, action string) error {
+	bare, err := o.IsBareRepository(ctx)
+	if err != nil {
+		return err
+	}
+	if bare {
+		o.logger.Error("Cannot %s in a bare repository", action)
+		return fmt.Errorf("cannot %s: %s is a bare repository with no working tree", action, o.workingDir)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/saint/ghquick/internal/retry"
+)
+
+// transientPatterns match git and network errors that are usually gone on a retry
+var transientPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)connection (reset|refused|timed out)`),
+	regexp.MustCompile(`(?i)operation timed out`),
+	regexp.MustCompile(`(?i)could not resolve host`),
+	regexp.MustCompile(`(?i)temporary failure in name resolution`),
+	regexp.MustCompile(`(?i)the remote end hung up unexpectedly`),
+	regexp.MustCompile(`(?i)early EOF`),
+	regexp.MustCompile(`(?i)RPC failed`),
+	regexp.MustCompile(`(?i)HTTP (429|5\d\d)|returned error: (429|5\d\d)`),
+	regexp.MustCompile(`(?i)gnutls_handshake|SSL_read|SSL_ERROR_SYSCALL|TLS handshake timeout`),
+	regexp.MustCompile(`(?i)unable to access .*: (Failed to connect|Recv failure|OpenSSL)`),
+}
+
+// permanentPatterns match failures a retry can't fix; they win over any
+// transient pattern that appears in the same output
+var permanentPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)authentication failed|invalid username or password|could not read (username|password)`),
+	regexp.MustCompile(`(?i)permission (to .* )?denied|returned error: 40[13]`),
+	regexp.MustCompile(`(?i)repository not found`),
+	regexp.MustCompile(`(?i)\[(rejected|remote rejected)\]|non-fast-forward|fetch first`),
+}
+
+// SetRetryPolicy sets how network git commands (push, fetch, pull, ls-remote,
+// submodule update) are retried when they fail with a transient error; the
+// zero policy uses the retry package defaults
+func (o *Operations) SetRetryPolicy(p retry.Policy) {
+	o.retryPolicy = p
+}
+
+// isNetworkCommand reports whether a git command talks to a remote, so a
+// failure may be transient
+func isNetworkCommand(args []string) bool {
+	if len(args) > 2 && args[0] == "-C" {
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "push", "fetch", "pull", "ls-remote", "clone":
+		return true
+	case "submodule":
+		// update clones and fetches the submodules
+		return len(args) > 1 && args[1] == "update"
+	}
+	return false
+}
+
+// SetRetryPatterns adds regular expressions matching error output that should
+// be retried, on top of the built-in transient network errors
+func (o *Operations) SetRetryPatterns(patterns []string) error {
+	o.retryPatterns = nil
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid retry pattern %q: %w", p, err)
+		}
+		o.retryPatterns = append(o.retryPatterns, re)
+	}
+	return nil
+}
+
+// IsRetryable reports whether a failed git command looks transient, so running
+// it again may succeed. Errors such as rejected pushes or bad credentials are not.
+func (o *Operations) IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, re := range permanentPatterns {
+		if re.MatchString(msg) {
+			return false
+		}
+	}
+	for _, re := range transientPatterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	for _, re := range o.retryPatterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Kinds of undo step, each dropped once the run has finished what it started
+const (
+	// undoIndex puts back the index staging replaced, until the commit is made
+	undoIndex = "index"
+	// undoRemote reverts the origin set up for this run, until a push succeeds
+	undoRemote = "remote"
+)
+
+// undoStep puts back one thing the run changed
+type undoStep struct {
+	kind string
+	// what describes the step for the log, e.g. "remove remote origin"
+	what string
+	run  func(ctx context.Context) error
+	// done releases what the step holds, e.g. a backup file, once it is dropped or run
+	done func()
+}
+
+// addUndo records how to put back a change the run is making. A kind already
+// recorded is kept, so the first, oldest state wins.
+func (o *Operations) addUndo(kind, what string, run func(ctx context.Context) error, done func()) {
+	o.undoMu.Lock()
+	defer o.undoMu.Unlock()
+	for _, s := range o.undo {
+		if s.kind == kind {
+			if done != nil {
+				done()
+			}
+			return
+		}
+	}
+	o.undo = append(o.undo, undoStep{kind: kind, what: what, run: run, done: done})
+}
+
+// hasUndo reports whether a step of kind is recorded
+func (o *Operations) hasUndo(kind string) bool {
+	o.undoMu.Lock()
+	defer o.undoMu.Unlock()
+	for _, s := range o.undo {
+		if s.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// dropUndo forgets the steps of kind, as the change they undo is now complete
+func (o *Operations) dropUndo(kind string) {
+	o.undoMu.Lock()
+	defer o.undoMu.Unlock()
+	kept := o.undo[:0]
+	for _, s := range o.undo {
+		if s.kind != kind {
+			kept = append(kept, s)
+		} else if s.done != nil {
+			s.done()
+		}
+	}
+	o.undo = kept
+}
+
+// Rollback puts the repository back as it was before this run wherever the run
+// stopped halfway: the index is restored when changes were staged but not
+// committed, and an origin remote added or repointed for a push that never
+// happened is removed or reverted. It is for a run that was interrupted or
+// timed out, so ctx must not be the run's own, cancelled, context. Every step is
+// tried; the first error is returned.
+func (o *Operations) Rollback(ctx context.Context) error {
+	o.undoMu.Lock()
+	steps := o.undo
+	o.undo = nil
+	o.undoMu.Unlock()
+
+	var first error
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		o.logger.Step("Rolling back: %s...", s.what)
+		if err := s.run(ctx); err != nil {
+			o.logger.Warning("Failed to %s: %v", s.what, err)
+			if first == nil {
+				first = fmt.Errorf("failed to %s: %w", s.what, err)
+			}
+		}
+		if s.done != nil {
+			s.done()
+		}
+	}
+	if len(steps) > 0 && first == nil {
+		o.logger.Success("Rolled back the unfinished changes")
+	}
+	return first
+}
+
+// DiscardRollback forgets how to roll back, for a run that ended on its own
+func (o *Operations) DiscardRollback() {
+	o.undoMu.Lock()
+	steps := o.undo
+	o.undo = nil
+	o.undoMu.Unlock()
+	for _, s := range steps {
+		if s.done != nil {
+			s.done()
+		}
+	}
+}
+
+// snapshotIndex saves a copy of the index before staging changes it, so
+// Rollback can restore what was staged before the run. Nothing is saved while
+// staging goes to an isolated index (see IsolateIndex), or if a copy is already
+// saved.
+func (o *Operations) snapshotIndex(ctx context.Context) error {
+	if o.indexFile != "" || o.hasUndo(undoIndex) {
+		return nil
+	}
+	indexPath, err := o.output(ctx, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return fmt.Errorf("failed to locate the index: %w", err)
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(o.workingDir, indexPath)
+	}
+
+	src, err := os.Open(indexPath)
+	if os.IsNotExist(err) {
+		// Nothing staged yet: rolling back means no index at all
+		o.addUndo(undoIndex, "unstage the changes", func(ctx context.Context) error {
+			if err := os.Remove(indexPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}, nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the index: %w", err)
+	}
+	defer src.Close()
+	backup, err := os.CreateTemp("", "ghquick-index-backup-*")
+	if err != nil {
+		return fmt.Errorf("failed to back up the index: %w", err)
+	}
+	_, err = io.Copy(backup, src)
+	backup.Close()
+	if err != nil {
+		os.Remove(backup.Name())
+		return fmt.Errorf("failed to back up the index: %w", err)
+	}
+
+	backupPath := backup.Name()
+	o.addUndo(undoIndex, "restore the staged changes from before the run", func(ctx context.Context) error {
+		data, err := os.ReadFile(backupPath)
+		if err != nil {
+			return err
+		}
+		// Like git, write a new index and move it into place
+		tmp := indexPath + ".ghquick"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, indexPath)
+	}, func() { os.Remove(backupPath) })
+	return nil
+}
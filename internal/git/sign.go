@@ -0,0 +1,183 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SignMode controls whether commits created by ghquick are signed
+type SignMode string
+
+const (
+	// SignDefault leaves signing to git config (commit.gpgsign)
+	SignDefault SignMode = ""
+	// SignOn always signs, with the configured key or user.signingkey
+	SignOn SignMode = "on"
+	// SignOff never signs, overriding commit.gpgsign
+	SignOff SignMode = "off"
+)
+
+// SetSigning sets whether commits are signed and, for SignOn, the key to sign
+// with; an empty key falls back to the repository's user.signingkey
+func (o *Operations) SetSigning(mode SignMode, keyID string) {
+	o.signMode = mode
+	o.signingKey = keyID
+}
+
+// signArgs returns the git commit flags for the signing mode
+func (o *Operations) signArgs() []string {
+	switch o.signMode {
+	case SignOn:
+		if o.signingKey != "" {
+			return []string{"--gpg-sign=" + o.signingKey}
+		}
+		return []string{"--gpg-sign"}
+	case SignOff:
+		return []string{"--no-gpg-sign"}
+	}
+	return nil
+}
+
+// CheckSigning verifies, before anything is committed, that commits can be
+// signed when signing is on (SignOn, or commit.gpgsign with SignDefault): the
+// signing program for gpg.format is installed and the key is available.
+// Problems are reported with a suggested fix.
+func (o *Operations) CheckSigning(ctx context.Context) error {
+	switch o.signMode {
+	case SignOff:
+		return nil
+	case SignDefault:
+		if on, _ := o.output(ctx, "config", "--bool", "commit.gpgsign"); on != "true" {
+			return nil
+		}
+	}
+
+	key := o.signingKey
+	if key == "" {
+		key, _ = o.output(ctx, "config", "user.signingkey")
+	}
+	format, _ := o.output(ctx, "config", "gpg.format")
+	o.logger.Debug("Checking commit signing (gpg.format %q, key %q)", format, key)
+
+	var err error
+	switch format {
+	case "ssh":
+		err = o.checkSSHSigning(ctx, key)
+	case "x509":
+		_, err = o.signingProgram(ctx, "gpg.x509.program", "gpgsm")
+	default:
+		err = o.checkGPGSigning(ctx, key)
+	}
+	if err != nil {
+		o.logger.Error("Commit signing is not set up: %v", err)
+		return fmt.Errorf("commit signing is not set up: %w (or pass --no-gpg-sign to commit unsigned)", err)
+	}
+	return nil
+}
+
+// signingProgram returns the path of the signing program configured under key,
+// or fallback, failing when it can't be found
+func (o *Operations) signingProgram(ctx context.Context, key, fallback string) (string, error) {
+	program, _ := o.output(ctx, "config", key)
+	if program == "" {
+		program = fallback
+	}
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed or not on PATH; install it or set %s", program, key)
+	}
+	return path, nil
+}
+
+// checkGPGSigning checks that gpg has a secret key for keyID, or any secret key when keyID is empty
+func (o *Operations) checkGPGSigning(ctx context.Context, keyID string) error {
+	gpg, err := o.signingProgram(ctx, "gpg.program", "gpg")
+	if err != nil {
+		return err
+	}
+	args := []string{"--batch", "--list-secret-keys", "--with-colons"}
+	if keyID != "" {
+		args = append(args, keyID)
+	}
+	output, err := exec.CommandContext(ctx, gpg, args...).Output()
+	if err != nil || !strings.Contains(string(output), "sec:") {
+		if keyID != "" {
+			return fmt.Errorf("gpg has no secret key for %s; import it or fix user.signingkey", keyID)
+		}
+		return fmt.Errorf("gpg has no secret keys; create one with 'gpg --full-generate-key' or set user.signingkey")
+	}
+	return nil
+}
+
+// checkSSHSigning checks that an SSH signing key is configured and, when it
+// names a file, that the file exists
+func (o *Operations) checkSSHSigning(ctx context.Context, key string) error {
+	if _, err := o.signingProgram(ctx, "gpg.ssh.program", "ssh-keygen"); err != nil {
+		return err
+	}
+	if key == "" {
+		if cmd, _ := o.output(ctx, "config", "gpg.ssh.defaultKeyCommand"); cmd != "" {
+			return nil
+		}
+		return fmt.Errorf("no SSH signing key; set user.signingkey to your public key file (e.g. ~/.ssh/id_ed25519.pub) or pass --signing-key")
+	}
+	// A literal public key ("ssh-ed25519 AAAA...") needs its private half in the agent, which git checks itself
+	if strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "key::") {
+		return nil
+	}
+	path := key
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, rest)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("SSH signing key %s does not exist; fix user.signingkey or pass --signing-key", key)
+	}
+	return nil
+}
+
+// runCommit runs `git commit` with the signing flags added, turning signing
+// failures into an error that says how to fix them
+func (o *Operations) runCommit(ctx context.Context, env []string, args ...string) error {
+	commit := []string{"commit"}
+	if o.noVerify {
+		commit = append(commit, "--no-verify")
+	}
+	args = append(append(commit, o.signArgs()...), args...)
+	if err := o.runCommandWithEnv(ctx, env, "git", args...); err != nil {
+		if hint := signingHint(err.Error()); hint != "" {
+			o.logger.Error("Commit signing failed: %s", hint)
+			return fmt.Errorf("commit signing failed: %s (or pass --no-gpg-sign to commit unsigned): %w", hint, err)
+		}
+		return err
+	}
+	// What was staged is committed now
+	o.dropUndo(undoIndex)
+	return nil
+}
+
+// signingHint maps gpg and ssh-keygen errors from a failed signed commit to a
+// suggested fix, or returns "" when the failure is unrelated to signing
+func signingHint(output string) string {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "cannot run gpg"), strings.Contains(lower, "cannot run ssh-keygen"):
+		return "the signing program is not installed or not on PATH; install gpg (or set gpg.program)"
+	case strings.Contains(lower, "no secret key"), strings.Contains(lower, "no default secret key"):
+		return "no secret key is available for the signing key; check user.signingkey or pass --signing-key"
+	case strings.Contains(lower, "inappropriate ioctl"), strings.Contains(lower, "no pinentry"), strings.Contains(lower, "problem with the agent"):
+		return "gpg could not ask for the key's passphrase; run 'export GPG_TTY=$(tty)' or unlock the key in gpg-agent"
+	case strings.Contains(lower, "couldn't load public key"), strings.Contains(lower, "no private key found"), strings.Contains(lower, "user.signingkey needs to be set for ssh signing"):
+		return "the SSH signing key could not be loaded; set user.signingkey to your public key file or pass --signing-key"
+	case strings.Contains(lower, "gpg failed to sign the data"), strings.Contains(lower, "failed to write commit object") && strings.Contains(lower, "sign"):
+		return "gpg failed to sign the commit; check that 'echo test | gpg --clearsign' works"
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SetAllowedExtensions restricts which files StageAll and StageFiles may stage.
+// Extensions are matched case-insensitively with or without the leading dot;
+// an empty list allows everything. When strict is set, staging fails instead of
+// skipping files outside the allowlist.
+func (o *Operations) SetAllowedExtensions(exts []string, strict bool) {
+	o.allowedExts = nil
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		o.allowedExts = append(o.allowedExts, ext)
+	}
+	o.strictExts = strict
+}
+
+// StagedChanges returns the paths that already have changes in the index
+func (o *Operations) StagedChanges(ctx context.Context) ([]string, error) {
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stagedPaths(entries), nil
+}
+
+// StageFiles stages only the given paths
+func (o *Operations) StageFiles(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return ErrNoChanges
+	}
+	if err := o.requireWorktree(ctx, "stage changes"); err != nil {
+		return err
+	}
+	o.logger.Step("Staging %d path(s)...", len(paths))
+	if err := o.snapshotIndex(ctx); err != nil {
+		o.logger.Debug("Staging can't be rolled back if interrupted: %v", err)
+	}
+	if err := o.backend.Add(ctx, paths); err != nil {
+		o.logger.Error("Failed to stage changes")
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
+	if err := o.enforceAllowedExtensions(ctx); err != nil {
+		return err
+	}
+	if err := o.enforceExcludes(ctx); err != nil {
+		return err
+	}
+
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stagedPaths(entries)) == 0 {
+		o.logger.Warning("No changes to stage")
+		return ErrNoChanges
+	}
+	o.logger.Success("Changes staged")
+	return nil
+}
+
+// StageWithUntracked stages changes to tracked files everywhere, but untracked
+// files only under the given directories; other untracked files are left alone
+func (o *Operations) StageWithUntracked(ctx context.Context, dirs []string) error {
+	if err := o.requireWorktree(ctx, "stage changes"); err != nil {
+		return err
+	}
+	o.logger.Step("Staging tracked changes...")
+	if err := o.snapshotIndex(ctx); err != nil {
+		o.logger.Debug("Staging can't be rolled back if interrupted: %v", err)
+	}
+	if err := o.runCommand(ctx, "git", "add", "-u"); err != nil {
+		o.logger.Error("Failed to stage changes")
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
+
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	var include []string
+	skipped := 0
+	for _, e := range entries {
+		if !e.IsUntracked() {
+			continue
+		}
+		if target := untrackedTarget(e.Path, dirs); target != "" {
+			include = append(include, target)
+		} else {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		o.logger.Info("Leaving %d untracked path(s) outside %s unstaged", skipped, strings.Join(dirs, ", "))
+	}
+
+	if len(include) > 0 {
+		o.logger.Step("Staging %d untracked path(s)...", len(include))
+		args := append([]string{"add", "--"}, include...)
+		if err := o.runCommand(ctx, "git", args...); err != nil {
+			o.logger.Error("Failed to stage untracked files")
+			return fmt.Errorf("failed to stage untracked files: %w", err)
+		}
+	}
+
+	if err := o.enforceAllowedExtensions(ctx); err != nil {
+		return err
+	}
+	if err := o.enforceExcludes(ctx); err != nil {
+		return err
+	}
+	if entries, err = o.GetStatus(ctx); err != nil {
+		return err
+	}
+	if len(stagedPaths(entries)) == 0 {
+		o.logger.Warning("No changes to stage")
+		return ErrNoChanges
+	}
+	o.logger.Success("Changes staged")
+	return nil
+}
+
+// untrackedTarget returns what to stage for an untracked status path when it
+// falls under one of dirs. git status collapses untracked directories to
+// "dir/", so a collapsed entry containing a requested directory yields that directory.
+func untrackedTarget(path string, dirs []string) string {
+	for _, dir := range dirs {
+		dir = strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/")
+		if dir == "" || dir == "." {
+			return path
+		}
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return path
+		}
+		if strings.HasSuffix(path, "/") && strings.HasPrefix(dir+"/", path) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// RestageModified re-stages staged files that changed in the working tree
+// since they were staged (e.g. by a formatter) and returns their paths
+func (o *Operations) RestageModified(ctx context.Context) ([]string, error) {
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var modified []string
+	for _, e := range entries {
+		if e.IsStaged() && e.Worktree == 'M' {
+			modified = append(modified, e.Path)
+		}
+	}
+	if len(modified) == 0 {
+		return nil, nil
+	}
+	if err := o.StageFiles(ctx, modified); err != nil {
+		return nil, err
+	}
+	return modified, nil
+}
+
+// enforceAllowedExtensions unstages (or, in strict mode, rejects) staged files
+// whose extension is not in the allowlist
+func (o *Operations) enforceAllowedExtensions(ctx context.Context) error {
+	if len(o.allowedExts) == 0 {
+		return nil
+	}
+
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	var disallowed []string
+	for _, p := range stagedPaths(entries) {
+		if !o.extensionAllowed(p) {
+			disallowed = append(disallowed, p)
+		}
+	}
+	if len(disallowed) == 0 {
+		return nil
+	}
+
+	if o.strictExts {
+		o.logger.Error("Staged files outside the extension allowlist: %s", strings.Join(disallowed, ", "))
+		return fmt.Errorf("%d staged file(s) not in the extension allowlist (%s): %s",
+			len(disallowed), strings.Join(o.allowedExts, ", "), strings.Join(disallowed, ", "))
+	}
+
+	for _, p := range disallowed {
+		o.logger.Warning("Skipping %s (extension not allowed)", p)
+	}
+	if err := o.Unstage(ctx, disallowed); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Unstage removes the given paths from the index, keeping working tree changes
+func (o *Operations) Unstage(ctx context.Context, paths []string) error {
+	if err := o.backend.Reset(ctx, paths); err != nil {
+		o.logger.Error("Failed to unstage files")
+		return fmt.Errorf("failed to unstage files: %w", err)
+	}
+	return nil
+}
+
+func (o *Operations) extensionAllowed(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, allowed := range o.allowedExts {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// stagedPaths returns the paths of entries with changes in the index
+func stagedPaths(entries []FileStatus) []string {
+	var paths []string
+	for _, e := range entries {
+		if e.IsStaged() {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
+}
+
+// StageDeletions stages only files deleted in the working tree, leaving other
+// modifications unstaged, and returns the staged paths
+func (o *Operations) StageDeletions(ctx context.Context) ([]string, error) {
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, e := range entries {
+		if e.Worktree == 'D' {
+			deleted = append(deleted, e.Path)
+		}
+	}
+	if len(deleted) == 0 {
+		o.logger.Warning("No deleted files to stage")
+		return nil, ErrNoChanges
+	}
+
+	for _, p := range stagedPaths(entries) {
+		o.logger.Warning("%s was already staged and will be included in the commit", p)
+	}
+	if err := o.StageFiles(ctx, deleted); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,184 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoStash is returned when the stash is empty or has no entry by the given name
+var ErrNoStash = errors.New("no matching stash entry")
+
+// StashEntry is one entry of the stash
+type StashEntry struct {
+	// Ref is the entry's current name, stash@{n}; it shifts as entries are added and dropped
+	Ref   string `json:"ref"`
+	Index int    `json:"index"`
+	// Name is the message it was saved with, without git's "On <branch>: " prefix
+	Name   string    `json:"name"`
+	Branch string    `json:"branch"`
+	Time   time.Time `json:"time"`
+}
+
+// Stash saves local changes (including untracked files) under the given message.
+// It reports whether anything was stashed.
+func (o *Operations) Stash(ctx context.Context, message string) (bool, error) {
+	return o.SaveStash(ctx, message, false, nil)
+}
+
+// StashPop restores the most recent stash entry, staged changes included
+// when the index can be restored
+func (o *Operations) StashPop(ctx context.Context) error {
+	o.logger.Step("Restoring stashed changes...")
+	err := o.runCommand(ctx, "git", "stash", "pop", "--index")
+	if err != nil && strings.Contains(err.Error(), "without --index") {
+		err = o.runCommand(ctx, "git", "stash", "pop")
+	}
+	if err != nil {
+		o.logger.Error("Failed to restore stashed changes")
+		o.logger.Warning("Your changes are still in the stash; resolve any conflicts, then run 'ghquick stash drop stash@{0}'")
+		return fmt.Errorf("failed to pop stash: %w", err)
+	}
+	o.logger.Success("Stashed changes restored")
+	return nil
+}
+
+// StashCount returns how many entries the stash holds
+func (o *Operations) StashCount(ctx context.Context) (int, error) {
+	out, err := o.output(ctx, "stash", "list", "--format=%gd")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	if out == "" {
+		return 0, nil
+	}
+	return strings.Count(out, "\n") + 1, nil
+}
+
+// ListStashes returns the stash entries, most recent first
+func (o *Operations) ListStashes(ctx context.Context) ([]StashEntry, error) {
+	out, err := o.output(ctx, "stash", "list", "--format=%gd%x00%gs%x00%ct")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	entries := []StashEntry{}
+	for i, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			continue
+		}
+		entry := StashEntry{Ref: fields[0], Index: i, Name: fields[1]}
+		// "On <branch>: <message>" for named entries, "WIP on <branch>: <sha> <subject>" otherwise
+		if head, name, ok := strings.Cut(fields[1], ": "); ok {
+			entry.Name = name
+			entry.Branch = strings.TrimPrefix(strings.TrimPrefix(head, "WIP on "), "On ")
+		}
+		if secs, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			entry.Time = time.Unix(secs, 0)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// FindStash looks up a stash entry by ref (stash@{1}), the name it was saved
+// with, or index (1), in that order, so an entry named "2" is found by its name
+// before stash@{2}; the most recent entry of a name wins. An empty name means
+// the most recent entry.
+func (o *Operations) FindStash(ctx context.Context, name string) (StashEntry, error) {
+	entries, err := o.ListStashes(ctx)
+	if err != nil {
+		return StashEntry{}, err
+	}
+	for _, e := range entries {
+		if name == "" || name == e.Ref || name == e.Name {
+			return e, nil
+		}
+	}
+	for _, e := range entries {
+		if name == strconv.Itoa(e.Index) {
+			return e, nil
+		}
+	}
+	if name == "" {
+		return StashEntry{}, fmt.Errorf("%w: the stash is empty", ErrNoStash)
+	}
+	return StashEntry{}, fmt.Errorf("%w: %s", ErrNoStash, name)
+}
+
+// SaveStash stashes local changes under name, leaving the working tree clean.
+// Untracked files are included unless trackedOnly is set; with paths only
+// those are stashed. It reports whether anything was stashed.
+func (o *Operations) SaveStash(ctx context.Context, name string, trackedOnly bool, paths []string) (bool, error) {
+	if err := o.requireWorktree(ctx, "stash changes"); err != nil {
+		return false, err
+	}
+	clean, err := o.IsClean(ctx, trackedOnly)
+	if err != nil {
+		return false, err
+	}
+	if clean {
+		return false, nil
+	}
+
+	args := []string{"stash", "push"}
+	if !trackedOnly {
+		args = append(args, "--include-untracked")
+	}
+	if name != "" {
+		args = append(args, "-m", name)
+	}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	o.logger.Step("Stashing local changes...")
+	if err := o.runCommand(ctx, "git", args...); err != nil {
+		o.logger.Error("Failed to stash changes")
+		return false, fmt.Errorf("failed to stash changes: %w", err)
+	}
+	o.logger.Success("Local changes stashed")
+	return true, nil
+}
+
+// ApplyStash restores a stash entry onto the working tree, and with drop
+// removes it from the stash (git stash pop). An entry that doesn't apply
+// cleanly stays in the stash so nothing is lost.
+func (o *Operations) ApplyStash(ctx context.Context, entry StashEntry, drop bool) error {
+	if err := o.requireWorktree(ctx, "restore a stash"); err != nil {
+		return err
+	}
+	verb := "apply"
+	if drop {
+		verb = "pop"
+	}
+	o.logger.Step("Restoring %s (%s)...", entry.Ref, entry.Name)
+	// --index brings back what was staged; it refuses when the index can't be
+	// restored, and then only the working tree changes are
+	err := o.runCommand(ctx, "git", "stash", verb, "--index", entry.Ref)
+	if err != nil && strings.Contains(err.Error(), "without --index") {
+		o.logger.Debug("Restoring %s without its staged state", entry.Ref)
+		err = o.runCommand(ctx, "git", "stash", verb, entry.Ref)
+	}
+	if err != nil {
+		o.logger.Error("Failed to restore %s", entry.Ref)
+		conflicts, _ := o.output(ctx, "diff", "--name-only", "-z", "--diff-filter=U")
+		if files := splitNames(conflicts); len(files) > 0 {
+			o.logger.Warning("Conflicts in: %s; %s is kept in the stash", strings.Join(files, ", "), entry.Ref)
+		}
+		return fmt.Errorf("failed to %s %s: %w", verb, entry.Ref, err)
+	}
+	o.logger.Success("Restored %s (%s)", entry.Ref, entry.Name)
+	return nil
+}
+
+// DropStash removes a stash entry
+func (o *Operations) DropStash(ctx context.Context, entry StashEntry) error {
+	if err := o.runCommand(ctx, "git", "stash", "drop", entry.Ref); err != nil {
+		return fmt.Errorf("failed to drop %s: %w", entry.Ref, err)
+	}
+	o.logger.Success("Dropped %s (%s)", entry.Ref, entry.Name)
+	return nil
+}
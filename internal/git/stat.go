@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffStat summarizes the size of a change the way `git diff --stat` does
+type DiffStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+	// Summary is the full --stat output: one line per file plus the totals line
+	Summary string
+}
+
+var shortStatPart = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// StagedDiffStat returns the --stat summary of the staged changes. With amend
+// the changes are measured against HEAD's parent, covering what the amended
+// commit will contain.
+func (o *Operations) StagedDiffStat(ctx context.Context, amend bool) (DiffStat, error) {
+	args := []string{"diff", "--cached", "--stat=100"}
+	if amend {
+		base, err := o.amendBase(ctx)
+		if err != nil {
+			return DiffStat{}, err
+		}
+		args = append(args, base)
+	}
+	// Keep the leading space git aligns the file column with
+	out, err := o.limitedOutput(ctx, o.gitCommand(ctx, args...))
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("failed to get diff stat: %w", err)
+	}
+	return parseDiffStat(strings.TrimRight(string(out), "\n")), nil
+}
+
+// parseDiffStat reads the totals from the last line of --stat output, e.g.
+// " 3 files changed, 10 insertions(+), 2 deletions(-)"
+func parseDiffStat(out string) DiffStat {
+	stat := DiffStat{Summary: out}
+	lines := strings.Split(out, "\n")
+	for _, m := range shortStatPart.FindAllStringSubmatch(lines[len(lines)-1], -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "file":
+			stat.Files = n
+		case "insertion":
+			stat.Insertions = n
+		case "deletion":
+			stat.Deletions = n
+		}
+	}
+	return stat
+}
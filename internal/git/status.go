@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FileStatus is a single entry of `git status --porcelain`
+type FileStatus struct {
+	Path     string
+	OrigPath string
+	Index    byte
+	Worktree byte
+}
+
+// IsStaged reports whether the entry has changes in the index
+func (s FileStatus) IsStaged() bool {
+	return s.Index != ' ' && s.Index != '?' && s.Index != '!'
+}
+
+// IsUntracked reports whether the file is not tracked by git
+func (s FileStatus) IsUntracked() bool {
+	return s.Index == '?' && s.Worktree == '?'
+}
+
+// IsUnstaged reports whether the entry has working tree changes not yet in the index
+func (s FileStatus) IsUnstaged() bool {
+	return s.Worktree != ' ' && !s.IsUntracked()
+}
+
+// IsDeleted reports whether the file was deleted in the working tree or index
+func (s FileStatus) IsDeleted() bool {
+	return s.Index == 'D' || s.Worktree == 'D'
+}
+
+// GetStatus returns the changed and untracked files, as `git status --porcelain` lists them
+func (o *Operations) GetStatus(ctx context.Context) ([]FileStatus, error) {
+	entries, err := o.backend.Status(ctx)
+	if err != nil {
+		o.logger.Error("Failed to check git status")
+		return nil, fmt.Errorf("failed to check git status: %w", err)
+	}
+	return entries, nil
+}
+
+// ParseStatus parses porcelain v1 status output
+func ParseStatus(output string) []FileStatus {
+	var entries []FileStatus
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		entry := FileStatus{
+			Index:    line[0],
+			Worktree: line[1],
+			Path:     unquotePath(line[3:]),
+		}
+		if from, to, ok := strings.Cut(entry.Path, " -> "); ok {
+			entry.OrigPath = unquotePath(from)
+			entry.Path = unquotePath(to)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// IsClean reports whether the working tree has no changes, ignoring untracked files if requested
+func (o *Operations) IsClean(ctx context.Context, ignoreUntracked bool) (bool, error) {
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if ignoreUntracked && e.IsUntracked() {
+			continue
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// unquotePath strips the quotes git adds around paths with special characters
+func unquotePath(p string) string {
+	if len(p) >= 2 && p[0] == '"' && p[len(p)-1] == '"' {
+		return p[1 : len(p)-1]
+	}
+	return p
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saint/ghquick/internal/log"
+)
+
+// progressLine matches git's transfer progress, e.g.
+// "Writing objects:  45% (9/20), 1.20 MiB | 2.00 MiB/s", optionally prefixed with "remote: "
+var progressLine = regexp.MustCompile(`^(?:remote: )?([A-Za-z][A-Za-z ]*):\s+(\d{1,3})% \(\d+/\d+\)`)
+
+// credentialURL matches the user:token part of a URL in command output
+var credentialURL = regexp.MustCompile(`([a-z][a-z0-9+.-]*://)[^/\s@]+@`)
+
+// isStreamedCommand reports whether a git command can run long enough that
+// its output should be shown as it arrives instead of only on failure
+func isStreamedCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "push", "fetch", "pull", "clone":
+		return true
+	}
+	return false
+}
+
+// withProgress asks git for progress output even though stderr is a pipe
+func withProgress(args []string) []string {
+	return append([]string{args[0], "--progress"}, args[1:]...)
+}
+
+// streamWriter passes a command's output to the logger line by line as it is
+// written, and keeps everything but the progress updates for error messages
+type streamWriter struct {
+	logger  *log.Logger
+	output  bytes.Buffer
+	pending []byte
+	// stage and percent are the last progress update shown
+	stage   string
+	percent int
+}
+
+func newStreamWriter(logger *log.Logger) *streamWriter {
+	return &streamWriter{logger: logger, percent: -1}
+}
+
+// Write splits the output on newlines and carriage returns; git ends progress
+// updates with \r and finished lines with \n
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexAny(w.pending, "\r\n")
+		if i < 0 {
+			break
+		}
+		line, sep := string(w.pending[:i]), w.pending[i]
+		w.pending = w.pending[i+1:]
+		w.line(line, sep == '\n')
+	}
+	return len(p), nil
+}
+
+// Flush shows output left without a final newline
+func (w *streamWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.line(string(w.pending), true)
+		w.pending = nil
+	}
+}
+
+func (w *streamWriter) line(line string, finished bool) {
+	line = credentialURL.ReplaceAllString(strings.TrimRight(line, " "), "$1***@")
+	if m := progressLine.FindStringSubmatch(line); m != nil {
+		percent, _ := strconv.Atoi(m[2])
+		done := finished || strings.HasSuffix(line, "done.")
+		// Redrawing on every object is wasteful; a few steps per stage is enough
+		if m[1] != w.stage || done || percent >= w.percent+5 {
+			w.stage, w.percent = m[1], percent
+			w.logger.Progress(m[1], percent, line, done)
+		}
+		if done {
+			w.output.WriteString(line + "\n")
+		}
+		return
+	}
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	w.output.WriteString(line + "\n")
+	w.logger.Output(line)
+}
+
+// String returns the output kept for error messages
+func (w *streamWriter) String() string {
+	return w.output.String()
+}
@@ -0,0 +1,341 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SubmodulePolicy decides what happens when a submodule has a detached HEAD with local commits
+type SubmodulePolicy string
+
+const (
+	// SubmoduleWarn reports such submodules but carries on (the default)
+	SubmoduleWarn SubmodulePolicy = "warn"
+	// SubmoduleBlock refuses to commit until they are resolved
+	SubmoduleBlock SubmodulePolicy = "block"
+	// SubmoduleIgnore skips the check
+	SubmoduleIgnore SubmodulePolicy = "ignore"
+)
+
+// ParseSubmodulePolicy validates a policy name, defaulting to SubmoduleWarn when empty
+func ParseSubmodulePolicy(s string) (SubmodulePolicy, error) {
+	switch SubmodulePolicy(s) {
+	case "":
+		return SubmoduleWarn, nil
+	case SubmoduleWarn, SubmoduleBlock, SubmoduleIgnore:
+		return SubmodulePolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid submodule policy %q (expected warn, block, or ignore)", s)
+}
+
+// DetachedSubmodule is a submodule whose HEAD is detached on commits no remote has
+type DetachedSubmodule struct {
+	Path         string
+	SHA          string
+	LocalCommits int
+}
+
+// DetachedSubmodules checks every initialized submodule (recursively) and returns
+// those with a detached HEAD carrying commits that aren't on any remote branch.
+// Recording their pointer in the parent would reference work nobody else can fetch.
+func (o *Operations) DetachedSubmodules(ctx context.Context) ([]DetachedSubmodule, error) {
+	if err := o.requireWorktree(ctx, "check submodules"); err != nil {
+		return nil, err
+	}
+	out, err := o.output(ctx, "submodule", "status", "--recursive")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+
+	var detached []DetachedSubmodule
+	for _, line := range strings.Split(out, "\n") {
+		// Lines are "<flag><sha> <path> (<describe>)"; "-" marks an uninitialized submodule
+		if len(line) < 2 || line[0] == '-' {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) < 2 {
+			continue
+		}
+		sha, path := fields[0], fields[1]
+
+		if _, err := o.output(ctx, "-C", path, "symbolic-ref", "-q", "HEAD"); err == nil {
+			continue // on a branch
+		}
+		count, err := o.output(ctx, "-C", path, "rev-list", "--count", "HEAD", "--not", "--remotes")
+		if err != nil {
+			return nil, fmt.Errorf("failed to check submodule %s: %w", path, err)
+		}
+		if n, _ := strconv.Atoi(count); n > 0 {
+			detached = append(detached, DetachedSubmodule{Path: path, SHA: sha, LocalCommits: n})
+		}
+	}
+	return detached, nil
+}
+
+// CheckSubmodules applies policy to submodules with detached local commits
+func (o *Operations) CheckSubmodules(ctx context.Context, policy SubmodulePolicy) error {
+	if policy == SubmoduleIgnore {
+		return nil
+	}
+	detached, err := o.DetachedSubmodules(ctx)
+	if err != nil || len(detached) == 0 {
+		return err
+	}
+
+	for _, d := range detached {
+		o.logger.Warning("Submodule %s is detached at %.7s with %d commit(s) not on any remote", d.Path, d.SHA, d.LocalCommits)
+	}
+	if policy == SubmoduleBlock {
+		o.logger.Error("Push the submodule commits (or check out a branch) before committing the parent")
+		return fmt.Errorf("%d submodule(s) have detached HEADs with unpushed commits", len(detached))
+	}
+	return nil
+}
+
+// HasSubmodules reports whether the repository declares submodules in .gitmodules
+func (o *Operations) HasSubmodules(ctx context.Context) bool {
+	root, err := o.output(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(root, ".gitmodules"))
+	return err == nil
+}
+
+// submoduleStatus is a line of 'git submodule status': the commit checked out
+// and a flag, "-" when uninitialized or "+" when the commit isn't the recorded one
+type submoduleStatus struct {
+	Flag byte
+	SHA  string
+	Path string
+}
+
+// submodules lists the top-level submodules, with paths relative to the working directory
+func (o *Operations) submodules(ctx context.Context) ([]submoduleStatus, error) {
+	out, err := o.output(ctx, "submodule", "status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+	var subs []submoduleStatus
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		if fields := strings.Fields(line[1:]); len(fields) >= 2 {
+			subs = append(subs, submoduleStatus{Flag: line[0], SHA: fields[0], Path: fields[1]})
+		}
+	}
+	return subs, nil
+}
+
+// InitSubmodules clones and checks out, recursively, the submodules declared in
+// .gitmodules that aren't initialized yet. Submodules already checked out are
+// left alone, so commits and pointer bumps in them aren't undone.
+func (o *Operations) InitSubmodules(ctx context.Context) error {
+	if !o.HasSubmodules(ctx) {
+		return nil
+	}
+	subs, err := o.submodules(ctx)
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, s := range subs {
+		if s.Flag == '-' {
+			paths = append(paths, s.Path)
+		}
+	}
+	if len(paths) == 0 {
+		o.logger.Debug("Submodules already initialized")
+		return nil
+	}
+
+	o.logger.Step("Initializing %d submodule(s)...", len(paths))
+	args := append([]string{"submodule", "update", "--init", "--recursive", "--"}, paths...)
+	if err := o.runCommand(ctx, "git", args...); err != nil {
+		o.logger.Error("Failed to initialize submodules")
+		return fmt.Errorf("failed to initialize submodules: %w", err)
+	}
+	o.logger.Success("Submodules initialized")
+	return nil
+}
+
+// DirtySubmodule is a submodule with changes inside it that committing the
+// parent doesn't record
+type DirtySubmodule struct {
+	// Path is relative to the repository root
+	Path string
+	// Modified is set for uncommitted changes to tracked files, nested submodules included
+	Modified bool
+	// Untracked is set for untracked files
+	Untracked bool
+}
+
+// DirtySubmodules returns the submodules with uncommitted changes or untracked
+// files. git add in the parent only stages a submodule's commit, so those
+// changes are left out of the parent's commit.
+func (o *Operations) DirtySubmodules(ctx context.Context) ([]DirtySubmodule, error) {
+	out, err := o.output(ctx, "status", "--porcelain=v2", "--ignore-submodules=none")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check submodules: %w", err)
+	}
+	var dirty []DirtySubmodule
+	for _, line := range strings.Split(out, "\n") {
+		// "1 XY <sub> <mH> <mI> <mW> <hH> <hI> <path>", where <sub> is "S<c><m><u>"
+		// for a submodule; renames ("2 ...") add a score and "\t<orig>"
+		fields := strings.SplitN(line, " ", 10)
+		var path string
+		switch {
+		case len(fields) >= 9 && fields[0] == "1":
+			path = strings.Join(fields[8:], " ")
+		case len(fields) == 10 && fields[0] == "2":
+			path, _, _ = strings.Cut(fields[9], "\t")
+		default:
+			continue
+		}
+		sub := fields[2]
+		if len(sub) != 4 || sub[0] != 'S' {
+			continue
+		}
+		if d := (DirtySubmodule{Path: path, Modified: sub[2] == 'M', Untracked: sub[3] == 'U'}); d.Modified || d.Untracked {
+			dirty = append(dirty, d)
+		}
+	}
+	return dirty, nil
+}
+
+// warnDirtySubmodules tells the user about submodule changes staging leaves out
+func (o *Operations) warnDirtySubmodules(ctx context.Context) {
+	if !o.HasSubmodules(ctx) {
+		return
+	}
+	dirty, err := o.DirtySubmodules(ctx)
+	if err != nil {
+		o.logger.Debug("Failed to check submodules: %v", err)
+		return
+	}
+	for _, d := range dirty {
+		what := "uncommitted changes"
+		if !d.Modified {
+			what = "untracked files"
+		}
+		o.logger.Warning("Submodule %s has %s that won't be committed; commit them inside the submodule first", d.Path, what)
+	}
+}
+
+// SubmoduleBump is a submodule moved to a new commit
+type SubmoduleBump struct {
+	Path string `json:"path"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SyncSubmodules checks out every top-level submodule at the tip of the branch
+// it tracks (submodule.<name>.branch, else the remote's default branch), with
+// their own submodules at the commits they record, and returns the submodules
+// that moved. Nothing is committed; see CommitSubmoduleBumps. Submodules with
+// uncommitted changes are refused rather than checked out over.
+func (o *Operations) SyncSubmodules(ctx context.Context) ([]SubmoduleBump, error) {
+	if err := o.requireWorktree(ctx, "sync submodules"); err != nil {
+		return nil, err
+	}
+	if !o.HasSubmodules(ctx) {
+		return nil, nil
+	}
+	dirty, err := o.DirtySubmodules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirty {
+		if d.Modified {
+			o.logger.Error("Submodule %s has uncommitted changes", d.Path)
+			return nil, fmt.Errorf("submodule %s has uncommitted changes; commit or stash them inside it first", d.Path)
+		}
+	}
+
+	o.logger.Step("Updating submodules to their tracked branches...")
+	if err := o.runCommand(ctx, "git", "submodule", "update", "--init", "--remote"); err != nil {
+		o.logger.Error("Failed to update submodules")
+		return nil, fmt.Errorf("failed to update submodules: %w", err)
+	}
+	if o.dryRun {
+		return nil, nil
+	}
+
+	subs, err := o.submodules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var bumps []SubmoduleBump
+	for _, s := range subs {
+		if s.Flag != '+' {
+			continue
+		}
+		// "<mode> <sha> <stage>\t<path>"
+		entry, err := o.output(ctx, "ls-files", "-s", "--", s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the recorded commit of %s: %w", s.Path, err)
+		}
+		from := ""
+		if fields := strings.Fields(entry); len(fields) >= 2 {
+			from = fields[1]
+		}
+		if err := o.runCommand(ctx, "git", "-C", s.Path, "submodule", "update", "--init", "--recursive"); err != nil {
+			o.logger.Error("Failed to update the submodules of %s", s.Path)
+			return nil, fmt.Errorf("failed to update the submodules of %s: %w", s.Path, err)
+		}
+		bumps = append(bumps, SubmoduleBump{Path: s.Path, From: from, To: s.SHA})
+	}
+	if len(bumps) == 0 {
+		o.logger.Success("Submodules are up to date")
+	} else {
+		o.logger.Success("%d submodule(s) updated", len(bumps))
+	}
+	return bumps, nil
+}
+
+// SubmoduleBumpMessage is the default commit message for bumps, listing each
+// submodule's old and new commit
+func SubmoduleBumpMessage(bumps []SubmoduleBump) string {
+	var b strings.Builder
+	if len(bumps) == 1 {
+		fmt.Fprintf(&b, "chore: update submodule %s\n\n", bumps[0].Path)
+	} else {
+		b.WriteString("chore: update submodules\n\n")
+	}
+	for _, bump := range bumps {
+		fmt.Fprintf(&b, "- %s: %.7s..%.7s\n", bump.Path, bump.From, bump.To)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// CommitSubmoduleBumps commits the new submodule pointers, and only those;
+// anything else already staged stays staged
+func (o *Operations) CommitSubmoduleBumps(ctx context.Context, bumps []SubmoduleBump, message string) error {
+	if len(bumps) == 0 {
+		return ErrNoChanges
+	}
+	if message == "" {
+		message = SubmoduleBumpMessage(bumps)
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would commit with message:\n%s", message)
+		return nil
+	}
+	o.logger.Step("Committing submodule updates...")
+	args := []string{"-m", message, "--"}
+	for _, bump := range bumps {
+		args = append(args, bump.Path)
+	}
+	if err := o.runCommit(ctx, o.identityEnv(), args...); err != nil {
+		o.logger.Error("Failed to commit submodule updates")
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	o.logger.Success("Submodule updates committed")
+	return nil
+}
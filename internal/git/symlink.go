@@ -0,0 +1,79 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// symlinkMode is the git file mode of a symbolic link
+const symlinkMode = "120000"
+
+// SymlinkPolicy decides what happens to staged symbolic links
+type SymlinkPolicy string
+
+const (
+	// SymlinkKeep commits symlinks as symlinks, git's normal behavior
+	SymlinkKeep SymlinkPolicy = "keep"
+	// SymlinkWarn commits them but warns about each one
+	SymlinkWarn SymlinkPolicy = "warn"
+	// SymlinkSkip unstages them so they are left out of the commit
+	SymlinkSkip SymlinkPolicy = "skip"
+)
+
+// ParseSymlinkPolicy validates a policy name, defaulting to SymlinkKeep when empty
+func ParseSymlinkPolicy(s string) (SymlinkPolicy, error) {
+	switch SymlinkPolicy(s) {
+	case "":
+		return SymlinkKeep, nil
+	case SymlinkKeep, SymlinkWarn, SymlinkSkip:
+		return SymlinkPolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid symlink policy %q (expected keep, warn, or skip)", s)
+}
+
+// StagedSymlinks returns the staged paths that are added or changed as symbolic links
+func (o *Operations) StagedSymlinks(ctx context.Context) ([]string, error) {
+	out, err := o.output(ctx, "diff", "--cached", "--raw", "-z", "--no-renames")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged file modes: %w", err)
+	}
+
+	// Records are ":<old mode> <new mode> <old sha> <new sha> <status>\0<path>\0"
+	var links []string
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) >= 2 && meta[1] == symlinkMode {
+			links = append(links, fields[i+1])
+		}
+	}
+	return links, nil
+}
+
+// ApplySymlinkPolicy checks the staged changes for symlinks and warns about or
+// unstages them according to policy. It returns the symlinks found.
+func (o *Operations) ApplySymlinkPolicy(ctx context.Context, policy SymlinkPolicy) ([]string, error) {
+	if policy == SymlinkKeep {
+		return nil, nil
+	}
+	links, err := o.StagedSymlinks(ctx)
+	if err != nil || len(links) == 0 {
+		return links, err
+	}
+
+	if policy == SymlinkWarn {
+		for _, p := range links {
+			o.logger.Warning("Committing symlink %s; it may be checked out as a plain file on systems without symlink support", p)
+		}
+		return links, nil
+	}
+
+	for _, p := range links {
+		o.logger.Warning("Skipping symlink %s", p)
+	}
+	if err := o.Unstage(ctx, links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
@@ -0,0 +1,258 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNonFastForward is returned by Push when the remote branch has commits the
+// local branch doesn't, so the push was refused rather than failing outright
+var ErrNonFastForward = errors.New("push rejected: the remote contains work that you do not have locally")
+
+// nonFastForward matches git's output for a push refused because the branch is behind its remote
+var nonFastForward = regexp.MustCompile(`(?i)non-fast-forward|\(fetch first\)|remote contains work that you do\s+not have locally|tip of your current branch is behind`)
+
+// ErrProtectedBranch is returned by Push when the remote refuses updates to
+// the branch outright, e.g. a GitHub protected branch or repository ruleset
+var ErrProtectedBranch = errors.New("push rejected: the remote branch is protected")
+
+// protectedBranch matches the errors GitHub and other hosts give for a push to
+// a branch that only accepts changes through pull requests: GH006 for branch
+// protection, and the ruleset's own reason under GH013. GH013 alone isn't
+// enough, as secret scanning push protection reports through it too.
+var protectedBranch = regexp.MustCompile(`(?i)GH006|protected branch|changes must be made through a pull request|not allowed to push code to protected branches`)
+
+// RebaseConflictError reports the files that stopped a rebase; the rebase has
+// already been aborted when it is returned
+type RebaseConflictError struct {
+	Remote string
+	Branch string
+	Files  []string
+}
+
+func (e *RebaseConflictError) Error() string {
+	return fmt.Sprintf("rebasing onto %s/%s conflicts in %d file(s): %s; the rebase was aborted and your branch is unchanged",
+		e.Remote, e.Branch, len(e.Files), strings.Join(e.Files, ", "))
+}
+
+// PullRebase replays local commits on top of the remote branch with
+// `git pull --rebase --autostash`. If the rebase stops on conflicts it is
+// aborted, HEAD is put back where it was, and a *RebaseConflictError names
+// the conflicting files.
+func (o *Operations) PullRebase(ctx context.Context, remote, branch string) error {
+	orig, err := o.output(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	o.logger.Step("Rebasing onto %s/%s...", remote, branch)
+	pullErr := o.runCommand(ctx, "git", "pull", "--rebase", "--autostash", remote, branch)
+	if pullErr == nil {
+		o.logger.Success("Rebased onto %s/%s", remote, branch)
+		return nil
+	}
+
+	conflicts, _ := o.output(ctx, "diff", "--name-only", "-z", "--diff-filter=U")
+	files := splitNames(conflicts)
+
+	// Abort whatever the pull left in progress; this also restores an autostash
+	if o.rebaseInProgress(ctx) {
+		if err := o.runCommand(ctx, "git", "rebase", "--abort"); err != nil {
+			o.logger.Error("Failed to abort the rebase")
+			return fmt.Errorf("rebase failed and could not be aborted (run 'git rebase --abort'): %w", err)
+		}
+	}
+	if head, err := o.output(ctx, "rev-parse", "HEAD"); err == nil && head != orig {
+		if err := o.runCommand(ctx, "git", "reset", "--keep", orig); err != nil {
+			return fmt.Errorf("failed to restore HEAD to %s: %w", orig, err)
+		}
+	}
+
+	if len(files) > 0 {
+		o.logger.Error("Rebase conflicts in: %s", strings.Join(files, ", "))
+		return &RebaseConflictError{Remote: remote, Branch: branch, Files: files}
+	}
+	o.logger.Error("Failed to rebase onto %s/%s", remote, branch)
+	return fmt.Errorf("failed to pull --rebase from %s/%s: %w", remote, branch, pullErr)
+}
+
+// rebaseInProgress reports whether a rebase has stopped partway through
+func (o *Operations) rebaseInProgress(ctx context.Context) bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := o.output(ctx, "rev-parse", "--git-path", dir)
+		if err != nil {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(o.workingDir, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncStrategy decides how Sync brings upstream commits into the current branch
+type SyncStrategy string
+
+const (
+	// SyncRebase replays local commits on top of the upstream branch (the default)
+	SyncRebase SyncStrategy = "rebase"
+	// SyncMerge merges the upstream branch, keeping local history as it is
+	SyncMerge SyncStrategy = "merge"
+)
+
+// ParseSyncStrategy validates a strategy name, defaulting to SyncRebase when empty
+func ParseSyncStrategy(s string) (SyncStrategy, error) {
+	switch SyncStrategy(strings.ToLower(s)) {
+	case "":
+		return SyncRebase, nil
+	case SyncRebase, SyncMerge:
+		return SyncStrategy(strings.ToLower(s)), nil
+	}
+	return "", fmt.Errorf("invalid sync strategy %q (expected rebase or merge)", s)
+}
+
+// SyncConflictError reports the files that stopped a Sync. Unless Aborted is
+// set, the rebase or merge is still in progress for the user to resolve.
+type SyncConflictError struct {
+	Strategy SyncStrategy
+	Upstream string
+	Files    []string
+	Aborted  bool
+}
+
+func (e *SyncConflictError) Error() string {
+	verb := "rebasing onto"
+	if e.Strategy == SyncMerge {
+		verb = "merging"
+	}
+	msg := fmt.Sprintf("%s %s conflicts in %d file(s): %s", verb, e.Upstream, len(e.Files), strings.Join(e.Files, ", "))
+	if e.Aborted {
+		return msg + "; it was aborted and your branch is unchanged"
+	}
+	return msg + fmt.Sprintf("; resolve them and run 'git %s --continue', or 'ghquick sync --abort'", e.Strategy)
+}
+
+// Upstream returns the remote and branch the current branch tracks
+func (o *Operations) Upstream(ctx context.Context) (string, string, error) {
+	current, err := o.CurrentBranch(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	out, err := o.output(ctx, "for-each-ref", "--format=%(upstream:remotename)%00%(upstream)", "refs/heads/"+current)
+	remote, ref, _ := strings.Cut(out, "\x00")
+	if err != nil || remote == "" || ref == "" {
+		return "", "", fmt.Errorf("branch %s has no upstream", current)
+	}
+	return remote, strings.TrimPrefix(ref, "refs/remotes/"+remote+"/"), nil
+}
+
+// FetchBranch updates the remote-tracking ref for remote/branch; a branch the
+// remote doesn't have is not an error
+func (o *Operations) FetchBranch(ctx context.Context, remote, branch string) error {
+	if err := o.runCommand(ctx, "git", o.fetchArgs(remote, branch)...); err != nil && !strings.Contains(err.Error(), "couldn't find remote ref") {
+		return fmt.Errorf("failed to fetch %s/%s: %w", remote, branch, err)
+	}
+	return nil
+}
+
+// Sync fetches remote and brings remote/branch into the current branch with
+// the given strategy; local changes are stashed around it (--autostash). When
+// it conflicts, the conflicting files are returned in a *SyncConflictError,
+// and with abortOnConflict the rebase or merge is first aborted and HEAD put
+// back where it was.
+func (o *Operations) Sync(ctx context.Context, remote, branch string, strategy SyncStrategy, abortOnConflict bool) error {
+	if err := o.requireWorktree(ctx, "sync"); err != nil {
+		return err
+	}
+	if o.rebaseInProgress(ctx) || o.mergeInProgress(ctx) {
+		return fmt.Errorf("a rebase or merge is already in progress; finish it or run 'ghquick sync --abort'")
+	}
+	upstream := remote + "/" + branch
+
+	o.logger.Step("Fetching %s...", upstream)
+	if err := o.runCommand(ctx, "git", o.fetchArgs(remote, branch)...); err != nil {
+		o.logger.Error("Failed to fetch %s", upstream)
+		return fmt.Errorf("failed to fetch %s: %w", upstream, err)
+	}
+
+	behind, err := o.output(ctx, "rev-list", "--count", "HEAD.."+upstream)
+	if err != nil {
+		return fmt.Errorf("failed to compare with %s: %w", upstream, err)
+	}
+	if behind == "0" {
+		o.logger.Success("Already up to date with %s", upstream)
+		return nil
+	}
+	orig, err := o.HeadCommit(ctx)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"rebase", "--autostash", upstream}
+	if strategy == SyncMerge {
+		args = []string{"merge", "--autostash", "--no-edit", upstream}
+	}
+	o.logger.Step("Bringing in %s commit(s) from %s (%s)...", behind, upstream, strategy)
+	syncErr := o.runCommand(ctx, "git", args...)
+	if syncErr == nil {
+		o.logger.Success("Synced with %s", upstream)
+		return nil
+	}
+
+	conflicts, _ := o.output(ctx, "diff", "--name-only", "-z", "--diff-filter=U")
+	files := splitNames(conflicts)
+	if len(files) > 0 && !abortOnConflict {
+		o.logger.Error("Conflicts in: %s", strings.Join(files, ", "))
+		return &SyncConflictError{Strategy: strategy, Upstream: upstream, Files: files}
+	}
+
+	if err := o.AbortSync(ctx); err != nil {
+		return err
+	}
+	if head, err := o.HeadCommit(ctx); err == nil && head != orig {
+		if err := o.runCommand(ctx, "git", "reset", "--keep", orig); err != nil {
+			return fmt.Errorf("failed to restore HEAD to %s: %w", orig, err)
+		}
+	}
+	if len(files) > 0 {
+		o.logger.Error("Conflicts in: %s", strings.Join(files, ", "))
+		return &SyncConflictError{Strategy: strategy, Upstream: upstream, Files: files, Aborted: true}
+	}
+	o.logger.Error("Failed to sync with %s", upstream)
+	return fmt.Errorf("failed to %s %s: %w", strategy, upstream, syncErr)
+}
+
+// AbortSync aborts a rebase or merge left in progress, restoring any autostash.
+// It does nothing when neither is in progress.
+func (o *Operations) AbortSync(ctx context.Context) error {
+	var op string
+	switch {
+	case o.rebaseInProgress(ctx):
+		op = "rebase"
+	case o.mergeInProgress(ctx):
+		op = "merge"
+	default:
+		return nil
+	}
+	o.logger.Step("Aborting the %s...", op)
+	if err := o.runCommand(ctx, "git", op, "--abort"); err != nil {
+		o.logger.Error("Failed to abort the %s", op)
+		return fmt.Errorf("failed to abort the %s (run 'git %s --abort'): %w", op, op, err)
+	}
+	o.logger.Success("Aborted the %s", op)
+	return nil
+}
+
+// mergeInProgress reports whether a merge has stopped on conflicts
+func (o *Operations) mergeInProgress(ctx context.Context) bool {
+	_, err := o.output(ctx, "rev-parse", "--verify", "--quiet", "MERGE_HEAD")
+	return err == nil
+}
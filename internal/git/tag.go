@@ -0,0 +1,206 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag is a tag in the local repository
+type Tag struct {
+	Name string `json:"name"`
+	// Commit is the commit the tag points at
+	Commit    string `json:"commit"`
+	Annotated bool   `json:"annotated"`
+	// Date is when an annotated tag was made, or its commit's date for a lightweight one
+	Date time.Time `json:"date"`
+	// Message is an annotated tag's subject, or its commit's subject for a lightweight one
+	Message string `json:"message"`
+	Tagger  string `json:"tagger,omitempty"`
+}
+
+// LatestTag returns the most recent tag reachable from HEAD, or "" when there is none
+func (o *Operations) LatestTag(ctx context.Context) string {
+	return o.TagBefore(ctx, "HEAD")
+}
+
+// TagBefore returns the most recent tag reachable from rev, or "" when there is
+// none. Pass "<tag>^" to find the tag before one.
+func (o *Operations) TagBefore(ctx context.Context, rev string) string {
+	tag, err := o.output(ctx, "describe", "--tags", "--abbrev=0", rev)
+	if err != nil {
+		return ""
+	}
+	return tag
+}
+
+// TagExists reports whether the local repository has a tag with the given name
+func (o *Operations) TagExists(ctx context.Context, name string) bool {
+	_, err := o.output(ctx, "show-ref", "--verify", "--quiet", "refs/tags/"+name)
+	return err == nil
+}
+
+// ValidateTagName reports whether name can be used as a tag
+func (o *Operations) ValidateTagName(ctx context.Context, name string) error {
+	if _, err := o.output(ctx, "check-ref-format", "refs/tags/"+name); err != nil {
+		return fmt.Errorf("invalid tag name %q", name)
+	}
+	return nil
+}
+
+// ListTags returns the local tags matching the glob patterns (all of them when
+// there are none), newest first
+func (o *Operations) ListTags(ctx context.Context, patterns ...string) ([]Tag, error) {
+	// %(*...) fields describe the commit an annotated tag points at and are
+	// empty for lightweight tags, whose own fields are the commit's
+	args := []string{"for-each-ref", "--sort=-creatordate",
+		"--format=%(refname:short)%00%(objecttype)%00%(objectname)%00%(*objectname)%00%(creatordate:unix)%00%(contents:subject)%00%(taggername)"}
+	for _, p := range patterns {
+		args = append(args, "refs/tags/"+p)
+	}
+	if len(patterns) == 0 {
+		args = append(args, "refs/tags")
+	}
+	out, err := o.output(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	tags := []Tag{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 7 {
+			continue
+		}
+		tag := Tag{Name: fields[0], Commit: fields[2], Message: fields[5]}
+		if fields[1] == "tag" {
+			tag.Annotated, tag.Commit, tag.Tagger = true, fields[3], fields[6]
+		}
+		if secs, err := strconv.ParseInt(fields[4], 10, 64); err == nil {
+			tag.Date = time.Unix(secs, 0)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// CreateTag creates an annotated tag on HEAD with the given message
+func (o *Operations) CreateTag(ctx context.Context, name, message string) error {
+	return o.CreateTagAt(ctx, name, "HEAD", message, false, false)
+}
+
+// CreateTagAt tags rev (a commit, branch or tag) as name: an annotated tag
+// carrying message, or with lightweight a plain ref. force moves an existing tag.
+func (o *Operations) CreateTagAt(ctx context.Context, name, rev, message string, lightweight, force bool) error {
+	if err := o.ValidateTagName(ctx, name); err != nil {
+		return err
+	}
+	if !force && o.TagExists(ctx, name) {
+		return fmt.Errorf("tag %s already exists", name)
+	}
+	if _, err := o.output(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		return fmt.Errorf("%s is not a commit", rev)
+	}
+
+	args := []string{"tag"}
+	if force {
+		args = append(args, "-f")
+	}
+	if lightweight {
+		args = append(args, name, rev)
+	} else {
+		args = append(args, "-a", "--cleanup=whitespace", name, rev, "-m", message)
+	}
+	if o.dryRun {
+		if lightweight {
+			o.logger.Info("[dry-run] Would tag %s as %s", rev, name)
+		} else {
+			o.logger.Info("[dry-run] Would tag %s as %s with message:\n%s", rev, name, message)
+		}
+		return nil
+	}
+	o.logger.Step("Tagging %s as %s...", rev, name)
+	if err := o.runCommandWithEnv(ctx, o.identityEnv(), "git", args...); err != nil {
+		o.logger.Error("Failed to create tag")
+		return fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+	o.logger.Success("Created tag %s", name)
+	return nil
+}
+
+// DeleteTag removes a local tag
+func (o *Operations) DeleteTag(ctx context.Context, name string) error {
+	if err := o.runCommand(ctx, "git", "tag", "-d", name); err != nil {
+		return fmt.Errorf("failed to delete tag %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteRemoteTag removes a tag from the remote; a tag the remote doesn't
+// have counts as deleted
+func (o *Operations) DeleteRemoteTag(ctx context.Context, remote, name string) error {
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git push %s --delete refs/tags/%s", remote, name)
+		return nil
+	}
+	o.logger.Step("Deleting tag %s on %s...", name, remote)
+	if err := o.runCommand(ctx, "git", "push", remote, "--delete", "refs/tags/"+name); err != nil {
+		if strings.Contains(err.Error(), "remote ref does not exist") {
+			o.logger.Debug("%s has no tag %s", remote, name)
+			return nil
+		}
+		o.logger.Error("Failed to delete tag %s on %s", name, remote)
+		return fmt.Errorf("failed to delete tag %s on %s: %w", name, remote, err)
+	}
+	o.logger.Success("Deleted tag %s on %s", name, remote)
+	return nil
+}
+
+// PushTag pushes a single tag to the remote
+func (o *Operations) PushTag(ctx context.Context, remote, name string) error {
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git push %s refs/tags/%s", remote, name)
+		return nil
+	}
+	o.logger.Step("Pushing tag %s to %s...", name, remote)
+	if err := o.runCommand(ctx, "git", "push", remote, "refs/tags/"+name); err != nil {
+		o.logger.Error("Failed to push tag")
+		return fmt.Errorf("failed to push tag %s: %w", name, err)
+	}
+	o.logger.Success("Pushed tag %s", name)
+	return nil
+}
+
+// PushTags pushes the named tags to the remote in one push, or every local
+// tag when names is empty. force overwrites tags the remote already has.
+func (o *Operations) PushTags(ctx context.Context, remote string, names []string, force bool) error {
+	args := []string{"push", remote}
+	if force {
+		args = append(args, "--force")
+	}
+	if len(names) == 0 {
+		args = append(args, "--tags")
+	}
+	for _, name := range names {
+		args = append(args, "refs/tags/"+name)
+	}
+	if o.dryRun {
+		o.logger.Info("[dry-run] Would run: git %s", strings.Join(args, " "))
+		return nil
+	}
+	what := "all tags"
+	if len(names) > 0 {
+		what = strings.Join(names, ", ")
+	}
+	o.logger.Step("Pushing %s to %s...", what, remote)
+	if err := o.runCommand(ctx, "git", args...); err != nil {
+		o.logger.Error("Failed to push tags")
+		if strings.Contains(err.Error(), "already exists") {
+			return fmt.Errorf("%s already has a different tag by that name; pass --force to overwrite it: %w", remote, err)
+		}
+		return fmt.Errorf("failed to push tags: %w", err)
+	}
+	o.logger.Success("Pushed %s to %s", what, remote)
+	return nil
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// ErrTimeout is returned when a git command runs longer than SetTimeouts allows
+var ErrTimeout = errors.New("operation timed out")
+
+// stopGracePeriod is how long a git command stopped by its context gets to
+// clean up its lock files after SIGINT before it is killed
+const stopGracePeriod = 5 * time.Second
+
+// SetTimeouts limits how long a single git command may run: network for those
+// that talk to a remote (push, fetch, pull, ls-remote, clone, submodule
+// update), per attempt when retried, and local for the rest. Zero leaves a
+// command bounded only by the caller's context.
+func (o *Operations) SetTimeouts(network, local time.Duration) {
+	o.networkTimeout = network
+	o.localTimeout = local
+}
+
+// timedCmd is a command bound to its context and, for git, the timeout for its
+// kind of command. Its context must be released with cancel once it has run.
+type timedCmd struct {
+	*exec.Cmd
+	parent  context.Context
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	name    string
+}
+
+// command builds name args under ctx, bounded for git by SetTimeouts. A
+// command whose context ends gets SIGINT, as from Ctrl-C, so git can remove
+// its lock files, and is killed if it hasn't exited stopGracePeriod later.
+func (o *Operations) command(ctx context.Context, name string, args ...string) *timedCmd {
+	t := &timedCmd{parent: ctx, name: name}
+	if name == o.gitPath {
+		t.timeout = o.localTimeout
+		if isNetworkCommand(args) {
+			t.timeout = o.networkTimeout
+		}
+	}
+	if t.timeout > 0 {
+		t.ctx, t.cancel = context.WithTimeout(ctx, t.timeout)
+	} else {
+		t.ctx, t.cancel = context.WithCancel(ctx)
+	}
+
+	t.Cmd = exec.CommandContext(t.ctx, name, args...)
+	t.Dir = o.workingDir
+	t.Cancel = func() error {
+		if runtime.GOOS == "windows" {
+			// Windows has no SIGINT to send
+			return t.Process.Kill()
+		}
+		return t.Process.Signal(os.Interrupt)
+	}
+	t.WaitDelay = stopGracePeriod
+	return t
+}
+
+// wrap explains an error from a command stopped by its own timeout rather than
+// the caller's context; the message reads as transient, so retries apply
+func (t *timedCmd) wrap(err error) error {
+	if err == nil || t.parent.Err() != nil || !errors.Is(t.ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	what := t.name
+	if len(t.Args) > 1 {
+		what = "git " + t.Args[1]
+	}
+	return fmt.Errorf("%s: %w after %v: %w", what, ErrTimeout, t.timeout, err)
+}
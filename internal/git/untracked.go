@@ -0,0 +1,44 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NewDirectory is an untracked directory that staging everything would add wholesale
+type NewDirectory struct {
+	Path  string
+	Files int
+}
+
+// LargeNewDirectories finds untracked directories holding more than threshold
+// files, which usually means generated output (dist/, build/, node_modules/)
+// is about to be committed. Ignored files are not counted.
+func (o *Operations) LargeNewDirectories(ctx context.Context, threshold int) ([]NewDirectory, error) {
+	if err := o.requireWorktree(ctx, "stage changes"); err != nil {
+		return nil, err
+	}
+	entries, err := o.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []NewDirectory
+	for _, e := range entries {
+		// git status collapses a directory with no tracked files into a single "dir/" entry
+		if !e.IsUntracked() || !strings.HasSuffix(e.Path, "/") {
+			continue
+		}
+		out, err := o.output(ctx, "ls-files", "--others", "--exclude-standard", "-z", "--", ":(top)"+e.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list untracked files in %s: %w", e.Path, err)
+		}
+		if n := len(splitNames(out)); n > threshold {
+			dirs = append(dirs, NewDirectory{Path: e.Path, Files: n})
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Files > dirs[j].Files })
+	return dirs, nil
+}
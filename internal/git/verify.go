@@ -0,0 +1,83 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CommitDiscrepancy lists the differences between what was staged and what was committed
+type CommitDiscrepancy struct {
+	// Missing were staged but are not part of the commit
+	Missing []string
+	// Unexpected are part of the commit but were not staged
+	Unexpected []string
+}
+
+// Empty reports whether the commit matched the staged files exactly
+func (d CommitDiscrepancy) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Unexpected) == 0
+}
+
+// StagedFileNames returns the paths with changes in the index
+func (o *Operations) StagedFileNames(ctx context.Context) ([]string, error) {
+	out, err := o.output(ctx, "diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	return splitNames(out), nil
+}
+
+// CommittedFileNames returns the paths changed by the given commit
+func (o *Operations) CommittedFileNames(ctx context.Context, rev string) ([]string, error) {
+	out, err := o.output(ctx, "show", "--name-only", "-z", "--format=", rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", rev, err)
+	}
+	return splitNames(out), nil
+}
+
+// VerifyCommit compares the files staged before committing with the files
+// changed by HEAD, catching hooks or concurrent index changes that altered the commit
+func (o *Operations) VerifyCommit(ctx context.Context, staged []string) (CommitDiscrepancy, error) {
+	committed, err := o.CommittedFileNames(ctx, "HEAD")
+	if err != nil {
+		return CommitDiscrepancy{}, err
+	}
+
+	want := make(map[string]bool, len(staged))
+	for _, p := range staged {
+		want[p] = true
+	}
+	got := make(map[string]bool, len(committed))
+	for _, p := range committed {
+		got[p] = true
+	}
+
+	var d CommitDiscrepancy
+	for p := range want {
+		if !got[p] {
+			d.Missing = append(d.Missing, p)
+		}
+	}
+	for p := range got {
+		if !want[p] {
+			d.Unexpected = append(d.Unexpected, p)
+		}
+	}
+	sort.Strings(d.Missing)
+	sort.Strings(d.Unexpected)
+	return d, nil
+}
+
+// splitNames splits NUL-separated path output
+func splitNames(out string) []string {
+	var names []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -7,26 +7,56 @@ import (
 	"os"
 
 	"github.com/google/go-github/v57/github"
+	"github.com/saint/ghquick/internal/cache"
 	"github.com/saint/ghquick/internal/log"
 	"golang.org/x/oauth2"
 )
 
 type Client struct {
-	client *github.Client
-	logger *log.Logger
+	client    *github.Client
+	logger    *log.Logger
+	dryRun    bool
+	repos     *cache.RepoCache
+	transport *retryTransport
 }
 
+// NewClient returns a client authenticated with token; without one it makes
+// anonymous requests, which only reach public data
 func NewClient(token string, debug bool) *Client {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(context.Background(), ts)
+	tc := &http.Client{Transport: http.DefaultTransport}
+	if token != "" {
+		tc = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	}
+	logger := log.New(debug)
+	transport := &retryTransport{base: tc.Transport, logger: logger}
+	tc.Transport = transport
 	return &Client{
-		client: github.NewClient(tc),
-		logger: log.New(debug),
+		client:    github.NewClient(tc),
+		logger:    logger,
+		repos:     cache.NewRepoCache(),
+		transport: transport,
 	}
 }
 
+// SetHost points the client at a GitHub Enterprise server's API instead of
+// api.github.com; an empty host or github.com keeps the default
+func (c *Client) SetHost(host string) error {
+	if host == "" || host == "github.com" {
+		return nil
+	}
+	client, err := c.client.WithEnterpriseURLs("https://"+host+"/api/v3/", "https://"+host+"/api/uploads/")
+	if err != nil {
+		return fmt.Errorf("invalid GitHub host %q: %w", host, err)
+	}
+	c.client = client
+	return nil
+}
+
+// SetDryRun makes mutating API calls log the request they would send instead of sending it
+func (c *Client) SetDryRun(dryRun bool) {
+	c.dryRun = dryRun
+}
+
 func isNotFound(err error) bool {
 	if err == nil {
 		return false
@@ -37,44 +67,46 @@ func isNotFound(err error) bool {
 	return false
 }
 
-func (c *Client) EnsureRepositoryExists(ctx context.Context, name string, private bool) error {
+// RepoOptions controls how EnsureRepositoryExists treats the repository
+type RepoOptions struct {
+	// Private makes a newly created repository private
+	Private     bool
+	Description string
+	// Create allows creating the repository when it doesn't exist yet
+	Create bool
+	// License is a license template key such as "mit" or "apache-2.0"
+	License string
+	// GitignoreTemplate names a .gitignore template such as "Go" or "Node"
+	GitignoreTemplate string
+	// Existing lets CreateRepo go on with a repository that already exists
+	// instead of failing with ErrRepoExists
+	Existing bool
+}
+
+// EnsureRepositoryExists checks that the user's repository exists on GitHub,
+// creating it when opts.Create is set. opts.Private only applies to a new
+// repository; an existing one keeps its visibility.
+func (c *Client) EnsureRepositoryExists(ctx context.Context, name string, opts RepoOptions) error {
 	c.logger.Step("Checking if repository exists...")
 	username := os.Getenv("GITHUB_USERNAME")
 
 	// Try to get the repository first
-	repo, _, err := c.client.Repositories.Get(ctx, username, name)
+	_, _, err := c.client.Repositories.Get(ctx, username, name)
 	if err == nil {
 		c.logger.Info("Repository exists, will append changes")
-		// Update repository settings if needed
-		if repo.GetPrivate() != private {
-			c.logger.Step("Updating repository visibility...")
-			repo.Private = github.Bool(private)
-			_, _, err = c.client.Repositories.Edit(ctx, username, name, repo)
-			if err != nil {
-				c.logger.Error("Failed to update repository visibility")
-				return fmt.Errorf("failed to update repository: %w", err)
-			}
-			c.logger.Success("Repository visibility updated")
-		}
 		return nil
 	}
 
 	// Only create if repository doesn't exist
 	if isNotFound(err) {
-		c.logger.Step("Repository doesn't exist, creating new repository: %s", name)
-		repo = &github.Repository{
-			Name:     github.String(name),
-			Private:  github.Bool(private),
-			AutoInit: github.Bool(false),
+		if !opts.Create {
+			c.logger.Error("Repository %s/%s doesn't exist on GitHub", username, name)
+			return fmt.Errorf("repository %s/%s does not exist; pass --create-repo to create it", username, name)
 		}
-
-		_, _, err = c.client.Repositories.Create(ctx, "", repo)
-		if err != nil {
-			c.logger.Error("Failed to create repository")
-			return fmt.Errorf("failed to create repository: %w", err)
-		}
-		c.logger.Success("Repository created successfully")
-		return nil
+		c.logger.Info("Repository doesn't exist yet")
+		// It may have been created since the lookup, which is as good
+		opts.Existing = true
+		return c.CreateRepo(ctx, name, opts)
 	}
 
 	// If we get here, it's an unexpected error
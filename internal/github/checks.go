@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// CheckRun is the subset of a check run (a GitHub Actions job or another
+// app's check) ghquick reports
+type CheckRun struct {
+	Name string `json:"name"`
+	// Status is queued, in_progress or completed
+	Status string `json:"status"`
+	// Conclusion is set once the run is completed: success, failure, neutral,
+	// cancelled, skipped, timed_out, action_required, stale or startup_failure
+	Conclusion  string    `json:"conclusion,omitempty"`
+	URL         string    `json:"url"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Completed reports whether the run has finished
+func (r CheckRun) Completed() bool {
+	return r.Status == "completed"
+}
+
+// Failed reports whether the run finished without passing. Neutral and
+// skipped runs don't count as failures.
+func (r CheckRun) Failed() bool {
+	switch r.Conclusion {
+	case "", "success", "neutral", "skipped":
+		return false
+	}
+	return r.Completed()
+}
+
+// ListCheckRuns returns the latest check run of every check for ref, a commit
+// SHA, branch or tag
+func (c *Client) ListCheckRuns(ctx context.Context, owner, repo, ref string) ([]CheckRun, error) {
+	opts := &github.ListCheckRunsOptions{
+		Filter:      github.String("latest"),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	runs := []CheckRun{}
+	for {
+		page, resp, err := c.client.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check runs for %s: %w", ref, err)
+		}
+		for _, r := range page.CheckRuns {
+			runs = append(runs, CheckRun{
+				Name:        r.GetName(),
+				Status:      r.GetStatus(),
+				Conclusion:  r.GetConclusion(),
+				URL:         r.GetHTMLURL(),
+				StartedAt:   r.GetStartedAt().Time,
+				CompletedAt: r.GetCompletedAt().Time,
+			})
+		}
+		if resp.NextPage == 0 {
+			return runs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,166 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Issue is the subset of an issue ghquick shows and reports back
+type Issue struct {
+	Number    int      `json:"number"`
+	Title     string   `json:"title"`
+	State     string   `json:"state"`
+	URL       string   `json:"url"`
+	Author    string   `json:"author,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+}
+
+// IssueFilter narrows ListIssues
+type IssueFilter struct {
+	// State is open (default), closed, or all
+	State    string
+	Labels   []string
+	Assignee string
+	// Limit caps how many issues are returned (default 30)
+	Limit int
+}
+
+// IssueInput describes an issue to open
+type IssueInput struct {
+	Owner     string
+	Repo      string
+	Title     string
+	Body      string
+	Labels    []string
+	Assignees []string
+}
+
+func toIssue(i *github.Issue) Issue {
+	issue := Issue{
+		Number: i.GetNumber(),
+		Title:  i.GetTitle(),
+		State:  i.GetState(),
+		URL:    i.GetHTMLURL(),
+		Author: i.GetUser().GetLogin(),
+	}
+	for _, l := range i.Labels {
+		issue.Labels = append(issue.Labels, l.GetName())
+	}
+	for _, a := range i.Assignees {
+		issue.Assignees = append(issue.Assignees, a.GetLogin())
+	}
+	return issue
+}
+
+// ListIssues returns the repository's issues matching filter, newest first.
+// Pull requests, which the API lists as issues too, are left out.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string, filter IssueFilter) ([]Issue, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 30
+	}
+	opts := &github.IssueListByRepoOptions{
+		State:       filter.State,
+		Labels:      filter.Labels,
+		Assignee:    filter.Assignee,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if opts.State == "" {
+		opts.State = "open"
+	}
+
+	issues := []Issue{}
+	for {
+		page, resp, err := c.client.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, i := range page {
+			if i.IsPullRequest() {
+				continue
+			}
+			issues = append(issues, toIssue(i))
+			if len(issues) == limit {
+				return issues, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreateIssue opens an issue, or in dry-run mode prints the API call it would make
+func (c *Client) CreateIssue(ctx context.Context, in IssueInput) (*Issue, error) {
+	req := &github.IssueRequest{
+		Title: github.String(in.Title),
+		Body:  github.String(in.Body),
+	}
+	if len(in.Labels) > 0 {
+		req.Labels = &in.Labels
+	}
+	if len(in.Assignees) > 0 {
+		req.Assignees = &in.Assignees
+	}
+
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(req, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/issues\n%s", in.Owner, in.Repo, payload)
+		return &Issue{Title: in.Title}, nil
+	}
+
+	c.logger.Step("Opening issue in %s/%s...", in.Owner, in.Repo)
+	issue, _, err := c.client.Issues.Create(ctx, in.Owner, in.Repo, req)
+	if err != nil {
+		c.logger.Error("Failed to open issue")
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	c.logger.Success("Issue #%d opened", issue.GetNumber())
+	result := toIssue(issue)
+	return &result, nil
+}
+
+// CommentOnIssue adds a comment to an issue or pull request
+func (c *Client) CommentOnIssue(ctx context.Context, owner, repo string, number int, body string) error {
+	comment := &github.IssueComment{Body: github.String(body)}
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(comment, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/issues/%d/comments\n%s", owner, repo, number, payload)
+		return nil
+	}
+	if _, _, err := c.client.Issues.CreateComment(ctx, owner, repo, number, comment); err != nil {
+		return fmt.Errorf("failed to comment on #%d: %w", number, err)
+	}
+	c.logger.Success("Commented on #%d", number)
+	return nil
+}
+
+// CloseIssue closes an issue, first adding comment when it isn't empty.
+// reason is completed (default) or not_planned.
+func (c *Client) CloseIssue(ctx context.Context, owner, repo string, number int, comment, reason string) error {
+	if comment != "" {
+		if err := c.CommentOnIssue(ctx, owner, repo, number, comment); err != nil {
+			return err
+		}
+	}
+
+	req := &github.IssueRequest{State: github.String("closed")}
+	if reason != "" {
+		req.StateReason = github.String(reason)
+	}
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(req, "", "  ")
+		c.logger.Info("[dry-run] Would call: PATCH /repos/%s/%s/issues/%d\n%s", owner, repo, number, payload)
+		return nil
+	}
+	if _, _, err := c.client.Issues.Edit(ctx, owner, repo, number, req); err != nil {
+		return fmt.Errorf("failed to close #%d: %w", number, err)
+	}
+	c.logger.Success("Closed #%d", number)
+	return nil
+}
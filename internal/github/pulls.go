@@ -0,0 +1,321 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// PullRequestInput describes a pull request to open
+type PullRequestInput struct {
+	Owner string
+	Repo  string
+	Title string
+	Body  string
+	// Head is the branch with the changes; use "owner:branch" for cross-repository PRs
+	Head  string
+	Base  string
+	Draft bool
+}
+
+// PullRequest is the subset of a pull request ghquick reports back
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Draft  bool   `json:"draft"`
+	State  string `json:"state,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Head is the branch with the changes, in HeadRepo
+	Head string `json:"head,omitempty"`
+	// HeadRepo is owner/name of the repository Head is in; empty when that fork was deleted
+	HeadRepo string `json:"head_repo,omitempty"`
+	HeadSHA  string `json:"head_sha,omitempty"`
+	Base     string `json:"base,omitempty"`
+	// NodeID is the GraphQL ID, needed to change the draft state
+	NodeID string `json:"-"`
+}
+
+// PullRequestFilter narrows ListPullRequests
+type PullRequestFilter struct {
+	// State is open (default), closed, or all
+	State string
+	// Base only lists pull requests into this branch
+	Base string
+	// Limit caps how many pull requests are returned (default 30)
+	Limit int
+}
+
+// MergeMethod is how a pull request's commits land on the base branch
+type MergeMethod string
+
+const (
+	// MergeCommit adds a merge commit
+	MergeCommit MergeMethod = "merge"
+	// MergeSquash squashes the commits into one
+	MergeSquash MergeMethod = "squash"
+	// MergeRebase replays the commits onto the base
+	MergeRebase MergeMethod = "rebase"
+)
+
+// ParseMergeMethod validates a merge method name, defaulting to MergeCommit when empty
+func ParseMergeMethod(s string) (MergeMethod, error) {
+	switch MergeMethod(s) {
+	case "":
+		return MergeCommit, nil
+	case MergeCommit, MergeSquash, MergeRebase:
+		return MergeMethod(s), nil
+	}
+	return "", fmt.Errorf("invalid merge method %q (expected merge, squash, or rebase)", s)
+}
+
+// MergeInput describes how to merge a pull request
+type MergeInput struct {
+	Method MergeMethod
+	// Title and Message are the merge or squash commit's; empty leaves GitHub's defaults
+	Title   string
+	Message string
+}
+
+// ErrNotMergeable is returned when GitHub refuses to merge a pull request, e.g.
+// because it conflicts, is a draft, or required checks or reviews are missing
+var ErrNotMergeable = errors.New("pull request is not mergeable")
+
+// CreatePullRequest opens a pull request, or in dry-run mode prints the API call it would make
+func (c *Client) CreatePullRequest(ctx context.Context, in PullRequestInput) (*PullRequest, error) {
+	req := &github.NewPullRequest{
+		Title: github.String(in.Title),
+		Head:  github.String(in.Head),
+		Base:  github.String(in.Base),
+		Body:  github.String(in.Body),
+		Draft: github.Bool(in.Draft),
+	}
+
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(req, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/pulls\n%s", in.Owner, in.Repo, payload)
+		return &PullRequest{}, nil
+	}
+
+	c.logger.Step("Opening pull request %s → %s...", in.Head, in.Base)
+	pr, _, err := c.client.PullRequests.Create(ctx, in.Owner, in.Repo, req)
+	if err != nil {
+		c.logger.Error("Failed to open pull request")
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	c.logger.Success("Pull request #%d opened", pr.GetNumber())
+	return toPullRequest(pr), nil
+}
+
+func toPullRequest(pr *github.PullRequest) *PullRequest {
+	return &PullRequest{
+		Number:   pr.GetNumber(),
+		Title:    pr.GetTitle(),
+		URL:      pr.GetHTMLURL(),
+		Draft:    pr.GetDraft(),
+		State:    pr.GetState(),
+		Author:   pr.GetUser().GetLogin(),
+		Head:     pr.GetHead().GetRef(),
+		HeadRepo: pr.GetHead().GetRepo().GetFullName(),
+		HeadSHA:  pr.GetHead().GetSHA(),
+		Base:     pr.GetBase().GetRef(),
+		NodeID:   pr.GetNodeID(),
+	}
+}
+
+// ListPullRequests returns the repository's pull requests matching filter, newest first
+func (c *Client) ListPullRequests(ctx context.Context, owner, repo string, filter PullRequestFilter) ([]*PullRequest, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 30
+	}
+	opts := &github.PullRequestListOptions{
+		State:       filter.State,
+		Base:        filter.Base,
+		ListOptions: github.ListOptions{PerPage: min(limit, 100)},
+	}
+	if opts.State == "" {
+		opts.State = "open"
+	}
+
+	prs := []*PullRequest{}
+	for {
+		page, resp, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range page {
+			prs = append(prs, toPullRequest(pr))
+			if len(prs) == limit {
+				return prs, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return prs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetPullRequest returns pull request number
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%s/%s has no pull request #%d", owner, repo, number)
+		}
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	return toPullRequest(pr), nil
+}
+
+// MergePullRequest merges a pull request, or in dry-run mode prints the API
+// call it would make. It only merges the head commit pr was read with, so
+// commits pushed since are never merged unseen.
+func (c *Client) MergePullRequest(ctx context.Context, owner, repo string, pr *PullRequest, in MergeInput) error {
+	opts := &github.PullRequestOptions{CommitTitle: in.Title, SHA: pr.HeadSHA, MergeMethod: string(in.Method)}
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(map[string]string{
+			"merge_method": opts.MergeMethod, "sha": opts.SHA, "commit_title": in.Title, "commit_message": in.Message,
+		}, "", "  ")
+		c.logger.Info("[dry-run] Would call: PUT /repos/%s/%s/pulls/%d/merge\n%s", owner, repo, pr.Number, payload)
+		return nil
+	}
+
+	c.logger.Step("Merging pull request #%d (%s)...", pr.Number, in.Method)
+	_, resp, err := c.client.PullRequests.Merge(ctx, owner, repo, pr.Number, in.Message, opts)
+	if err != nil {
+		c.logger.Error("Failed to merge pull request #%d", pr.Number)
+		var apiErr *github.ErrorResponse
+		if errors.As(err, &apiErr) && resp != nil {
+			switch resp.StatusCode {
+			case http.StatusMethodNotAllowed:
+				return fmt.Errorf("%w (#%d): %s", ErrNotMergeable, pr.Number, apiErr.Message)
+			case http.StatusConflict:
+				return fmt.Errorf("#%d changed since it was read (%s); check the new commits and merge again", pr.Number, apiErr.Message)
+			}
+		}
+		return fmt.Errorf("failed to merge pull request #%d: %w", pr.Number, err)
+	}
+	c.logger.Success("Merged pull request #%d", pr.Number)
+	return nil
+}
+
+// DeleteBranch deletes a branch in a repository; one already gone (GitHub can
+// delete head branches on merge by itself) is not an error
+func (c *Client) DeleteBranch(ctx context.Context, owner, repo, branch string) error {
+	if c.dryRun {
+		c.logger.Info("[dry-run] Would call: DELETE /repos/%s/%s/git/refs/heads/%s", owner, repo, branch)
+		return nil
+	}
+	resp, err := c.client.Git.DeleteRef(ctx, owner, repo, "heads/"+branch)
+	if err != nil {
+		if (resp != nil && resp.StatusCode == http.StatusUnprocessableEntity) || isNotFound(err) {
+			c.logger.Debug("%s/%s has no branch %s", owner, repo, branch)
+			return nil
+		}
+		return fmt.Errorf("failed to delete branch %s in %s/%s: %w", branch, owner, repo, err)
+	}
+	c.logger.Success("Deleted branch %s in %s/%s", branch, owner, repo)
+	return nil
+}
+
+// FindOpenPullRequest returns the open pull request for head ("owner:branch"), or nil if there is none
+func (c *Client) FindOpenPullRequest(ctx context.Context, owner, repo, head string) (*PullRequest, error) {
+	prs, _, err := c.client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+		State:       "open",
+		Head:        head,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return toPullRequest(prs[0]), nil
+}
+
+// SetPullRequestDraft converts a pull request to a draft or marks it ready for
+// review. The REST API can't change the draft state, so this uses GraphQL.
+func (c *Client) SetPullRequestDraft(ctx context.Context, pr *PullRequest, draft bool) error {
+	mutation := "markPullRequestReadyForReview"
+	if draft {
+		mutation = "convertPullRequestToDraft"
+	}
+	query := fmt.Sprintf("mutation($id: ID!) { %s(input: {pullRequestId: $id}) { pullRequest { isDraft } } }", mutation)
+
+	if c.dryRun {
+		c.logger.Info("[dry-run] Would call: POST /graphql %s for #%d", mutation, pr.Number)
+		return nil
+	}
+
+	req, err := c.client.NewRequest("POST", "graphql", map[string]interface{}{
+		"query":     query,
+		"variables": map[string]string{"id": pr.NodeID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", mutation, err)
+	}
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.client.Do(ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to update draft state of #%d: %w", pr.Number, err)
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("failed to update draft state of #%d: %s", pr.Number, resp.Errors[0].Message)
+	}
+
+	pr.Draft = draft
+	if draft {
+		c.logger.Success("Pull request #%d converted to draft", pr.Number)
+	} else {
+		c.logger.Success("Pull request #%d marked ready for review", pr.Number)
+	}
+	return nil
+}
+
+// RequestReviewers asks users (or "org/team" teams) to review a pull request
+func (c *Client) RequestReviewers(ctx context.Context, owner, repo string, pr *PullRequest, reviewers []string) error {
+	var req github.ReviewersRequest
+	for _, r := range reviewers {
+		if _, team, ok := strings.Cut(r, "/"); ok {
+			req.TeamReviewers = append(req.TeamReviewers, team)
+		} else {
+			req.Reviewers = append(req.Reviewers, r)
+		}
+	}
+
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(req, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/pulls/%d/requested_reviewers\n%s", owner, repo, pr.Number, payload)
+		return nil
+	}
+	if _, _, err := c.client.PullRequests.RequestReviewers(ctx, owner, repo, pr.Number, req); err != nil {
+		return fmt.Errorf("failed to request reviewers for #%d: %w", pr.Number, err)
+	}
+	c.logger.Success("Requested review from %s", strings.Join(reviewers, ", "))
+	return nil
+}
+
+// AddLabels adds labels to a pull request; labels that don't exist yet are created by GitHub
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, pr *PullRequest, labels []string) error {
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(map[string][]string{"labels": labels}, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/issues/%d/labels\n%s", owner, repo, pr.Number, payload)
+		return nil
+	}
+	if _, _, err := c.client.Issues.AddLabelsToIssue(ctx, owner, repo, pr.Number, labels); err != nil {
+		return fmt.Errorf("failed to label #%d: %w", pr.Number, err)
+	}
+	c.logger.Success("Labeled #%d with %s", pr.Number, strings.Join(labels, ", "))
+	return nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ReleaseInput describes a GitHub release to create for an existing tag
+type ReleaseInput struct {
+	Owner      string
+	Repo       string
+	Tag        string
+	Name       string
+	Body       string
+	Draft      bool
+	Prerelease bool
+}
+
+// Release is the subset of a release ghquick reports back
+type Release struct {
+	ID  int64
+	URL string
+}
+
+// CreateRelease publishes a release for in.Tag, or in dry-run mode prints the API call it would make
+func (c *Client) CreateRelease(ctx context.Context, in ReleaseInput) (*Release, error) {
+	req := &github.RepositoryRelease{
+		TagName:    github.String(in.Tag),
+		Name:       github.String(in.Name),
+		Body:       github.String(in.Body),
+		Draft:      github.Bool(in.Draft),
+		Prerelease: github.Bool(in.Prerelease),
+	}
+
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(req, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/releases\n%s", in.Owner, in.Repo, payload)
+		return &Release{}, nil
+	}
+
+	c.logger.Step("Creating release %s...", in.Tag)
+	release, _, err := c.client.Repositories.CreateRelease(ctx, in.Owner, in.Repo, req)
+	if err != nil {
+		c.logger.Error("Failed to create release")
+		return nil, fmt.Errorf("failed to create release: %w", err)
+	}
+	c.logger.Success("Release %s created", in.Tag)
+	return &Release{ID: release.GetID(), URL: release.GetHTMLURL()}, nil
+}
+
+// UploadReleaseAsset attaches the file at path to the release, named after the file
+func (c *Client) UploadReleaseAsset(ctx context.Context, owner, repo string, release *Release, path string) error {
+	name := filepath.Base(path)
+	if c.dryRun {
+		c.logger.Info("[dry-run] Would call: POST /repos/%s/%s/releases/{id}/assets?name=%s (%s)", owner, repo, name, path)
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open asset: %w", err)
+	}
+	defer file.Close()
+
+	c.logger.Step("Uploading %s...", name)
+	if _, _, err := c.client.Repositories.UploadReleaseAsset(ctx, owner, repo, release.ID, &github.UploadOptions{Name: name}, file); err != nil {
+		c.logger.Error("Failed to upload %s", name)
+		return fmt.Errorf("failed to upload asset %s: %w", name, err)
+	}
+	c.logger.Success("Uploaded %s", name)
+	return nil
+}
@@ -0,0 +1,166 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint/ghquick/internal/cache"
+)
+
+// GetDefaultBranch returns the repository's default branch as reported by the API.
+// Results are cached for the lifetime of the client's repo cache.
+func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	key := owner + "/" + repo
+	if info, ok := c.repos.Get(key); ok && info.Branch != "" {
+		return info.Branch, nil
+	}
+
+	c.logger.Debug("Looking up default branch of %s", key)
+	r, _, err := c.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository %s: %w", key, err)
+	}
+	branch := r.GetDefaultBranch()
+	if branch == "" {
+		return "", fmt.Errorf("repository %s has no default branch", key)
+	}
+
+	c.repos.Set(key, &cache.RepoInfo{
+		Name:   repo,
+		Remote: r.GetCloneURL(),
+		Branch: branch,
+	})
+	return branch, nil
+}
+
+// ErrRepoExists is returned by CreateRepo when the name is already taken
+var ErrRepoExists = errors.New("repository already exists")
+
+// CreateRepo creates a repository owned by the authenticated user. A
+// repository that already exists is an ErrRepoExists error, unless
+// opts.Existing is set, when it is left as it is. A license or .gitignore
+// template makes GitHub create an initial commit holding those files.
+func (c *Client) CreateRepo(ctx context.Context, name string, opts RepoOptions) error {
+	repo := &github.Repository{
+		Name:     github.String(name),
+		Private:  github.Bool(opts.Private),
+		AutoInit: github.Bool(opts.License != "" || opts.GitignoreTemplate != ""),
+	}
+	if opts.Description != "" {
+		repo.Description = github.String(opts.Description)
+	}
+	if opts.License != "" {
+		repo.LicenseTemplate = github.String(opts.License)
+	}
+	if opts.GitignoreTemplate != "" {
+		repo.GitignoreTemplate = github.String(opts.GitignoreTemplate)
+	}
+
+	if c.dryRun {
+		payload, _ := json.MarshalIndent(repo, "", "  ")
+		c.logger.Info("[dry-run] Would call: POST /user/repos\n%s", payload)
+		return nil
+	}
+
+	c.logger.Step("Creating repository %s...", name)
+	if _, _, err := c.client.Repositories.Create(ctx, "", repo); err != nil {
+		if isAlreadyExists(err) {
+			if opts.Existing {
+				c.logger.Info("Repository %s already exists", name)
+				return nil
+			}
+			c.logger.Error("Repository %s already exists", name)
+			return fmt.Errorf("%s: %w", name, ErrRepoExists)
+		}
+		c.logger.Error("Failed to create repository")
+		return fmt.Errorf("failed to create repository: %w", err)
+	}
+	c.logger.Success("Repository created successfully")
+	return nil
+}
+
+// isAlreadyExists reports whether a create call failed because the name is taken
+func isAlreadyExists(err error) bool {
+	githubErr, ok := err.(*github.ErrorResponse)
+	if !ok || githubErr.Response == nil || githubErr.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, e := range githubErr.Errors {
+		if strings.Contains(e.Message, "already exists") {
+			return true
+		}
+	}
+	return strings.Contains(githubErr.Message, "already exists")
+}
+
+// GitignoreTemplate returns the github/gitignore template for a language or
+// tool such as "Go" or "Node", matched case-insensitively. With refresh a
+// cached copy is downloaded again.
+func (c *Client) GitignoreTemplate(ctx context.Context, name string, refresh bool) (string, error) {
+	return c.cachedTemplate("gitignore", name, refresh, func() (string, error) {
+		names, _, err := c.client.Gitignores.List(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to list .gitignore templates: %w", err)
+		}
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				t, _, err := c.client.Gitignores.Get(ctx, n)
+				if err != nil {
+					return "", fmt.Errorf("failed to get .gitignore template %s: %w", n, err)
+				}
+				return t.GetSource(), nil
+			}
+		}
+		return "", fmt.Errorf("no .gitignore template named %q (see https://github.com/github/gitignore)", name)
+	})
+}
+
+// LicenseTemplate returns the text of a license such as "mit" or "apache-2.0",
+// with its [year] and [fullname] placeholders left in. With refresh a cached
+// copy is downloaded again.
+func (c *Client) LicenseTemplate(ctx context.Context, key string, refresh bool) (string, error) {
+	return c.cachedTemplate("license", key, refresh, func() (string, error) {
+		l, _, err := c.client.Licenses.Get(ctx, strings.ToLower(key))
+		if err != nil {
+			if isNotFound(err) {
+				return "", fmt.Errorf("no license named %q (e.g. mit, apache-2.0, gpl-3.0, bsd-3-clause)", key)
+			}
+			return "", fmt.Errorf("failed to get license %s: %w", key, err)
+		}
+		return l.GetBody(), nil
+	})
+}
+
+// cachedTemplate returns a template from the on-disk cache, downloading it
+// with fetch when it isn't there or is out of date. An out-of-date copy is
+// still used when the download fails, e.g. offline.
+func (c *Client) cachedTemplate(kind, name string, refresh bool, fetch func() (string, error)) (string, error) {
+	templates, err := cache.NewTemplateCache()
+	if err != nil {
+		c.logger.Debug("Not caching templates: %v", err)
+		return fetch()
+	}
+	if !refresh {
+		if data, ok := templates.Get(kind, name, false); ok {
+			c.logger.Debug("Using cached %s template %s", kind, name)
+			return data, nil
+		}
+	}
+	data, err := fetch()
+	if err != nil {
+		if stale, ok := templates.Get(kind, name, true); ok {
+			c.logger.Warning("Could not download the %s template %s; using the cached copy: %v", kind, name, err)
+			return stale, nil
+		}
+		return "", err
+	}
+	if err := templates.Set(kind, name, data); err != nil {
+		c.logger.Debug("%v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,113 @@
+package github
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/saint/ghquick/internal/log"
+	"github.com/saint/ghquick/internal/retry"
+)
+
+// maxRateLimitWait is the longest a rate-limited request waits for the limit
+// to reset; beyond that it fails so the user isn't left staring at a timer
+const maxRateLimitWait = time.Minute
+
+// retryTransport retries API requests that fail with network errors, 5xx
+// responses or rate limits. Requests that create or change something
+// (POST, PATCH) are only retried when GitHub certainly didn't act on them:
+// connection failures, 429, 502, 503 and 504.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy retry.Policy
+	logger *log.Logger
+}
+
+// SetRetryPolicy sets how API requests are retried on transient failures;
+// the zero policy uses the retry package defaults
+func (c *Client) SetRetryPolicy(p retry.Policy) {
+	c.transport.policy = p
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := t.policy.MaxAttempts()
+	// A body can only be sent again if it can be recreated
+	if req.Body != nil && req.GetBody == nil {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		delay, retryable := t.retryDelay(req, resp, err, attempt)
+		if !retryable || attempt >= attempts || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		reason := "network error"
+		if err == nil {
+			reason = resp.Status
+			resp.Body.Close()
+		}
+		t.logger.Warning("GitHub API %s %s failed (%s); retrying in %v (attempt %d/%d)...",
+			req.Method, req.URL.Path, reason, delay.Round(100*time.Millisecond), attempt+1, attempts)
+		if err := retry.Wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether a request is worth sending again and how long to
+// wait first, honouring Retry-After and the rate limit reset time
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead ||
+		req.Method == http.MethodPut || req.Method == http.MethodDelete
+
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return t.policy.Delay(attempt), true
+		}
+		var netErr net.Error
+		return t.policy.Delay(attempt), idempotent && errors.As(err, &netErr)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return rateLimitDelay(resp, t.policy.Delay(attempt))
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return t.policy.Delay(attempt), true
+	case resp.StatusCode >= 500:
+		return t.policy.Delay(attempt), idempotent
+	}
+	return 0, false
+}
+
+// rateLimitDelay returns how long GitHub asks to wait before trying again, or
+// fallback when it doesn't say. Waits longer than maxRateLimitWait aren't retried.
+func rateLimitDelay(resp *http.Response, fallback time.Duration) (time.Duration, bool) {
+	delay := fallback
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		delay = time.Duration(s) * time.Second
+	} else if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		delay = time.Until(time.Unix(reset, 0)) + time.Second
+	}
+	if delay > maxRateLimitWait {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = fallback
+	}
+	return delay, true
+}
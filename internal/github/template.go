@@ -0,0 +1,34 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prTemplatePaths are the locations GitHub looks for a pull request template
+var prTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+// FindPRTemplate returns the repository's pull request template, or "" if it has none
+func FindPRTemplate(repoDir string) (string, error) {
+	for _, p := range prTemplatePaths {
+		data, err := os.ReadFile(filepath.Join(repoDir, p))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read PR template %s: %w", p, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// LogFileName is the file OpenFile writes in the log directory
+	LogFileName = "ghquick.log"
+	// maxLogFileSize is the size past which the log is rotated when opened
+	maxLogFileSize = 5 << 20
+	// maxLogFiles is how many rotated logs (ghquick.log.1 and up) are kept
+	maxLogFiles = 5
+)
+
+var (
+	fileMu  sync.Mutex
+	logFile *os.File
+)
+
+// DefaultLogDir returns the directory logs are written to (~/.ghquick/logs)
+func DefaultLogDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".ghquick", "logs"), nil
+}
+
+// OpenFile starts recording every message, debug ones and command output
+// included, as one JSON object per line in ghquick.log under dir
+// (DefaultLogDir when empty), whatever the output format and level. A log
+// that has grown past 5 MB is rotated first, keeping the last five. It returns
+// the file's path.
+func OpenFile(dir string) (string, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultLogDir(); err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+	path := filepath.Join(dir, LogFileName)
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogFileSize {
+		rotate(path)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to open log file: %w", err)
+	}
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = f
+	return path, nil
+}
+
+// CloseFile stops recording to the log file
+func CloseFile() error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	return err
+}
+
+// rotate shifts path to path.1, path.1 to path.2 and so on, dropping the oldest
+func rotate(path string) {
+	os.Remove(fmt.Sprintf("%s.%d", path, maxLogFiles))
+	for i := maxLogFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	os.Rename(path, path+".1")
+}
+
+// fileOpen reports whether messages are being recorded to a log file
+func fileOpen() bool {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	return logFile != nil
+}
+
+// writeFile records one entry in the log file, if one is open. The process ID
+// tells apart runs that overlap. mu must not be held.
+func writeFile(level, message string, data map[string]interface{}) {
+	mu.Lock()
+	currentPhase := phase
+	mu.Unlock()
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	if logFile == nil {
+		return
+	}
+	entry := make(map[string]interface{}, len(data)+5)
+	for k, v := range data {
+		entry[k] = redactValue(v)
+	}
+	entry["time"] = time.Now().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["pid"] = os.Getpid()
+	if currentPhase != "" {
+		entry["phase"] = currentPhase
+	}
+	if message != "" {
+		entry["message"] = message
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	logFile.Write(append(line, '\n'))
+}
@@ -1,9 +1,14 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
 )
 
 const (
@@ -14,8 +19,137 @@ const (
 	colorBlue   = "\033[34m"
 	colorPurple = "\033[35m"
 	colorCyan   = "\033[36m"
+	colorDim    = "\033[2m"
+	clearLine   = "\r\033[K"
+)
+
+// Format selects how every logger writes its output
+type Format string
+
+const (
+	// FormatText is colored, human-readable output (the default)
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per line to stdout
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates an output format name; an empty string selects text
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid output format %q (expected text or json)", s)
+}
+
+// Level is the least severe kind of message shown
+type Level string
+
+const (
+	// LevelDebug also shows debug messages and the commands being run
+	LevelDebug Level = "debug"
+	// LevelInfo shows progress, results, warnings and errors (the default)
+	LevelInfo Level = "info"
+	// LevelWarn shows only warnings and errors
+	LevelWarn Level = "warn"
+	// LevelError shows only errors
+	LevelError Level = "error"
 )
 
+// ParseLevel validates a log level name; an empty string selects info
+func ParseLevel(s string) (Level, error) {
+	switch l := Level(strings.ToLower(s)); l {
+	case "":
+		return LevelInfo, nil
+	case "warning":
+		return LevelWarn, nil
+	case LevelDebug, LevelInfo, LevelWarn, LevelError:
+		return l, nil
+	}
+	return "", fmt.Errorf("invalid log level %q (expected debug, info, warn, or error)", s)
+}
+
+// severity ranks a message level against a Level; step, success and command
+// output count as info
+func severity(level string) int {
+	switch level {
+	case "debug":
+		return 0
+	case "warning", string(LevelWarn):
+		return 2
+	case "error":
+		return 3
+	}
+	return 1
+}
+
+// Output state is shared by all loggers, since each package creates its own
+var (
+	mu     sync.Mutex
+	format = FormatText
+	level  = LevelInfo
+	phase  string
+	result bool
+	quiet  bool
+	// progressShown is set while a progress line is drawn without its newline
+	progressShown bool
+	stdoutTTY     = term.IsTerminal(int(os.Stdout.Fd()))
+)
+
+// SetQuiet hides the output that commands print while they run, including
+// transfer progress; ghquick's own messages are still shown
+func SetQuiet(q bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	quiet = q
+}
+
+// Quiet reports whether command output is hidden
+func Quiet() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return quiet
+}
+
+// SetFormat switches every logger to the given output format
+func SetFormat(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	format = f
+}
+
+// JSON reports whether loggers write JSON events
+func JSON() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return format == FormatJSON
+}
+
+// SetLevel hides messages less severe than l. Debug messages are shown only by
+// loggers created with debug set, whatever the level.
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// shown reports whether messages of the given level pass the log level
+func shown(msgLevel string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return severity(msgLevel) >= severity(string(level))
+}
+
+// SetPhase names the stage of the run (e.g. "stage", "commit", "push") that
+// subsequent JSON events are attributed to
+func SetPhase(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	phase = name
+}
+
 // Logger provides pretty console logging
 type Logger struct {
 	debug bool
@@ -28,40 +162,182 @@ func New(debug bool) *Logger {
 
 // Info prints an info message with a blue info icon
 func (l *Logger) Info(format string, args ...interface{}) {
-	fmt.Printf("%sℹ️  INFO: %s%s\n", colorBlue, fmt.Sprintf(format, args...), colorReset)
+	l.print("info", colorBlue+"ℹ️  INFO: ", format, args...)
 }
 
 // Success prints a success message with a green checkmark
 func (l *Logger) Success(format string, args ...interface{}) {
-	fmt.Printf("%s✅ SUCCESS: %s%s\n", colorGreen, fmt.Sprintf(format, args...), colorReset)
+	l.print("success", colorGreen+"✅ SUCCESS: ", format, args...)
 }
 
 // Error prints an error message with a red X
 func (l *Logger) Error(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s❌ ERROR: %s%s\n", colorRed, fmt.Sprintf(format, args...), colorReset)
+	l.print("error", colorRed+"❌ ERROR: ", format, args...)
 }
 
 // Warning prints a warning message with a yellow warning icon
 func (l *Logger) Warning(format string, args ...interface{}) {
-	fmt.Printf("%s⚠️  WARNING: %s%s\n", colorYellow, fmt.Sprintf(format, args...), colorReset)
+	l.print("warning", colorYellow+"⚠️  WARNING: ", format, args...)
 }
 
-// Debug prints a debug message if debug mode is enabled
+// Debug prints a debug message if debug mode is enabled. The log file gets it
+// either way.
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if l.debug {
-		fmt.Printf("%s🔍 DEBUG: %s%s\n", colorPurple, fmt.Sprintf(format, args...), colorReset)
+	if l.debug || fileOpen() {
+		l.print("debug", colorPurple+"🔍 DEBUG: ", format, args...)
 	}
 }
 
 // Step prints a step message with a cyan arrow
 func (l *Logger) Step(format string, args ...interface{}) {
-	fmt.Printf("%s➡️  %s%s\n", colorCyan, fmt.Sprintf(format, args...), colorReset)
+	l.print("step", colorCyan+"➡️  ", format, args...)
 }
 
-// Command prints a command that's being executed
+// Command prints a command that's being executed, with any credentials in it
+// redacted
 func (l *Logger) Command(cmd string, args ...string) {
-	if l.debug {
-		fullCmd := fmt.Sprintf("%s %s", cmd, strings.Join(args, " "))
-		fmt.Printf("%s$ %s%s\n", colorPurple, fullCmd, colorReset)
+	if l.debug || fileOpen() {
+		l.print("debug", colorPurple+"$ ", "%s %s", cmd, strings.Join(args, " "))
+	}
+}
+
+// Output shows a line a command printed while it runs, indented under the step
+// that started it. JSON output gets an "output" event; quiet mode drops it.
+func (l *Logger) Output(line string) {
+	line = Redact(line)
+	writeFile("output", line, nil)
+	if Quiet() || !shown("output") {
+		return
+	}
+	if JSON() {
+		emit("output", line, nil)
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	endProgress()
+	fmt.Fprintf(os.Stdout, "%s   %s%s\n", colorDim, line, colorReset)
+}
+
+// Progress shows a transfer progress update such as "Writing objects: 45%
+// (9/20)". In a terminal the line is redrawn in place; otherwise only the
+// finished line of each stage is printed. JSON output gets a "progress" event.
+func (l *Logger) Progress(stage string, percent int, line string, done bool) {
+	if Quiet() || !shown("progress") {
+		return
+	}
+	line = Redact(line)
+	if JSON() {
+		emit("progress", "", map[string]interface{}{"stage": stage, "percent": percent, "done": done})
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	switch {
+	case stdoutTTY:
+		fmt.Fprintf(os.Stdout, "%s%s   %s%s", clearLine, colorDim, line, colorReset)
+		progressShown = true
+		if done {
+			endProgress()
+		}
+	case done:
+		fmt.Fprintf(os.Stdout, "%s   %s%s\n", colorDim, line, colorReset)
+	}
+}
+
+// endProgress finishes a progress line left open by Progress; mu must be held
+func endProgress() {
+	if progressShown {
+		fmt.Fprintln(os.Stdout)
+		progressShown = false
+	}
+}
+
+// Event records structured data about the current phase, such as the commit
+// message and staged files. Only JSON output shows it; text output has its own
+// messages for the same information.
+func (l *Logger) Event(data map[string]interface{}) {
+	writeFile("event", "", data)
+	if JSON() {
+		emit("event", "", data)
+	}
+}
+
+// Result writes the final outcome of a run as a JSON object with level
+// "result", the last line of JSON output. Text output only records it in the
+// log file.
+func (l *Logger) Result(data map[string]interface{}) {
+	writeFile("result", "", data)
+	if JSON() {
+		emit("result", "", data)
+	}
+	mu.Lock()
+	result = true
+	mu.Unlock()
+}
+
+// ResultWritten reports whether Result has been called
+func ResultWritten() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return result
+}
+
+// print writes a message as colored text, or as a JSON event in JSON mode,
+// and records it in the log file. Credentials are redacted from it first.
+// Errors go to stderr in text mode so they survive a redirected stdout.
+func (l *Logger) print(level, prefix, format string, args ...interface{}) {
+	message := Redact(fmt.Sprintf(format, args...))
+	writeFile(level, message, nil)
+	if level == "debug" && !l.debug || !shown(level) {
+		return
+	}
+	if JSON() {
+		emit(level, message, nil)
+		return
+	}
+	out := os.Stdout
+	if level == "error" {
+		out = os.Stderr
+	}
+	mu.Lock()
+	endProgress()
+	mu.Unlock()
+	fmt.Fprintf(out, "%s%s%s\n", prefix, message, colorReset)
+}
+
+// emit writes one JSON event line to stdout
+func emit(level, message string, data map[string]interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	event := make(map[string]interface{}, len(data)+4)
+	for k, v := range data {
+		event[k] = redactValue(v)
+	}
+	event["time"] = time.Now().UTC().Format(time.RFC3339)
+	event["level"] = level
+	if phase != "" {
+		event["phase"] = phase
+	}
+	if message != "" {
+		event["message"] = message
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{"level": "error", "message": err.Error()})
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", line)
+}
+
+// TruncateLines keeps the first max lines of s, replacing the rest with a
+// "... (N more lines)" marker. A max of zero or less keeps everything.
+func TruncateLines(s string, max int) string {
+	if max <= 0 {
+		return s
+	}
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= max {
+		return s
 	}
+	return fmt.Sprintf("%s\n... (%d more lines)", strings.Join(lines[:max], "\n"), len(lines)-max)
 }
@@ -0,0 +1,115 @@
+// Package retry runs operations again, with exponential backoff and jitter,
+// when they fail with errors that are likely to go away on their own
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Defaults used for zero Policy fields
+const (
+	DefaultAttempts   = 3
+	DefaultBackoff    = 2 * time.Second
+	DefaultMaxBackoff = 30 * time.Second
+	DefaultJitter     = 0.2
+)
+
+// Policy says how often and how patiently a failing operation is retried.
+// The zero value uses the defaults above.
+type Policy struct {
+	// Attempts is the total number of tries, including the first
+	Attempts int
+	// Backoff is the wait before the second try; it doubles after every failure
+	Backoff time.Duration
+	// MaxBackoff caps the wait between tries
+	MaxBackoff time.Duration
+	// Jitter randomizes every wait by up to this fraction of it, e.g. 0.2 for ±20%;
+	// a negative value turns it off
+	Jitter float64
+}
+
+// withDefaults fills in the zero fields
+func (p Policy) withDefaults() Policy {
+	if p.Attempts <= 0 {
+		p.Attempts = DefaultAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultMaxBackoff
+	}
+	if p.Jitter == 0 {
+		p.Jitter = DefaultJitter
+	}
+	return p
+}
+
+// MaxAttempts returns the number of tries the policy allows
+func (p Policy) MaxAttempts() int {
+	return p.withDefaults().Attempts
+}
+
+// Delay returns how long to wait after the given failed attempt (counting from 1)
+func (p Policy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
+
+// Wait sleeps for d, returning early with the context's error if it is done first
+func Wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Do calls fn until it succeeds, fails with an error retryable rejects, or the
+// policy runs out of attempts. onRetry, when set, is told about each failure
+// that is about to be retried and how long the wait is.
+func Do(ctx context.Context, p Policy, retryable func(error) bool, onRetry func(attempt int, delay time.Duration, err error), fn func() error) error {
+	attempts := p.MaxAttempts()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable(err) {
+			return err
+		}
+		if attempt >= attempts {
+			if attempts > 1 {
+				return fmt.Errorf("gave up after %d attempts: %w", attempts, err)
+			}
+			return err
+		}
+
+		delay := p.Delay(attempt)
+		if onRetry != nil {
+			onRetry(attempt, delay, err)
+		}
+		if err := Wait(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package scan
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Finding is a match in a line added by the diff
+type Finding struct {
+	File string
+	Line int
+	Rule string
+	Text string
+}
+
+// hunkHeader captures the starting line in the new file: "@@ -a,b +c,d @@"
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// AddedLine is a line introduced by the diff, with its position in the new file
+type AddedLine struct {
+	File string
+	Line int
+	Text string
+}
+
+// AddedLines walks a unified diff and returns every added line with its file and line number
+func AddedLines(diff string) []AddedLine {
+	var lines []AddedLine
+	var file string
+	lineNo := 0
+	inHunk := false
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+			file = ""
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				lineNo, _ = strconv.Atoi(m[1])
+			}
+		case inHunk && strings.HasPrefix(line, "+"):
+			lines = append(lines, AddedLine{File: file, Line: lineNo, Text: line[1:]})
+			lineNo++
+		case inHunk && strings.HasPrefix(line, "-"):
+			// removed lines don't advance the new file
+		case inHunk && strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case inHunk:
+			lineNo++
+		}
+	}
+	return lines
+}
+
+// Scan runs match over every added line and collects the findings it reports
+func Scan(diff string, match func(AddedLine) []Finding) []Finding {
+	var findings []Finding
+	for _, line := range AddedLines(diff) {
+		findings = append(findings, match(line)...)
+	}
+	return findings
+}
@@ -0,0 +1,35 @@
+package scan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultMarkers are the code-hygiene markers flagged when none are configured
+var DefaultMarkers = []string{"TODO", "FIXME", "XXX"}
+
+// ScanDiffForMarkers reports added lines that introduce one of the markers
+// (matched as whole words, case-sensitively, e.g. "TODO" but not "TODOS")
+func ScanDiffForMarkers(diff string, markers []string) []Finding {
+	if len(markers) == 0 {
+		markers = DefaultMarkers
+	}
+	quoted := make([]string, len(markers))
+	for i, m := range markers {
+		quoted[i] = regexp.QuoteMeta(m)
+	}
+	re := regexp.MustCompile(`\b(` + strings.Join(quoted, "|") + `)\b`)
+
+	return Scan(diff, func(line AddedLine) []Finding {
+		m := re.FindStringSubmatch(line.Text)
+		if m == nil {
+			return nil
+		}
+		return []Finding{{
+			File: line.File,
+			Line: line.Line,
+			Rule: m[1],
+			Text: strings.TrimSpace(line.Text),
+		}}
+	})
+}
@@ -0,0 +1,111 @@
+package scan
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AllowSecretMarker on an added line tells ScanDiffForSecrets that whatever
+// it contains is not a secret (e.g. a test fixture or a documented example key)
+const AllowSecretMarker = "ghquick:allow-secret"
+
+// secretRule matches one kind of credential; group 1, when present, is the secret itself
+// and is what gets masked and checked against minEntropy
+type secretRule struct {
+	name string
+	re   *regexp.Regexp
+	// minEntropy, when set, additionally requires the secret to look random
+	minEntropy float64
+}
+
+var secretRules = []secretRule{
+	{name: "private key", re: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{name: "AWS access key ID", re: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{name: "GitHub token", re: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{name: "Anthropic API key", re: regexp.MustCompile(`\b(sk-ant-[A-Za-z0-9_-]{20,})`)},
+	{name: "OpenAI API key", re: regexp.MustCompile(`\b(sk-(?:proj-)?[A-Za-z0-9_-]{20,})`)},
+	{name: "Slack token", re: regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})`)},
+	{name: "Google API key", re: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`)},
+	{name: "Stripe live key", re: regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{20,})\b`)},
+	{name: "password in URL", re: regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^/\s:@]+:([^/\s:@]{3,})@`)},
+	{
+		name:       "hard-coded secret",
+		re:         regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|access[_-]?key|client[_-]?secret)["']?\s*[:=]+\s*["']([^"'\s]{12,})["']`),
+		minEntropy: 3.5,
+	},
+	{
+		name:       "high-entropy string",
+		re:         regexp.MustCompile(`["']([A-Za-z0-9+/=_-]{32,})["']`),
+		minEntropy: 4.5,
+	},
+}
+
+// ScanDiffForSecrets reports added lines that look like they contain API keys,
+// tokens, private keys or passwords: known token formats are matched by regular
+// expression, and quoted strings assigned to secret-sounding names or long
+// random-looking literals by Shannon entropy. Lock files are skipped, and so is
+// any line containing AllowSecretMarker. The secret is masked in Finding.Text.
+func ScanDiffForSecrets(diff string) []Finding {
+	return Scan(diff, func(line AddedLine) []Finding {
+		if isLockFile(line.File) || strings.Contains(line.Text, AllowSecretMarker) {
+			return nil
+		}
+		for _, rule := range secretRules {
+			m := rule.re.FindStringSubmatch(line.Text)
+			if m == nil {
+				continue
+			}
+			text := line.Text
+			if len(m) > 1 {
+				// Only the group is secret; rules without one, like the private key header, are shown as-is
+				if rule.minEntropy > 0 && entropy(m[1]) < rule.minEntropy {
+					continue
+				}
+				text = strings.Replace(text, m[1], mask(m[1]), 1)
+			}
+			// One finding per line is enough to block the commit
+			return []Finding{{
+				File: line.File,
+				Line: line.Line,
+				Rule: rule.name,
+				Text: strings.TrimSpace(text),
+			}}
+		}
+		return nil
+	})
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// mask keeps the first few characters of a secret so it can be found, hiding the rest
+func mask(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8)
+}
+
+// isLockFile reports whether p is a dependency lock file, whose hashes look random
+func isLockFile(p string) bool {
+	switch path.Base(p) {
+	case "go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock", "poetry.lock", "composer.lock", "Gemfile.lock":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+// Package strutil holds small string helpers shared across packages
+package strutil
+
+// FirstNonEmpty returns the first non-empty value, or "" if there is none
+func FirstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/saint/ghquick/cmd"
+	"github.com/saint/ghquick/internal/log"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", log.Redact(err.Error()))
+		if errors.Is(err, cmd.ErrInterrupted) {
+			// The shell convention for a command stopped by SIGINT
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }